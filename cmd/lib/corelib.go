@@ -32,6 +32,13 @@ func ZScript_Init(argc C.int, argv **C.char) {
 	vm.InitVM(args)
 }
 
+// ZScript_AddLibPath adds a directory to the search path of imports.
+//
+//export ZScript_AddLibPath
+func ZScript_AddLibPath(cdir *C.char) {
+	vm.AddLibPath(C.GoString(cdir))
+}
+
 // ZScript_Interpret interprets ZScript source code with a given name.
 //
 //export ZScript_Interpret
@@ -102,6 +109,8 @@ func valueToString(val runtime.Value) string {
 		return "false"
 	case runtime.VAL_NUMBER:
 		return fmt.Sprintf("%g", val.Number)
+	case runtime.VAL_INT:
+		return fmt.Sprintf("%d", val.Int)
 	case runtime.VAL_OBJ:
 		switch obj := val.Obj.(type) {
 		case *runtime.ObjString:
@@ -145,6 +154,8 @@ func valueToString(val runtime.Value) string {
 				return "<script>"
 			}
 			return fmt.Sprintf("<fn %s>", obj.Function.Name.Chars)
+		case *runtime.ObjBoundMethod:
+			return fmt.Sprintf("<fn %s>", obj.Method.Function.Name.Chars)
 		case *runtime.ObjNative:
 			return "<native fn>"
 		case *runtime.ObjModule:
@@ -157,6 +168,10 @@ func valueToString(val runtime.Value) string {
 			return fmt.Sprintf("<DateTime %s>", obj.Time.Format("2006-01-02 15:04:05"))
 		case *runtime.ObjArrayIterator:
 			return fmt.Sprintf("<array iterator at %d>", obj.Index)
+		case *runtime.ObjBigInt:
+			return obj.String()
+		case *runtime.ObjDecimal:
+			return obj.String()
 		default:
 			return "<unknown object>"
 		}
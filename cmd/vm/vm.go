@@ -39,15 +39,45 @@ func main() {
 		}
 	}
 
-	vm.InitVM(os.Args)
+	args, libPaths, err := parseLibPaths(os.Args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(64)
+	}
+
+	vm.InitVM(args)
 	defer vm.FreeVM()
+	for _, dir := range libPaths {
+		vm.AddLibPath(dir)
+	}
 
-	if len(os.Args) == 1 {
+	if len(args) == 1 {
 		fmt.Println("zvm REPL - ZScript Virtual Machine (type Ctrl+D to exit)")
 		repl()
 	} else {
-		runFile(os.Args[1])
+		runFile(args[1])
+	}
+}
+
+// parseLibPaths takes the '--lib-path DIR' and '--lib-path=DIR' options in front of the script out
+// of args, returning the remaining arguments and the directories in the order given.
+func parseLibPaths(args []string) ([]string, []string, error) {
+	var libPaths []string
+	i := 1
+	for i < len(args) && strings.HasPrefix(args[i], "--lib-path") {
+		if dir, found := strings.CutPrefix(args[i], "--lib-path="); found {
+			libPaths = append(libPaths, dir)
+		} else if args[i] == "--lib-path" && i+1 < len(args) {
+			i++
+			libPaths = append(libPaths, args[i])
+		} else if args[i] == "--lib-path" {
+			return nil, nil, fmt.Errorf("Option '--lib-path' expects a directory.")
+		} else {
+			break
+		}
+		i++
 	}
+	return append([]string{args[0]}, args[i:]...), libPaths, nil
 }
 
 // showUsage prints detailed help and usage instructions.
@@ -59,6 +89,13 @@ Usage: zvm [options] [script]
 Options:
   -h, --help       Display this help message and exit
   -v, --version    Show version information and exit
+  --lib-path DIR   Search DIR for imported modules; can be given several times
+
+Imports:
+  - 'import "name"' looks for name, name.z or name/mod.z next to the importing script, then in the
+    zscript_modules directories of the script's directory and its parents, then in the --lib-path
+    directories and last in the directories listed in ZSCRIPT_PATH (separated by ':').
+  - Names starting with './' or '../' are only looked up next to the importing script.
 
 Modes:
   - If no script is provided, zvm starts an interactive REPL (Read-Eval-Print Loop)
@@ -79,6 +116,7 @@ Script Execution:
 
 Exit Codes:
   0   Successful execution
+  64  Invalid command-line options
   65  Compilation error
   70  Runtime error
   74  File I/O error
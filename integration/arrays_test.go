@@ -185,3 +185,97 @@ println(array_to_string(slice2))`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestSteppedSlices(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var a = [0, 1, 2, 3, 4, 5, 6]
+println(a[::2])
+println(a[1::2])
+println(a[::-1])
+println(a[5:1:-2])
+println(a[-3:])
+println(a[3:1])`
+	expectedOutput := "[0, 2, 4, 6]\n[1, 3, 5]\n[6, 5, 4, 3, 2, 1, 0]\n[5, 3]\n[4, 5, 6]\n[1, 2]\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestRangeIndexing(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var a = [10, 20, 30, 40, 50]
+println(a[1..3])
+println(a[1..=3])
+println(a[-2..=-1])
+println(a[3..100])`
+	expectedOutput := "[20, 30]\n[20, 30, 40]\n[40, 50]\n[40, 50]\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestRangeIndexingLongerThanArray(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var a = [1, 2, 3]
+println(a[0..10000000000])
+println(a[-10000000000..=1])
+println(a[range(10000000000, -10000000000, -1)])
+println(a[range(0, 10000000000, 2)])
+println("abc"[1..10000000000])`
+	expectedOutput := "[1, 2, 3]\n[1, 2, 3, 1, 2]\n[3, 2, 1, 3, 2, 1]\n[1, 3]\nbc\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestSliceStepZero(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `try:
+    println([1, 2][::0])
+catch (e):
+    println(e.message)`
+	expectedOutput := "Slice step cannot be zero.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -62,3 +62,59 @@ println(counter())`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestLambdaExpressions(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var double = func(x): x * 2
+func apply(f, v):
+    return f(v)
+println(double(4), apply(func(x): x + 1, 10))
+var fs = [func(): "a", func(): "b"]
+println(fs[1]())
+struct Button:
+    label = "ok"
+    func handler():
+        return func(suffix): this.label + suffix
+println(Button{}.handler()("!"))`
+	expectedOutput := "8 11\nb\nok!\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestLambdaBlockCapturesUpvalues(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func makeAccumulator():
+    var total = 0
+    return func(amount = 1):
+        total = total + amount
+        return total
+var acc = makeAccumulator()
+acc()
+acc(5)
+println(acc(10))`
+	expectedOutput := "16\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -102,3 +102,212 @@ while (x < 5):
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestMatchLiteralAndWildcard(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func name(n):
+    match n with:
+        | 1: println("one")
+        | "two": println("two")
+        | *: println("other")
+name(1)
+name("two")
+name(3)`
+	expectedOutput := "one\ntwo\nother\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMatchDestructuringAndGuards(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Point:
+    x = 0
+    y = 0
+func describe(v):
+    match v with:
+        | [a, b]: println("pair", a, b)
+        | Point{x = 0, y}: println("y axis", y)
+        | Point{x, y} if x == y: println("diagonal", x)
+        | Point{x, y}: println("point", x, y)
+        | n if n > 10: println("big", n)
+        | *: println("other")
+describe([1, 2])
+describe(Point{x = 0, y = 5})
+describe(Point{x = 3, y = 3})
+describe(Point{x = 1, y = 2})
+describe(42)
+describe(7)`
+	expectedOutput := "pair 1 2\ny axis 5\ndiagonal 3\npoint 1 2\nbig 42\nother\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var value = 2
+var label = match value with:
+    | 1: "one"
+    | 2: "two"
+    | *: "many"
+println(label)
+println(match [value, 3] with { | [a, b]: a * b | *: 0 })
+println(match value with { | 5: "five" })`
+	expectedOutput := "two\n6\nnull\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMatchThrough(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func check(v):
+    match v through:
+        | 1: println("one")
+        | 2:
+            println("two")
+            break
+        | "three": println("three"); break
+        | *: println("default")
+check(1)
+check(2)
+check("three")
+check(4)`
+	expectedOutput := "one\ntwo\ntwo\nthree\ndefault\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMatchThroughNonConstantCases(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var limit = 3
+func check(v):
+    match v through:
+        | true: println("true"); break
+        | limit: println("limit"); break
+        | *: println("other")
+check(true)
+check(3)
+check(false)`
+	expectedOutput := "true\nlimit\nother\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMatchThroughInsideLoop(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var i = 0
+while (i < 4):
+    i = i + 1
+    var doubled = i * 2
+    match i through:
+        | 2:
+            var skipped = doubled
+            continue
+        | 4: break
+        | *: println(doubled)
+println("done", i)`
+	expectedOutput := "2\n6\ndone 4\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMatchInlineArmIndentedLine(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var v = 3
+match v with:
+    | 1: println("a")
+            println("b")
+    | 2: println("c")`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for an indented line after an inline match arm")
+		}
+	})
+}
+
+func TestMatchThroughInlineArmIndentedLine(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var v = 1
+match v through:
+    | 1: println("one")
+        break
+    | 2: println("two")`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for an indented line after an inline match arm")
+		}
+	})
+}
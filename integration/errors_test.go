@@ -0,0 +1,211 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/cryptrunner49/zscript/internal/core"
+	"github.com/cryptrunner49/zscript/internal/vm"
+)
+
+func TestTryCatchThrow(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func fail(n):
+    throw "bad " + to_str(n)
+try:
+    fail(1)
+    println("not reached")
+catch (e):
+    println(e.message, e.line, len(e.stack))
+try:
+    throw Error{message = "custom"}
+catch (e):
+    println(e)
+try:
+    throw 42
+catch (e):
+    println(e.value)`
+	expectedOutput := "bad 1 2 2\nError: custom\n42\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestTryCatchRuntimeAndNativeErrors(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `try:
+    println(missing)
+catch (e):
+    println(e.message)
+try:
+    read_file("/nonexistent/zscript/file.txt")
+catch (e):
+    println("caught", e.file)
+println("still running")`
+	expectedOutput := "Global variable 'missing' is not defined.\ncaught <script>\nstill running\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestFinallyRunsOnEveryExit(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func value():
+    try:
+        return "returned"
+    finally:
+        println("finally after return")
+println(value())
+for (var i = 0; i < 4; i++):
+    try:
+        if (i == 1):
+            continue
+        if (i == 3):
+            break
+        println("body", i)
+    finally:
+        println("finally", i)
+try:
+    try:
+        throw "inner"
+    finally:
+        println("finally before rethrow")
+catch (e):
+    println("outer", e.message)`
+	expectedOutput := "finally after return\nreturned\nbody 0\nfinally 0\nfinally 1\nbody 2\nfinally 2\nfinally 3\nfinally before rethrow\nouter inner\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestCatchClosesUpvalues(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var saved = null
+func work():
+    var state = "before"
+    func get():
+        return state
+    saved = get
+    state = "after"
+    throw "stop"
+try:
+    work()
+catch (e):
+    println(saved())`
+	expectedOutput := "after\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `throw "unhandled"
+println("not reached")`
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a runtime error for an uncaught throw")
+		}
+	})
+
+	if output != "" {
+		t.Errorf("Expected no output, got %q", output)
+	}
+}
+
+func TestCustomErrorStruct(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct NotFound < Error:
+    path = ""
+try:
+    throw NotFound{message = "missing", path = "a.txt"}
+catch (e):
+    println(e, e.path, e.line, is_instance(e, Error))`
+	expectedOutput := "NotFound: missing a.txt 4 true\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestErrorStackInMatchExpression(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func f(x):
+    var r = match x with:
+        | 1: 1 / "a"
+        | *: 0
+    return r
+
+try:
+    f(1)
+catch (e):
+    println(e.line)
+    iter (var entry in e.stack):
+        println(entry)`
+	expectedOutput := "3\n[line 3] in function 'f()'\n[line 8] in top-level script\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -48,3 +48,365 @@ println(greet("World"))`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestDefaultParameters(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var base = 1
+func f(a, b = base, c = a + b):
+    return [a, b, c]
+println(f(10), f(10, 2), f(10, 2, 3))
+base = 5
+println(f(0))
+func fresh(list = []):
+    push(list, 1)
+    return list
+println(fresh(), fresh())`
+	expectedOutput := "[10, 1, 11] [10, 2, 12] [10, 2, 3]\n[0, 5, 5]\n[1] [1]\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestRestParameter(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func collect(first, second = "b", ...rest):
+    println(first, second, rest)
+collect("a")
+collect("a", "x")
+collect("a", "x", 1, 2, 3)
+struct Bag:
+    items = []
+    func add(...xs):
+        iter (var x in xs):
+            push(this.items, x)
+        return len(this.items)
+println(Bag{}.add(4, 5, 6))`
+	expectedOutput := "a b []\na x []\na x [1, 2, 3]\n3\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestParameterArityErrors(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func f(a, b = 1):
+    return a
+try:
+    f()
+catch (e):
+    println(e.message)
+try:
+    f(1, 2, 3)
+catch (e):
+    println(e.message)
+func g(a, ...rest):
+    return a
+try:
+    g()
+catch (e):
+    println(e.message)`
+	expectedOutput := "Function 'f' expects 1 to 2 arguments but got 0.\n" +
+		"Function 'f' expects 1 to 2 arguments but got 3.\n" +
+		"Function 'g' expects at least 1 argument but got 0.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestKeywordArguments(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func draw(x, y = 0, color = "black", width = 1):
+    println(x, y, color, width)
+draw(1, color = "red")
+draw(width = 3, x = 5)
+draw(1, 2, width = 9, color = "blue")
+struct Pen:
+    ink = "blue"
+    func line(from, to = 10, style = "solid"):
+        return this.ink + " " + to_str(from) + "-" + to_str(to) + " " + style
+var pen = Pen{}
+println(pen.line(1, style = "dashed"))
+var bound = pen.line
+println(bound(to = 2, from = 0))`
+	expectedOutput := "1 0 red 1\n5 0 black 3\n1 2 blue 9\nblue 1-10 dashed\nblue 0-2 solid\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestKeywordArgumentErrors(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func f(a, b = 2):
+    return a
+try:
+    f(1, c = 3)
+catch (e):
+    println(e.message)
+try:
+    f(1, a = 3)
+catch (e):
+    println(e.message)
+try:
+    f(b = 3)
+catch (e):
+    println(e.message)
+try:
+    println(x = 1)
+catch (e):
+    println(e.message)`
+	expectedOutput := "Function 'f' has no parameter named 'c'.\n" +
+		"Function 'f' got more than one value for parameter 'a'.\n" +
+		"Function 'f' is missing a value for parameter 'a'.\n" +
+		"Cannot pass keyword arguments to native function; only script functions accept them.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestDuplicateKeywordArgument(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func f(a, b):
+    return a
+f(a = 1, a = 2)`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for a repeated keyword argument")
+		}
+	})
+}
+
+func TestGenerators(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func count(n):
+    var i = 0
+    while (i < n):
+        yield i
+        i = i + 1
+    return "end"
+
+var g = count(2)
+println(g.next())
+println(g.next())
+println(g.next())
+println(g.done())
+
+iter (var x in count(3)):
+    println(x)
+
+func running():
+    var total = 0
+    while (true):
+        var amount = yield total
+        if (amount == null):
+            return total
+        total = total + amount
+
+var r = running()
+println(r.next())
+println(r.send(5))
+println(r.send(10))
+println(r.send(null))`
+	expectedOutput := "0\n1\nend\ntrue\n0\n1\n2\n0\n5\n15\n15\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestGeneratorFrameState(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func counter():
+    var n = 0
+    var bump = func(): n = n + 1
+    yield bump
+    yield n
+    bump()
+    yield n
+
+var c = counter()
+var bump = c.next()
+bump()
+bump()
+println(c.next())
+println(c.next())
+
+func guarded():
+    try:
+        yield 1
+        throw "boom"
+    catch (e):
+        yield "caught " + e.message
+    yield 3
+
+iter (var v in guarded()):
+    println(v)
+
+func failing():
+    yield 1
+    throw "oops"
+
+var f = failing()
+f.next()
+try:
+    f.next()
+catch (e):
+    println(e.message)
+println(f.done())`
+	expectedOutput := "2\n3\n1\ncaught boom\n3\noops\ntrue\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestGeneratorErrors(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func once():
+    yield 1
+
+var g = once()
+g.next()
+println(g.done())
+try:
+    g.next()
+catch (e):
+    println(e.message)
+
+func selfish():
+    var me = yield
+    me.next()
+
+var s = selfish()
+s.next()
+try:
+    s.send(s)
+catch (e):
+    println(e.message)`
+	expectedOutput := "true\n" +
+		"Generator 'once' has already finished.\n" +
+		"Generator 'selfish' is already running.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestYieldOutsideFunction(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `yield 1`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for 'yield' outside a function")
+		}
+	})
+}
+
+func TestYieldInMatchExpression(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	scripts := []string{
+		`func gm(x):
+    var r = match x with:
+        | 1: yield "one"
+        | *: "other"
+    return r`,
+		`var r = match 1 with:
+    | 1: yield "one"
+    | *: "other"`,
+	}
+
+	for _, script := range scripts {
+		captureOutput(t, func() {
+			result := core.Interpret(script, "<script>")
+			if result == 0 {
+				t.Fatalf("Expected a compile error for 'yield' inside a match expression:\n%s", script)
+			}
+		})
+	}
+}
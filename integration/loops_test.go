@@ -68,3 +68,171 @@ func TestIteratorLoop(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestIteratorLoopBodyLocals(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func run():
+    var total = 0
+    iter (var item in [1, 2, 3]):
+        var scaled = item * 10
+        total = total + scaled
+    println(total)
+run()`
+	expectedOutput := "60\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIteratorLoopDestructuring(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Point:
+    x
+    y
+iter (var [key, value] in [["a", 1], ["b", 2]]):
+    println(key, value)
+iter (var Point{x, y} in [Point{x = 1, y = 2}]):
+    println(x + y)`
+	expectedOutput := "a 1\nb 2\n3\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIteratorLoopMapsStringsAndRanges(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `iter (var k, v in {b: 2, a: 1}):
+    println(k, v)
+iter (var k in {y: 0, x: 0}):
+    println(k)
+iter (var ch in "hé"):
+    println(ch)
+iter (var i, item in ["p", "q"]):
+    println(i, item)
+iter (var n in range(5, 0, -2)):
+    println(n)`
+	expectedOutput := "a 1\nb 2\nx\ny\nh\né\n0 p\n1 q\n5\n3\n1\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIteratorProtocol(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Countdown:
+    n = 3
+    func done():
+        return this.n == 0
+    func next():
+        this.n = this.n - 1
+        return this.n + 1
+iter (var n in Countdown{}):
+    println(n)
+var it = iterator(["a", "b"])
+while (!it.done()):
+    println(it.next())`
+	expectedOutput := "3\n2\n1\na\nb\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIteratorLoopBreakAndContinue(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func run():
+    var seen = []
+    iter (var n in range(10)):
+        if (n == 1):
+            continue
+        if (n == 4):
+            break
+        var label = "n" + to_str(n)
+        push(seen, label)
+    return seen
+println(run())`
+	expectedOutput := "[n0, n2, n3]\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIteratorLoopNotIterable(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Empty:
+    x
+try:
+    iter (var x in 5):
+        println(x)
+catch (e):
+    println(e.message)
+try:
+    iter (var x in Empty{}):
+        println(x)
+catch (e):
+    println(e.message)`
+	expectedOutput := "Cannot iterate over number.\n" +
+		"Cannot iterate over an instance of 'Empty'; it needs 'done' and 'next' methods.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -0,0 +1,397 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptrunner49/zscript/internal/core"
+	"github.com/cryptrunner49/zscript/internal/vm"
+)
+
+// writeModule writes a script to be imported by the tests into dir.
+func writeModule(t *testing.T, dir string, name string, source string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write module %s: %v", name, err)
+	}
+}
+
+const mathModule = `var calls = 0
+func square(x):
+    calls += 1
+    return x * x
+export func area(r):
+    return PI * square(r)
+export func callCount():
+    return calls
+export const PI = 3
+export struct Circle:
+    r = 1
+    func area():
+        return area(this.r)
+export mod Units:
+    var name = "cm"
+`
+
+func TestImportExports(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "shapes.z", mathModule)
+
+	script := `import "shapes.z"
+var c = Circle()
+c.r = 2
+println(area(1), c.area(), PI, Units.name, callCount())
+try:
+    square(2)
+catch (e):
+    println(e.message)`
+	expectedOutput := "3 12 3 cm 2\n" +
+		"Global variable 'square' is not defined.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestImportAsNamespace(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "shapes.z", mathModule)
+
+	script := `import "shapes.z" as shapes
+println(shapes.area(2), shapes.PI, shapes)
+try:
+    println(shapes.calls)
+catch (e):
+    println(e.message)
+try:
+    shapes.square(2)
+catch (e):
+    println(e.message)
+try:
+    shapes.PI = 4
+catch (e):
+    println(e.message)`
+	expectedOutput := "12 3 <mod shapes>\n" +
+		"Module 'shapes' does not export 'calls'.\n" +
+		"Module 'shapes' does not export 'square'.\n" +
+		"Cannot assign to 'PI'; the namespace of imported module 'shapes' is read-only.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestFromImport(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "shapes.z", mathModule)
+
+	script := `from "shapes.z" import area, callCount
+func total():
+    from "shapes.z" import PI
+    return area(1) + PI
+println(total(), callCount())
+try:
+    from "shapes.z" import square
+catch (e):
+    println(e.message)`
+	expectedOutput := "6 1\n" +
+		"Module 'shapes' does not export 'square'.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestImportRunsOnce(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "counter.z", `println("loading")
+var count = 0
+export func next():
+    count += 1
+    return count
+`)
+
+	script := `import "counter.z" as a
+import "counter.z" as b
+from "counter.z" import next
+println(a.next(), b.next(), next())`
+	expectedOutput := "loading\n1 2 3\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestImportedFileGlobalsArePrivate(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "config.z", `var name = "library"
+export func describe():
+    return name
+`)
+
+	script := `var name = "main"
+import "config.z"
+println(name, describe())`
+	expectedOutput := "main library\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestExportUndefinedName(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "broken.z", "export missing\n")
+
+	script := `try:
+    import "broken.z"
+catch (e):
+    println(e.message)`
+	expectedOutput := "Module 'broken' exports 'missing' but does not define it.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestExportInsideFunction(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func f():
+    export var x = 1`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for 'export' inside a function")
+		}
+	})
+}
+
+func TestImportSearchPath(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	root := t.TempDir()
+	for _, dir := range []string{"zscript_modules/utils", "src/app", "lib", "shared"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	writeModule(t, root, "zscript_modules/utils/strings.z", "export func shout(s):\n    return s + \"!\"\n")
+	writeModule(t, root, "zscript_modules/utils/mod.z", "export var version = 2\n")
+	writeModule(t, root, "lib/paths.z", "export var origin = \"lib-path\"\n")
+	writeModule(t, root, "shared/env.z", "export var origin = \"ZSCRIPT_PATH\"\n")
+	writeModule(t, root, "src/app/local.z", "export var origin = \"local\"\n")
+	vm.AddLibPath(filepath.Join(root, "lib"))
+	t.Setenv("ZSCRIPT_PATH", filepath.Join(root, "shared"))
+
+	script := `import "utils/strings" as strings
+import "utils" as utils
+import "local" as local
+import "paths" as paths
+import "env.z" as env
+println(strings.shout("hi"), utils.version, utils, local.origin, paths.origin, env.origin)`
+	expectedOutput := "hi! 2 <mod utils> local lib-path ZSCRIPT_PATH\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(root, "src/app/main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestImportRelativeSpecifier(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "zscript_modules"), 0755); err != nil {
+		t.Fatalf("Failed to create zscript_modules: %v", err)
+	}
+	writeModule(t, dir, "zscript_modules/helpers.z", "export var origin = \"package\"\n")
+
+	script := `import "helpers" as helpers
+println(helpers.origin)
+try:
+    import "./helpers"
+catch (e):
+    println(e.message)`
+	expectedOutput := "package\n" +
+		"Cannot find module './helpers'; looked for " + filepath.Join(dir, "helpers") + ".\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestCircularImport(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "a.z", "import \"b\"\nexport var name = \"a\"\n")
+	writeModule(t, dir, "b.z", "println(\"loading b\")\n\nimport \"a\"\n")
+	main := filepath.Join(dir, "main.z")
+
+	script := `try:
+    import "a"
+catch (e):
+    println(e.message)
+try:
+    import "a"
+catch (e):
+    println(e.message)`
+	chain := main + " (line 2) -> " + filepath.Join(dir, "a.z") + " (line 1) -> " +
+		filepath.Join(dir, "b.z") + " (line 3) -> " + filepath.Join(dir, "a.z")
+	expectedOutput := "loading b\n" +
+		"Circular import of 'a': " + chain + ".\n" +
+		"loading b\n" +
+		"Circular import of 'a': " + strings.Replace(chain, "(line 2)", "(line 6)", 1) + ".\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, main)
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestImportCompileError(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "broken.z", "println(\"never runs\")\nvar = 3\n")
+
+	script := `println("start")
+try:
+    import "broken"
+catch (e):
+    println(e.message, e.line)`
+	expectedOutput := "start\n" +
+		"Cannot import 'broken'; '" + filepath.Join(dir, "broken.z") + "' has compile errors. 3\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestImportRuntimeErrorLocation(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	writeModule(t, dir, "failing.z", "func check():\n    throw \"bad state\"\ncheck()\n")
+	failing := filepath.Join(dir, "failing.z")
+
+	script := `var stack = null
+try:
+    import "failing"
+catch (e):
+    println(e.message, e.file, e.line)
+    stack = e.stack
+iter (var entry in stack):
+    println(entry)`
+	expectedOutput := "bad state " + failing + " 2\n" +
+		"[" + failing + ", line 2] in function 'check()'\n" +
+		"[" + failing + ", line 3] in top-level script\n" +
+		"[line 3] in top-level script\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -177,3 +177,51 @@ println(randStr)`
 		t.Errorf("Expected string of length 8, got %q (length %d)", output, len(output)-1)
 	}
 }
+
+func TestNativeErrorAbortsScript(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println("before")
+var content = read_file("/nonexistent/zscript/missing.txt")
+println("after", content)`
+	expectedOutput := "before\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a runtime error from a failing native function")
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestNativeErrorInsideFunction(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func middle(s):
+    var part = substring(s, 1)
+    println("not reached")
+    return part
+try:
+    middle("abc")
+catch (e):
+    println(e.message)
+    println(len(e.stack))`
+	expectedOutput := "'substring' expects 3 arguments: a string, start index, and end index.\n2\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -105,3 +105,617 @@ println(false or true)`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestRangeLiterals(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var n = 3
+var r = 0..n+1
+println(r, len(r), 1..=5)
+iter (var i in 1..=3):
+    println(i)
+println(len(range(10, 0, -3)))`
+	expectedOutput := "0..4 4 1..=5\n1\n2\n3\n4\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(3 in 1..5, 5 in 1..5, 5 in 1..=5, 2.5 in 1..5)
+println(2 in [1, 2], "ell" in "hello", "a" in {a: 1}, "z" in {a: 1})
+println(7 in range(1, 10, 3), 8 in range(1, 10, 3))`
+	expectedOutput := "true false true false\ntrue true true false\ntrue false\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var g = 10
+g += 5
+g -= 1
+g *= 2
+g /= 4
+g %= 4
+g **= 3
+println(g)
+func local():
+    var x = 1
+    var add = func(n): x += n
+    add(10)
+    x *= 3
+    return x
+println(local())
+struct Point:
+    x = 1
+var p = Point{}
+p.x += 41
+println(p.x)
+var arr = [1, 2, 3]
+arr[1] *= 10
+println(arr)
+var m = {a: 1}
+m["a"] += 1
+println(m["a"])
+var s = "ab"
+s += "cd"
+println(s)
+println(g += 1)`
+	expectedOutput := "27\n33\n42\n[1, 20, 3]\n2\nabcd\n28\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestCompoundAssignmentEvaluatesTargetOnce(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var calls = 0
+var xs = [5, 6]
+struct Box:
+    n = 1
+var box = Box{}
+func index():
+    calls += 1
+    return 1
+func target():
+    calls += 1
+    return box
+xs[index()] += 1
+target().n -= 3
+println(xs, box.n, calls)`
+	expectedOutput := "[5, 7] -2 2\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInvalidCompoundAssignmentTarget(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var a = 1
+a + 1 += 2`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for an invalid compound assignment target")
+		}
+	})
+}
+
+func TestConditionalOperator(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var n = 5
+println(n > 3 ? "big" : "small")
+println(n > 9 ? "huge" : n > 3 ? "big" : "small")
+var calls = 0
+func hit(v):
+    calls += 1
+    return v
+println(true ? hit(1) : hit(2), calls)
+println({a: n == 5 ? 1 : 2}["a"])`
+	expectedOutput := "big\nbig\n1 1\n1\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestNullSafeOperators(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(null ?? "default", 0 ?? 1, false ?? 2)
+var calls = 0
+func hit(v):
+    calls += 1
+    return v
+println(hit(null) ?? hit(null) ?? "last", hit(1) ?? hit(2), calls)
+var config = {server: {port: 8080}}
+println(config?.server?.port, config.server.port)
+println(config["server"]?["port"])
+var missing = null
+println(missing?.server.port.deep)
+println(missing?["a"]["b"])
+println(missing?.count() ?? 0)
+println(config?.db?.host ?? "localhost")
+struct P:
+    x = 1
+    func get():
+        return this.x
+var p = P{}
+println(p?.get(), p?.x)
+config.debug = true
+println(config["debug"])`
+	expectedOutput := "default 0 false\n" +
+		"last 1 3\n" +
+		"8080 8080\n" +
+		"8080\n" +
+		"null\n" +
+		"null\n" +
+		"0\n" +
+		"localhost\n" +
+		"1 1\n" +
+		"true\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMapEntryCall(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var m = {"f": func(x): x + 1}
+println(m.f(1), (m.f)(1), m?.f(2))
+var missing = null
+println(missing?.f(1))
+try:
+    m.g(1)
+catch (e):
+    println(e.message)`
+	expectedOutput := "2 2 3\n" +
+		"null\n" +
+		"Cannot call 'g'; the map has no entry with that name.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestConditionalWithArrayBranch(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var c = true
+println(c ? [1] : [2])
+println(!c ? ["]", 1]: [2])
+var a = [5, 6]
+println(a?[1], a?[0] ?? 0, {k: a?[0]}["k"])
+var flags = [true]
+match 1 with:
+    | n if (flags?[0]): println("guard")
+    | *: println("none")`
+	expectedOutput := "[1]\n[2]\n6 5 5\nguard\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestOptionalSubscriptBeforeColon(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var arr = [7]
+var missing = null
+var cond = true
+println(cond ? arr?[0] : "none")
+println(cond ? missing?[0] : "none")
+println(!cond ? arr?[0] : "none")
+match [1] with:
+    | n if n?[0]: println("yes")
+    | *: println("no")
+match [false] with:
+    | n if n?[0]: println("yes")
+    | *: println("no")`
+	expectedOutput := "7\nnull\nnone\nyes\nno\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestOptionalSubscriptIsNotConditional(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var c = true
+println(c ?[1] : [2])`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for '?[' read as an optional subscript before ':'")
+		}
+	})
+}
+
+func TestOptionalChainAssignment(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var config = {a: 1}
+config?.a = 2`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for assigning to an optional chain")
+		}
+	})
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(9007199254740993 + 0)
+println(2 ** 62, 9223372036854775807 + 1)
+println(7 / 2, 6 / 3, 7 /_ 2, -7 /_ 2, -7 % 3)
+println(1 + 0.5, get_runtype(2 * 3), is_int(2 * 3), is_int(2 * 3.0))
+println(get_runtype(5), get_runtype(parse_int("5")), get_runtype(5.5), get_runtype(5) == "number")
+println(0xFF, 0o17, 0b1010, 3 == 3.0)`
+	expectedOutput := "9007199254740993\n4611686018427387904 9.223372036854776e+18\n3.5 2 3 -4 -1\n1.5 number true false\nnumber number number true\n255 15 10 true\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIntegerOverflowBecomesFloat(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func fact(n):
+    if (n <= 1):
+        return 1
+    return n * fact(n - 1)
+println(fact(25), fact(20))
+println(10 ** 20, 2 ** 64, 2 ** 62)
+println(1000000 * 1000000 * 1000000 * 1000000)
+var smallest = -9223372036854775807 - 1
+println(smallest - 1, -smallest, smallest /_ -1)
+println(1 << 62, 1 << 63, 1 << 64, -1 << 63, 0 << 64)`
+	expectedOutput := "1.5511210043330986e+25 2432902008176640000\n" +
+		"1e+20 1.8446744073709552e+19 4611686018427387904\n" +
+		"1e+24\n" +
+		"-9.223372036854776e+18 9.223372036854776e+18 9.223372036854776e+18\n" +
+		"4611686018427387904 9.223372036854776e+18 1.8446744073709552e+19 -9223372036854775808 0\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIntegerRanges(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `iter (var i in 9007199254740992..=9007199254740994):
+    println(i)
+println(len(9007199254740992..9007199254740993))
+println(0..9223372036854775807, len(0..9223372036854775807))
+println(9223372036854775806 in 0..9223372036854775807, 9223372036854775807 in 0..9223372036854775807)
+println(len(range(9223372036854775807, -9223372036854775807, -9223372036854775807)))
+println(4.0 in 0..10, 4.5 in 0..10, 3 in range(0, 10, 3), 4 in range(0, 10, 3))
+try:
+    println(len(-9223372036854775807..=9223372036854775807))
+catch (e):
+    println(e.message)`
+	expectedOutput := "9007199254740992\n9007199254740993\n9007199254740994\n1\n" +
+		"0..9223372036854775807 9223372036854775807\n" +
+		"true false\n2\ntrue false true false\n" +
+		"'len' cannot count range -9223372036854775807..=9223372036854775807; it has more values than an int holds.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestIntegerLiteralOverflow(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(12345678901234567890, 99999999999999999999)
+println(9223372036854775807, 9223372036854775808, -9223372036854775808)
+println(is_int(9223372036854775807), is_int(9223372036854775808))`
+	expectedOutput := "1.2345678901234567e+19 1e+20\n" +
+		"9223372036854775807 9.223372036854776e+18 -9.223372036854776e+18\n" +
+		"true false\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(6 & 3, 6 | 3, 6 ^ 3, ~5, 1 << 10, -16 >> 2)
+println(1 | 2 ^ 3 & 4 << 1, 5 & 1 == 1)
+var flags = 0b0101
+flags |= 0b1000
+flags <<= 1
+println(flags)
+var n = 3
+println(match n with { | 3: (n | 8) | *: 0 })
+match n with:
+    | 3: println(n | 16)
+    | *: println("other")`
+	expectedOutput := "2 7 5 -6 1024 -4\n3 true\n26\n11\n19\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestBitwiseOperandErrors(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `try:
+    println(1.5 & 2)
+catch (e):
+    println(e.message)
+try:
+    println(1 << -1)
+catch (e):
+    println(e.message)
+try:
+    println(~2.0)
+catch (e):
+    println(e.message)`
+	expectedOutput := "Operands for '&' must be integers (got float).\nShift count for '<<' cannot be negative (got -1).\nOperand for '~' must be an integer (got float).\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestBigIntArithmetic(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(2n ** 100n, get_runtype(2n))
+println(9223372036854775807n + 1, -7n /_ 2n, -7n % 3n, 7n / 2n)
+println(1n << 70, 0xFFn & 0x0F, ~5n, -3n)
+println(3n == 3, 3n < 3.5, bigint("123456789012345678901234567890") > 1)
+var n = 10n
+n *= n
+println(n, to_int(n) + 1)`
+	expectedOutput := "1267650600228229401496703205376 bigint\n9223372036854775808 -4 -1 3.5\n1180591620717411303424 15 -6 -3\ntrue true true\n100 101\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var a = decimal("0.1")
+println(a + decimal("0.2"), a + decimal("0.2") == decimal("0.3"), get_runtype(a))
+println(decimal("10.00") / 4, decimal(1) / 3, decimal("19.99") * 3, decimal("1.10"))
+println(decimal("1.1") ** 2, decimal(2) ** -2, 200 %% decimal("19.99"), decimal("7.5") % 2)
+println(decimal("0.5") == 0.5, decimal("0.1") == 0.1, -decimal("1.5"), decimal(0.1))
+println(sprintf("%.2f|%s|%d", decimal("2.675"), decimal("1.50"), 2n ** 70n))`
+	expectedOutput := "0.3 true decimal\n2.50 0.33333333333333333333 59.97 1.10\n1.21 0.25 39.98 1.5\ntrue false -1.5 0.1\n2.68|1.50|1180591620717411303424\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println(decimal_round(decimal("2.665"), 2), decimal_round(decimal("2.665"), 2, "half_up"))
+println(decimal_round(decimal("-2.5"), 0, "ceiling"), decimal_round(decimal("-2.5"), 0, "floor"), decimal_round(3, 2))
+println(decimal_precision(4), decimal(2) / 3)
+println(decimal_rounding("down"), decimal(2) / 3, decimal_rounding())`
+	expectedOutput := "2.66 2.67\n-2 -3 3.00\n20 0.6667\nhalf_even 0.6666 down\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestBigNumberErrors(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `try:
+    println(1n + 0.5)
+catch (e):
+    println(e.message)
+try:
+    println(decimal(1) / 0)
+catch (e):
+    println(e.message)
+try:
+    println(decimal(1) & 1)
+catch (e):
+    println(e.message)
+try:
+    println(bigint(decimal("1.5")))
+catch (e):
+    println(e.message)
+try:
+    println(decimal_rounding("nearest"))
+catch (e):
+    println(e.message)`
+	expectedOutput := "Cannot mix bigint and float in '+'; convert the float with bigint() or the bigint with to_float() first.\n" +
+		"Division by zero in '/'.\n" +
+		"Operands for '&' must be integers (got decimal).\n" +
+		"Cannot convert 1.5 to a bigint; it is not a whole number. Round it with decimal_round() first.\n" +
+		"Unknown rounding mode 'nearest'; expected one of 'half_even', 'half_up', 'half_down', 'up', 'down', 'ceiling', 'floor'.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
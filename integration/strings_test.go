@@ -68,3 +68,158 @@ println(str_contains(str, "World"))`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestStringSlicing(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var s = "héllo world"
+println(s[0:5])
+println(s[6:])
+println(s[::-1])
+println(s[1..=4])`
+	expectedOutput := "héllo\nworld\ndlrow olléh\néllo\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := "var name = \"Ada\"\n" +
+		"var items = [1, 2, 3]\n" +
+		"println(\"Hello ${name}, you have ${len(items)} items\")\n" +
+		"println(\"${1 + 2}${\"!\"} ${true} ${null} ${items}\")\n" +
+		"println(\"nested ${\"<${name}>\"} and ${ {a: 1}[\"a\"] }\")\n" +
+		"println(\"literal ${{name} and $5\")"
+	expectedOutput := "Hello Ada, you have 3 items\n" +
+		"3! true null [1, 2, 3]\n" +
+		"nested <Ada> and 1\n" +
+		"literal ${name} and $5\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestUnterminatedInterpolation(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var name = "Ada"
+println("Hello ${name")`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for an unterminated interpolation")
+		}
+	})
+}
+
+func TestStringEscapes(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println("say \"hi\"\tnow\\")
+println("\x41\x62 \u{e9} \u{1F600} \${x}")
+println(str_length("a\0b\r"))
+println('\'' + '\n' + "end")`
+	expectedOutput := "say \"hi\"\tnow\\\nAb é 😀 ${x}\n4\n'\nend\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInvalidEscape(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `println("C:\qux")`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for an invalid escape sequence")
+		}
+	})
+}
+
+func TestRawAndMultilineStrings(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var id = 7
+println(r"C:\new\table ${id}")
+func query():
+    return """
+        SELECT name
+          FROM users
+         WHERE id = ${id}
+        """
+println(query())
+println(r"""
+    raw\n
+      kept""")
+println("""one "quoted" line""")`
+	expectedOutput := "C:\\new\\table ${id}\n" +
+		"SELECT name\n  FROM users\n WHERE id = 7\n" +
+		"raw\\n\n  kept\n" +
+		"one \"quoted\" line\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestMultilineStringMixedIndentation(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := "var s = \"\"\"\n\tone\n    two\n    \"\"\"\nprintln(s)\n" +
+		"var t = \"\"\"\n\t  a\n\t    b\n\t\"\"\"\nprintln(t)"
+	expectedOutput := "\tone\n    two\na\n  b\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
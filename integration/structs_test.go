@@ -103,3 +103,305 @@ println(v.z)`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestEnumVariants(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `enum Color { Red, Green, Blue }
+var value = Color::Blue
+println(value)
+println(value == Color::Blue, value == Color::Red)
+iter (var c in Color):
+    println(c)`
+	expectedOutput := "Color::Blue\ntrue false\nColor::Red\nColor::Green\nColor::Blue\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestEnumPayloadsAndMatch(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `enum Shape:
+    Circle(radius)
+    Rect(w, h)
+    Empty
+func area(s):
+    match s with:
+        | Shape::Circle(r): return 3 * r * r
+        | Shape::Rect(w, h): return w * h
+        | Shape::Empty: return 0
+var c = Shape::Circle(2)
+println(c, c.radius)
+println(area(c), area(Shape::Rect(3, 4)), area(Shape::Empty))
+println(Shape::Rect(1, 2) == Shape::Rect(1, 2), Shape::Rect(1, 2) == Shape::Rect(2, 1))`
+	expectedOutput := "Shape::Circle(2) 2\n12 12 0\ntrue false\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestEnumPatternWithoutPayload(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `enum Shape:
+    Circle(r)
+    Square(s)
+func describe(s):
+    match s with:
+        | Shape::Circle(r): println("circle", r)
+        | Shape::Circle: println("bare circle")
+        | *: println("other")
+describe(Shape::Circle)
+describe(Shape::Circle(2))
+describe(Shape::Square(3))`
+	expectedOutput := "bare circle\ncircle 2\nother\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestEnumUnknownVariant(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `enum Color { Red, Green }
+println(Color::Grene)`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a runtime error for an unknown variant")
+		}
+	})
+}
+
+func TestStructMethods(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Player:
+    name = "anon"
+    x = 0
+    func move(dx):
+        this.x = this.x + dx
+        return this
+    func describe():
+        return this.name + " at " + to_str(this.x)
+var p = Player{name = "ada"}
+p.move(3).move(4)
+println(p.describe())
+var other = Player{}
+other.move(1)
+println(other.describe(), p.x)`
+	expectedOutput := "ada at 7\nanon at 1 7\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestBoundMethodsAndClosures(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Counter:
+    n = 0
+    func inc():
+        this.n = this.n + 1
+        return this.n
+    func incrementer():
+        func run():
+            return this.inc()
+        return run
+var c = Counter{}
+var inc = c.inc
+inc()
+inc()
+var run = c.incrementer()
+println(run(), c.n)`
+	expectedOutput := "3 3\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestThisOutsideMethod(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func notMethod():
+    return this
+println(notMethod())`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for 'this' outside a method")
+		}
+	})
+}
+
+func TestStructInheritance(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Entity:
+    name = "entity"
+    hp = 10
+    func describe():
+        return this.name + " " + to_str(this.hp)
+    func hit(n):
+        this.hp = this.hp - n
+struct Enemy < Entity:
+    damage = 3
+    func describe():
+        return "enemy " + super.describe()
+struct Boss < Enemy:
+    hp = 50
+    func describe():
+        var parent = super.describe
+        return "boss " + parent()
+struct Minion < Enemy
+var b = Boss{name = "drake"}
+b.hit(5)
+println(b.describe(), b.damage)
+println(Minion{}.describe())
+println(is_instance(b, Entity), is_instance(b, Boss), is_instance(Enemy{}, Boss), is_instance(1, Entity))
+match b with:
+    | Enemy{name}: println("matched", name)
+    | *: println("no match")`
+	expectedOutput := "boss enemy drake 45 3\nenemy entity 10\ntrue true false false\nmatched drake\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestSuperWithoutParent(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Lonely:
+    func greet():
+        return super.greet()`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for 'super' in a struct without a parent")
+		}
+	})
+}
+
+func TestStructDefaultExpressions(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var calls = 0
+func count():
+    calls = calls + 1
+    return calls
+struct Point:
+    x = 0
+struct Bag:
+    id = count()
+    items = []
+    pos = Point{x = 5}
+    label = "bag " + to_str(this.id)
+var a = Bag{}
+var b = Bag()
+push(a.items, 1)
+a.pos.x = 9
+println(a.items, b.items, a.pos.x, b.pos.x, a.label, b.label)
+var c = Bag{id = 7}
+println(c.id, c.label, calls)`
+	expectedOutput := "[1] [] 9 5 bag 1 bag 2\n7 bag 3 3\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestInheritedDefaultExpressions(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Base:
+    list = []
+    name = "base"
+struct Child < Base:
+    name = "child of " + to_str(len(this.list))
+var x = Child{}
+var y = Child{}
+push(x.list, 1)
+println(x.name, x.list, y.list)`
+	expectedOutput := "child of 0 [1] []\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/cryptrunner49/zscript/internal/core"
@@ -60,3 +62,253 @@ println(negative)`
 		t.Errorf("Expected %q, got %q", expectedOutput, output)
 	}
 }
+
+func TestDestructuringDeclarations(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Point:
+    x
+    y
+var [a, b, ...rest] = [1, 2, 3, 4]
+println(a, b, rest)
+var {name, age, ...others} = {name: "Ann", age: 30, city: "Oslo"}
+println(name, age, others)
+var Point{x, y} = Point{x = 3, y = 4}
+println(x, y)
+func local():
+    var [first, ...empty] = ["only"]
+    var {missing} = {}
+    println(first, empty, missing)
+local()`
+	expectedOutput := "1 2 [3, 4]\nAnn 30 {city: Oslo}\n3 4\nonly [] null\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestDestructuringAssignment(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var a = 1
+var b = 2
+println(a, b)
+[a, b] = [b, a]
+println(a, b)
+func swap():
+    var p = "p"
+    var q = "q"
+    [p, q] = [q, p]
+    {p} = {p: "map"}
+    println(p, q)
+swap()`
+	expectedOutput := "1 2\n2 1\nmap p\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestDestructuringMismatch(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `struct Point:
+    x
+try:
+    var [a, b] = [1, 2, 3]
+catch (e):
+    println(e.message)
+try:
+    var [a, b, ...rest] = [1]
+catch (e):
+    println(e.message)
+try:
+    var Point{x} = {x: 1}
+catch (e):
+    println(e.message)`
+	expectedOutput := "Array pattern expects 2 elements but the array has 3.\n" +
+		"Array pattern expects at least 2 elements but the array has 1.\n" +
+		"Cannot destructure map with a 'Point' pattern.\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestDestructuringDuplicateName(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `var [a, a] = [1, 2]`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for a name bound twice in a pattern")
+		}
+	})
+}
+
+func TestConstDeclarations(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `const LIMIT = 10
+const [W, H] = [4, 3]
+func area():
+    const scale = 2
+    func scaled():
+        return W * H * scale
+    return scaled()
+const items = []
+push(items, LIMIT)
+mod Config:
+    const NAME = "app"
+    var level = 1
+Config.level = 2
+println(LIMIT, W, H, area(), items, Config.NAME, Config.level)`
+	expectedOutput := "10 4 3 24 [10] app 2\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
+
+func TestConstReassignment(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `const LIMIT = 10
+LIMIT = 20`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for assigning to a constant")
+		}
+	})
+}
+
+func TestConstCompoundAssignment(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `func counter():
+    const count = 0
+    func next():
+        count += 1
+        return count
+    return next`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for updating a captured constant")
+		}
+	})
+}
+
+func TestConstIncrement(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `const [a, b] = [1, 2]
+println(a++)`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for incrementing a constant")
+		}
+	})
+}
+
+func TestConstWithoutValue(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	script := `const LIMIT
+println(LIMIT)`
+
+	captureOutput(t, func() {
+		result := core.Interpret(script, "<script>")
+		if result == 0 {
+			t.Fatalf("Expected a compile error for a constant without a value")
+		}
+	})
+}
+
+func TestConstRuntimeProtection(t *testing.T) {
+	vm.InitVM([]string{"zscript"})
+	t.Cleanup(vm.FreeVM)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "settings.z"), []byte("export var LIMIT = 99\n"), 0644); err != nil {
+		t.Fatalf("Failed to write module: %v", err)
+	}
+
+	script := `func reset():
+    LIMIT = 0
+const LIMIT = 10
+mod Config:
+    const NAME = "app"
+try:
+    reset()
+catch (e):
+    println(e.message)
+try:
+    Config.NAME = "other"
+catch (e):
+    println(e.message)
+try:
+    import "settings.z"
+catch (e):
+    println(e.message)
+println(LIMIT, Config.NAME)`
+	expectedOutput := "Cannot assign to constant 'LIMIT'.\n" +
+		"Cannot assign to constant 'NAME' of module 'Config'.\n" +
+		"Cannot redeclare constant 'LIMIT'.\n" +
+		"10 app\n"
+
+	output := captureOutput(t, func() {
+		result := core.Interpret(script, filepath.Join(dir, "main.z"))
+		if result != 0 {
+			t.Fatalf("Interpretation failed: %d", result)
+		}
+	})
+
+	if output != expectedOutput {
+		t.Errorf("Expected %q, got %q", expectedOutput, output)
+	}
+}
@@ -2,8 +2,9 @@ package compiler
 
 import (
 	"fmt"
-	"path/filepath"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/cryptrunner49/zscript/internal/common"
 	"github.com/cryptrunner49/zscript/internal/debug"
@@ -18,8 +19,10 @@ var compiledFiles = make(map[string]*runtime.ObjFunction)
 type FunctionType int
 
 const (
-	TYPE_FUNCTION FunctionType = iota // Regular function definition.
-	TYPE_SCRIPT                       // Top-level script execution.
+	TYPE_FUNCTION    FunctionType = iota // Regular function definition.
+	TYPE_METHOD                          // Method defined in a struct body.
+	TYPE_INITIALIZER                     // Hidden method evaluating the field defaults of a struct.
+	TYPE_SCRIPT                          // Top-level script execution.
 )
 
 // Parser holds the current and previous tokens and error flags for parsing.
@@ -28,6 +31,11 @@ type Parser struct {
 	previous  token.Token // The previous token processed.
 	hadError  bool        // Flag indicating if a parsing error has occurred.
 	panicMode bool        // Flag to suppress cascading errors after an error is encountered.
+
+	nesting    int // Number of brackets, parentheses and braces open after the previous token.
+	armNesting int // Nesting of the inline match arm body being compiled, or -1 outside one.
+
+	afterOptionalSubscript bool // The previous token closed an optional subscript, 'a?[i]'.
 }
 
 // Local represents a local variable with its name, scope depth, and if it was captured by a closure.
@@ -35,12 +43,14 @@ type Local struct {
 	name       token.Token // Token representing the variable's name.
 	depth      int         // Scope depth where the variable was declared.
 	isCaptured bool        // Indicates if the variable is captured by an enclosing function.
+	isConst    bool        // Indicates if the variable was declared with 'const'.
 }
 
 // Upvalue holds information about a variable captured by a closure.
 type Upvalue struct {
 	index   uint8 // Index of the variable in the parent's local variables.
 	isLocal bool  // Indicates if the captured variable was a local variable.
+	isConst bool  // Indicates if the captured variable was declared with 'const'.
 }
 
 // JumpType defines different kinds of jumps.
@@ -50,17 +60,19 @@ const (
 	JUMP_WHILE JumpType = iota // While jump.
 	JUMP_FOR                   // For jump.
 	JUMP_MATCH                 // Match jump.
+	JUMP_ITER                  // Iter jump.
 )
 
 // Loop is used to manage loop state during compilation, including jump patching.
 type Loop struct {
-	jumpType        JumpType // Type of jump (while, for or match).
+	jumpType        JumpType // Type of jump (while, for, match or iter).
 	start           int      // Bytecode index where the loop begins.
 	exitPatches     []int    // List of jump offsets to patch for loop exit.
 	continuePatches []int    // List of jump offsets to patch for continue statements.
 	exitAddress     int      // Address to jump to when exiting the loop.
 	hasIncrement    bool     // Flag indicating if the loop has an increment expression.
 	incrementStart  int      // Bytecode index where the increment expression starts.
+	localCount      int      // Locals live when the loop began; break and continue discard the rest.
 }
 
 // Compiler holds the current state of the compilation process.
@@ -73,27 +85,46 @@ type Compiler struct {
 	upvalues     [256]Upvalue         // Fixed array of upvalues for closures.
 	scopeDepth   int                  // Current depth of local scope nesting.
 	loops        []Loop               // Stack of active loops for break/continue handling.
-	scriptDir    string
+	tries        []TryBlock           // Stack of 'try' statements whose blocks are being compiled.
+	scriptPath   string               // Path of the script being compiled.
+}
+
+// StructCompiler tracks a struct declaration whose methods are being compiled.
+type StructCompiler struct {
+	enclosing *StructCompiler // The struct declaration this one is nested in, if any.
+	hasParent bool            // Whether the struct inherits from another one.
 }
 
-var parser Parser     // Global parser state.
-var current *Compiler // Pointer to the current compiler instance.
+var parser Parser                 // Global parser state.
+var current *Compiler             // Pointer to the current compiler instance.
+var currentStruct *StructCompiler // Innermost struct declaration being compiled, if any.
+var constGlobals map[string]bool  // Names of the globals declared with 'const' in the script being compiled.
+
+var compilingModule *runtime.ObjModule // Namespace of the imported file being compiled, or nil for the main script.
+var exporting bool                     // Whether the declaration being compiled follows 'export'.
 
 // Precedence defines operator precedence levels.
 type Precedence int
 
 const (
-	PREC_NONE       Precedence = iota // No precedence.
-	PREC_ASSIGNMENT                   // Assignment operators.
-	PREC_OR                           // Logical OR.
-	PREC_AND                          // Logical AND.
-	PREC_EQUALITY                     // Equality operators.
-	PREC_COMPARISON                   // Comparison operators.
-	PREC_TERM                         // Term operators (addition, subtraction).
-	PREC_FACTOR                       // Factor operators (multiplication, division).
-	PREC_UNARY                        // Unary operators.
-	PREC_CALL                         // Call and subscript operators.
-	PREC_PRIMARY                      // Primary expressions.
+	PREC_NONE        Precedence = iota // No precedence.
+	PREC_ASSIGNMENT                    // Assignment operators.
+	PREC_CONDITIONAL                   // Conditional operator ('cond ? a : b').
+	PREC_COALESCE                      // Null-coalescing operator ('??').
+	PREC_OR                            // Logical OR.
+	PREC_AND                           // Logical AND.
+	PREC_EQUALITY                      // Equality operators.
+	PREC_COMPARISON                    // Comparison operators.
+	PREC_RANGE                         // Range operators ('..' and '..=').
+	PREC_BIT_OR                        // Bitwise OR ('|').
+	PREC_BIT_XOR                       // Bitwise XOR ('^').
+	PREC_BIT_AND                       // Bitwise AND ('&').
+	PREC_SHIFT                         // Shift operators ('<<' and '>>').
+	PREC_TERM                          // Term operators (addition, subtraction).
+	PREC_FACTOR                        // Factor operators (multiplication, division).
+	PREC_UNARY                         // Unary operators.
+	PREC_CALL                          // Call and subscript operators.
+	PREC_PRIMARY                       // Primary expressions.
 )
 
 // ParseFn represents a pointer to a parsing function.
@@ -119,6 +150,7 @@ func init() {
 	rules[token.TOKEN_RIGHT_BRACKET] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_COMMA] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_DOT] = ParseRule{nil, dot, PREC_CALL}
+	rules[token.TOKEN_COLON_COLON] = ParseRule{nil, variantAccess, PREC_CALL}
 	rules[token.TOKEN_MINUS] = ParseRule{unary, binary, PREC_TERM}
 	rules[token.TOKEN_PLUS] = ParseRule{nil, binary, PREC_TERM}
 	rules[token.TOKEN_SEMICOLON] = ParseRule{nil, nil, PREC_NONE}
@@ -128,8 +160,16 @@ func init() {
 	rules[token.TOKEN_STAR_STAR] = ParseRule{nil, binary, PREC_FACTOR}
 	rules[token.TOKEN_FLOOR] = ParseRule{nil, binary, PREC_FACTOR}
 	rules[token.TOKEN_PERCENT_PERCENT] = ParseRule{nil, binary, PREC_FACTOR}
-	rules[token.TOKEN_PIPE] = ParseRule{nil, nil, PREC_NONE}
-	rules[token.TOKEN_QUESTION] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_PIPE] = ParseRule{nil, binary, PREC_BIT_OR}
+	rules[token.TOKEN_CARET] = ParseRule{nil, binary, PREC_BIT_XOR}
+	rules[token.TOKEN_AMPERSAND] = ParseRule{nil, binary, PREC_BIT_AND}
+	rules[token.TOKEN_LESS_LESS] = ParseRule{nil, binary, PREC_SHIFT}
+	rules[token.TOKEN_GREATER_GREATER] = ParseRule{nil, binary, PREC_SHIFT}
+	rules[token.TOKEN_TILDE] = ParseRule{unary, nil, PREC_NONE}
+	rules[token.TOKEN_QUESTION] = ParseRule{nil, conditional, PREC_CONDITIONAL}
+	rules[token.TOKEN_QUESTION_QUESTION] = ParseRule{nil, coalesce, PREC_COALESCE}
+	rules[token.TOKEN_QUESTION_DOT] = ParseRule{nil, optionalChain, PREC_CALL}
+	rules[token.TOKEN_QUESTION_BRACKET] = ParseRule{nil, optionalChain, PREC_CALL}
 	rules[token.TOKEN_AT] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_HASH] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_DOLLAR] = ParseRule{nil, nil, PREC_NONE}
@@ -142,34 +182,56 @@ func init() {
 	rules[token.TOKEN_GREATER_EQUAL] = ParseRule{nil, binary, PREC_COMPARISON}
 	rules[token.TOKEN_LESS] = ParseRule{nil, binary, PREC_COMPARISON}
 	rules[token.TOKEN_LESS_EQUAL] = ParseRule{nil, binary, PREC_COMPARISON}
+	rules[token.TOKEN_IN] = ParseRule{nil, binary, PREC_COMPARISON}
+	rules[token.TOKEN_DOT_DOT] = ParseRule{nil, binary, PREC_RANGE}
+	rules[token.TOKEN_DOT_DOT_EQUAL] = ParseRule{nil, binary, PREC_RANGE}
+	rules[token.TOKEN_PLUS_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_MINUS_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_STAR_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_SLASH_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_PERCENT_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_STAR_STAR_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_AMPERSAND_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_PIPE_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_CARET_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_LESS_LESS_EQUAL] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_GREATER_GREATER_EQUAL] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_PLUS_PLUS] = ParseRule{unary, nil, PREC_UNARY}
 	rules[token.TOKEN_MINUS_MINUS] = ParseRule{unary, nil, PREC_UNARY}
 	rules[token.TOKEN_IDENTIFIER] = ParseRule{variable, nil, PREC_NONE}
 	rules[token.TOKEN_CHAR] = ParseRule{charLiteral, nil, PREC_NONE}
 	rules[token.TOKEN_STRING] = ParseRule{stringLiteral, nil, PREC_NONE}
+	rules[token.TOKEN_INTERPOLATION] = ParseRule{interpolation, nil, PREC_NONE}
 	rules[token.TOKEN_NUMBER] = ParseRule{number, nil, PREC_NONE}
 	rules[token.TOKEN_AND] = ParseRule{nil, and, PREC_AND}
 	rules[token.TOKEN_CLASS] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_ELSE] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_FALSE] = ParseRule{literal, nil, PREC_NONE}
 	rules[token.TOKEN_FOR] = ParseRule{nil, nil, PREC_NONE}
-	rules[token.TOKEN_FUNC] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_FUNC] = ParseRule{lambda, nil, PREC_NONE}
 	rules[token.TOKEN_IF] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_NULL] = ParseRule{literal, nil, PREC_NONE}
 	rules[token.TOKEN_OR] = ParseRule{nil, or, PREC_OR}
 	rules[token.TOKEN_RETURN] = ParseRule{nil, nil, PREC_NONE}
-	rules[token.TOKEN_SUPER] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_YIELD] = ParseRule{yieldExpression, nil, PREC_NONE}
+	rules[token.TOKEN_SUPER] = ParseRule{superExpression, nil, PREC_NONE}
 	rules[token.TOKEN_STRUCT] = ParseRule{nil, nil, PREC_NONE}
-	rules[token.TOKEN_THIS] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_ENUM] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_THIS] = ParseRule{thisExpression, nil, PREC_NONE}
 	rules[token.TOKEN_TRUE] = ParseRule{literal, nil, PREC_NONE}
 	rules[token.TOKEN_VAR] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_CONST] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_WHILE] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_ITER] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_BREAK] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_CONTINUE] = ParseRule{nil, nil, PREC_NONE}
-	rules[token.TOKEN_MATCH] = ParseRule{Prefix: nil, Infix: nil, Precedence: PREC_NONE}
+	rules[token.TOKEN_MATCH] = ParseRule{matchExpression, nil, PREC_NONE}
 	rules[token.TOKEN_WITH] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_THROUGH] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_TRY] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_CATCH] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_FINALLY] = ParseRule{nil, nil, PREC_NONE}
+	rules[token.TOKEN_THROW] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_RANDOM] = ParseRule{random, nil, PREC_NONE}
 	rules[token.TOKEN_IMPORT] = ParseRule{nil, nil, PREC_NONE}
 	rules[token.TOKEN_EXPORT] = ParseRule{nil, nil, PREC_NONE}
@@ -188,11 +250,29 @@ func dot(canAssign bool) {
 	if canAssign && match(token.TOKEN_EQUAL) {
 		expression()
 		emitBytes(byte(runtime.OP_SET_PROPERTY), name)
+	} else if op, ok := matchCompoundAssignment(canAssign); ok {
+		// Keep the object for the store while the current value is read.
+		emitByte(byte(runtime.OP_DUP))
+		emitBytes(byte(runtime.OP_GET_PROPERTY), name)
+		expression()
+		emitByte(byte(op))
+		emitBytes(byte(runtime.OP_SET_PROPERTY), name)
+	} else if match(token.TOKEN_LEFT_PAREN) {
+		// Calling a property directly invokes it without creating a bound method.
+		argCount := argumentList()
+		emitBytes(byte(runtime.OP_INVOKE), name)
+		emitByte(argCount)
 	} else {
 		emitBytes(byte(runtime.OP_GET_PROPERTY), name)
 	}
 }
 
+// variantAccess handles enum variant access (e.g., Color::Red).
+func variantAccess(canAssign bool) {
+	consume(token.TOKEN_IDENTIFIER, "Expected a variant name after '::' (e.g., 'Color::Red').")
+	emitBytes(byte(runtime.OP_GET_VARIANT), identifierConstant(parser.previous))
+}
+
 // emitByte writes a single byte into the current chunk with the current line number.
 func emitByte(b byte) {
 	currentChunk().Write(b, parser.previous.Line)
@@ -206,7 +286,15 @@ func emitBytes(b1, b2 byte) {
 
 // emitReturn writes the return opcode to the chunk, ending the function.
 func emitReturn() {
-	emitByte(byte(runtime.OP_RNULL))
+	if current.functionType == TYPE_INITIALIZER {
+		// A struct initializer hands the instance it set up back to the caller.
+		emitBytes(byte(runtime.OP_GET_LOCAL), 0)
+	} else if current.functionType == TYPE_SCRIPT && compilingModule != nil {
+		// An imported file hands its namespace back to the importer.
+		emitByte(byte(runtime.OP_NAMESPACE))
+	} else {
+		emitByte(byte(runtime.OP_RNULL))
+	}
 	emitByte(byte(runtime.OP_RETURN))
 }
 
@@ -227,11 +315,15 @@ func endCompiler() *runtime.ObjFunction {
 
 // block compiles a block statement by repeatedly compiling declarations until a closing brace is found.
 func block() {
+	// An indented block ends inline match arms it is nested in, so a '|' inside it is an operator.
+	enclosingArm := parser.armNesting
+	parser.armNesting = -1
 	consume(token.TOKEN_INDENT, "Expected indented block after ':'.")
 	for !check(token.TOKEN_DEDENT) && !check(token.TOKEN_EOF) {
 		declaration()
 	}
 	consume(token.TOKEN_DEDENT, "Expected dedent after block.")
+	parser.armNesting = enclosingArm
 }
 
 // beginScope increases the scope depth, starting a new local variable scope.
@@ -252,6 +344,19 @@ func endScope() {
 	}
 }
 
+// discardLocals emits the pops (or upvalue closes) for every local declared after the first
+// localCount ones, without removing them from the compiler. It is used by jumps that leave scopes
+// early, such as break and continue.
+func discardLocals(localCount int) {
+	for i := current.localCount - 1; i >= localCount; i-- {
+		if current.locals[i].isCaptured {
+			emitByte(byte(runtime.OP_CLOSE_UPVALUE))
+		} else {
+			emitByte(byte(runtime.OP_POP))
+		}
+	}
+}
+
 // expression compiles an expression using the assignment precedence level.
 func expression() {
 	parsePrecedence(PREC_ASSIGNMENT)
@@ -267,14 +372,22 @@ func statement() {
 		forStatement()
 	} else if match(token.TOKEN_ITER) {
 		iterStatement()
+	} else if match(token.TOKEN_MATCH) {
+		matchStatement()
 	} else if match(token.TOKEN_BREAK) {
 		breakStatement()
 	} else if match(token.TOKEN_CONTINUE) {
 		continueStatement()
 	} else if match(token.TOKEN_RETURN) {
 		returnStatement()
+	} else if match(token.TOKEN_TRY) {
+		tryStatement()
+	} else if match(token.TOKEN_THROW) {
+		throwStatement()
 	} else if match(token.TOKEN_PASS) {
 		passStatement()
+	} else if isDestructuringAssignment() {
+		destructuringAssignment()
 	} else if match(token.TOKEN_LEFT_BRACE) {
 		beginScope()
 		block()
@@ -288,16 +401,28 @@ func statement() {
 func declaration() {
 	if match(token.TOKEN_STRUCT) {
 		structDeclaration()
-	} else if match(token.TOKEN_FUNC) {
+	} else if match(token.TOKEN_ENUM) {
+		enumDeclaration()
+	} else if check(token.TOKEN_FUNC) && lexer.PeekToken().Type != token.TOKEN_LEFT_PAREN {
+		// 'func(' starts a lambda, which is compiled as an expression statement.
+		advance()
 		fnDeclaration()
 	} else if match(token.TOKEN_VAR) {
 		varDeclaration()
+	} else if match(token.TOKEN_CONST) {
+		constDeclaration()
 	} else if match(token.TOKEN_DEF) {
 		defDeclaration()
 	} else if match(token.TOKEN_MOD) {
 		modDeclaration()
 	} else if match(token.TOKEN_IMPORT) {
 		importDeclaration()
+	} else if check(token.TOKEN_IDENTIFIER) && parser.current.Start == "from" && lexer.PeekToken().Type == token.TOKEN_STRING {
+		// 'from' is only a keyword in front of the path of a file to import.
+		advance()
+		fromImportDeclaration()
+	} else if match(token.TOKEN_EXPORT) {
+		exportDeclaration()
 	} else if match(token.TOKEN_USE) {
 		useDeclaration()
 	} else {
@@ -314,6 +439,27 @@ func call(canAssign bool) {
 	emitBytes(byte(runtime.OP_CALL), argCount)
 }
 
+// continuesExpression reports whether the current token is an infix operator binding at least as
+// tightly as precedence, continuing the expression being compiled.
+func continuesExpression(precedence Precedence) bool {
+	if precedence > getRule(parser.current.Type).Precedence {
+		return false
+	}
+	// A destructuring assignment on the next line starts a new statement rather than indexing
+	// or instantiating the value just compiled.
+	if parser.current.Line != parser.previous.Line && isDestructuringAssignment() {
+		return false
+	}
+	// A '|' directly in the body of an inline match arm starts the next arm; a bitwise OR there
+	// must be put in parentheses.
+	if parser.current.Type == token.TOKEN_PIPE && parser.armNesting == parser.nesting {
+		return false
+	}
+	// '::' names an enum variant only when a name follows; otherwise it separates the start
+	// of a slice from its step, as in 'a[1::2]'.
+	return parser.current.Type != token.TOKEN_COLON_COLON || lexer.PeekToken().Type == token.TOKEN_IDENTIFIER
+}
+
 // parsePrecedence compiles an expression based on a minimum precedence, handling operators accordingly.
 func parsePrecedence(precedence Precedence) {
 	advance()
@@ -324,7 +470,7 @@ func parsePrecedence(precedence Precedence) {
 	}
 	canAssign := precedence <= PREC_ASSIGNMENT
 	prefixRule(canAssign)
-	for precedence <= getRule(parser.current.Type).Precedence {
+	for continuesExpression(precedence) {
 		advance()
 		infixRule := getRule(parser.previous.Type).Infix
 		infixRule(canAssign)
@@ -332,6 +478,9 @@ func parsePrecedence(precedence Precedence) {
 	if canAssign && match(token.TOKEN_EQUAL) {
 		reportError("Invalid assignment target; only variables or properties can be assigned.")
 	}
+	if _, ok := matchCompoundAssignment(canAssign); ok {
+		reportError("Invalid assignment target; only variables, properties or elements can be updated.")
+	}
 }
 
 // addLocal adds a new local variable to the current compiler state.
@@ -344,6 +493,7 @@ func addLocal(name token.Token) {
 	local.name = name
 	local.depth = -1 // Uninitialized.
 	local.isCaptured = false
+	local.isConst = false
 	current.localCount++
 }
 
@@ -369,36 +519,74 @@ func defineVariable(global uint8) {
 	if current.scopeDepth > 0 {
 		markInitialized()
 	} else {
+		exportGlobal(global)
 		emitBytes(byte(runtime.OP_DEFINE_GLOBAL), global)
 	}
 }
 
+// defineConstant defines the variable just declared as a constant: a local is marked so that the
+// compiler rejects assignments to it, a global is also recorded by the VM.
+func defineConstant(global uint8, name token.Token) {
+	if current.scopeDepth > 0 {
+		current.locals[current.localCount-1].isConst = true
+		markInitialized()
+	} else {
+		constGlobals[name.Start] = true
+		exportGlobal(global)
+		emitBytes(byte(runtime.OP_DEFINE_CONST), global)
+	}
+}
+
 // grouping compiles a grouped expression enclosed in parentheses.
 func grouping(canAssign bool) {
 	expression()
 	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' to close grouped expression (unmatched '(').")
 }
 
-// stringLiteral compiles a string literal by removing the enclosing quotes and emitting a constant.
+// stringLiteral compiles a string literal, emitting the text the lexer decoded as a constant.
 func stringLiteral(canAssign bool) {
 	text := parser.previous.Start
 	if len(text) < 2 {
 		reportError("Invalid string literal; must be enclosed in quotes (e.g., \"hello\").")
 		return
 	}
-	str := text[1 : len(text)-1]
+	str := parser.previous.Literal
 	emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(str)})
 }
 
-// charLiteral compiles a character literal by removing the enclosing quotes.
+// interpolation compiles a string literal with '${expression}' parts. The literal parts and the
+// values of the expressions are pushed in order and OP_INTERPOLATE joins them into one string.
+func interpolation(canAssign bool) {
+	enclosingArm := parser.armNesting
+	parser.armNesting = -1
+	defer func() { parser.armNesting = enclosingArm }()
+	parts := 0
+	for {
+		emitConstant(runtime.ObjVal(runtime.NewObjString(parser.previous.Literal)))
+		expression()
+		parts += 2
+		if match(token.TOKEN_INTERPOLATION) {
+			continue
+		}
+		consume(token.TOKEN_STRING, "Expected '}' after the expression in a string interpolation.")
+		emitConstant(runtime.ObjVal(runtime.NewObjString(parser.previous.Literal)))
+		parts++
+		break
+	}
+	if parts > 255 {
+		reportError("A string cannot interpolate more than 127 expressions.")
+	}
+	emitBytes(byte(runtime.OP_INTERPOLATE), byte(parts))
+}
+
+// charLiteral compiles a character literal from the character the lexer decoded.
 func charLiteral(canAssign bool) {
 	text := parser.previous.Start
 	if len(text) < 2 {
 		reportError("Invalid char literal; must be enclosed in quotes (e.g., 'a').")
 		return
 	}
-	str := text[1 : len(text)-1]
-	emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(str)})
+	emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(parser.previous.Literal)})
 }
 
 // makeConstant adds a constant value to the current chunk and returns its index.
@@ -423,12 +611,48 @@ func emitConstant(val runtime.Value) {
 
 // number compiles a numeric literal by parsing it and emitting the constant.
 func number(canAssign bool) {
-	val, err := strconv.ParseFloat(parser.previous.Start, 64)
-	if err != nil {
-		reportError(fmt.Sprintf("Invalid number literal '%s'; must be a valid number.", parser.previous.Start))
+	val, ok := numberValue()
+	if !ok {
 		return
 	}
-	emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: val})
+	emitConstant(val)
+}
+
+// numberValue converts the number token just consumed to its value, reporting an invalid literal.
+// A literal without a fractional part is an integer, unless it is too large for 64 bits and so a
+// float, and one ending in 'n' a BigInt. Hexadecimal, octal and binary literals
+// ('0xFF', '0o17', '0b1010') may use all 64 bits, so '0xFFFFFFFFFFFFFFFF' is -1.
+func numberValue() (runtime.Value, bool) {
+	text := parser.previous.Start
+	if digits, isBigInt := strings.CutSuffix(text, "n"); isBigInt {
+		value, ok := new(big.Int).SetString(digits, 0)
+		if !ok || strings.Contains(digits, ".") {
+			reportError(fmt.Sprintf("Invalid BigInt literal '%s'; must be a whole number followed by 'n'.", text))
+			return runtime.Value{Type: runtime.VAL_NULL}, false
+		}
+		return runtime.ObjVal(runtime.NewBigInt(value)), true
+	}
+	if len(text) > 1 && text[0] == '0' && strings.ContainsRune("xXoObB", rune(text[1])) {
+		bits, err := strconv.ParseUint(text, 0, 64)
+		if err != nil {
+			reportError(fmt.Sprintf("Invalid number literal '%s'; must be a valid number.", text))
+			return runtime.Value{Type: runtime.VAL_NULL}, false
+		}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(bits)}, true
+	}
+	if !strings.Contains(text, ".") {
+		// A literal too large for an integer falls through to a float, as an integer result too
+		// large for 64 bits does.
+		if val, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return runtime.Value{Type: runtime.VAL_INT, Int: val}, true
+		}
+	}
+	val, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		reportError(fmt.Sprintf("Invalid number literal '%s'; must be a valid number.", text))
+		return runtime.Value{Type: runtime.VAL_NULL}, false
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: val}, true
 }
 
 // unary compiles a unary operator expression (handles prefix ++x and --x).
@@ -441,6 +665,8 @@ func unary(canAssign bool) {
 		emitByte(byte(runtime.OP_NEGATE))
 	case token.TOKEN_BANG:
 		emitByte(byte(runtime.OP_NOT))
+	case token.TOKEN_TILDE:
+		emitByte(byte(runtime.OP_BIT_NOT))
 	case token.TOKEN_PLUS_PLUS:
 		// Ensure the operand is a variable (identifier)
 		if parser.previous.Type != token.TOKEN_IDENTIFIER {
@@ -449,28 +675,15 @@ func unary(canAssign bool) {
 		}
 
 		name := parser.previous
-		var getOp, setOp uint8
-		var arg int
-		if localArg := resolveLocal(current, name); localArg != -1 {
-			arg = localArg
-			getOp = byte(runtime.OP_GET_LOCAL)
-			setOp = byte(runtime.OP_SET_LOCAL)
-		} else if upvalueArg := resolveUpvalue(current, name); upvalueArg != -1 {
-			arg = upvalueArg
-			getOp = byte(runtime.OP_GET_UPVALUE)
-			setOp = byte(runtime.OP_SET_UPVALUE)
-		} else {
-			arg = int(identifierConstant(name))
-			getOp = byte(runtime.OP_GET_GLOBAL)
-			setOp = byte(runtime.OP_SET_GLOBAL)
-		}
+		getOp, setOp, arg, isConst := resolveVariable(name)
+		checkAssignable(name, isConst)
 
 		// Prefix ++x: Load, increment, store, leave new value on stack
-		emitByte(byte(runtime.OP_POP))                                   // Remove old value from stack
-		emitBytes(getOp, uint8(arg))                                     // Load variable value
-		emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: 1}) // Push 1
-		emitByte(byte(runtime.OP_ADD))                                   // Increment
-		emitBytes(setOp, uint8(arg))                                     // Store back to variable
+		emitByte(byte(runtime.OP_POP))                             // Remove old value from stack
+		emitBytes(getOp, uint8(arg))                               // Load variable value
+		emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: 1}) // Push 1
+		emitByte(byte(runtime.OP_ADD))                             // Increment
+		emitBytes(setOp, uint8(arg))                               // Store back to variable
 	case token.TOKEN_MINUS_MINUS:
 		// Ensure the operand is a variable (identifier)
 		if parser.previous.Type != token.TOKEN_IDENTIFIER {
@@ -479,28 +692,15 @@ func unary(canAssign bool) {
 		}
 
 		name := parser.previous
-		var getOp, setOp uint8
-		var arg int
-		if localArg := resolveLocal(current, name); localArg != -1 {
-			arg = localArg
-			getOp = byte(runtime.OP_GET_LOCAL)
-			setOp = byte(runtime.OP_SET_LOCAL)
-		} else if upvalueArg := resolveUpvalue(current, name); upvalueArg != -1 {
-			arg = upvalueArg
-			getOp = byte(runtime.OP_GET_UPVALUE)
-			setOp = byte(runtime.OP_SET_UPVALUE)
-		} else {
-			arg = int(identifierConstant(name))
-			getOp = byte(runtime.OP_GET_GLOBAL)
-			setOp = byte(runtime.OP_SET_GLOBAL)
-		}
+		getOp, setOp, arg, isConst := resolveVariable(name)
+		checkAssignable(name, isConst)
 
 		// Prefix --x: Load, decrement, store, leave new value on stack
-		emitByte(byte(runtime.OP_POP))                                   // Remove old value from stack
-		emitBytes(getOp, uint8(arg))                                     // Load variable value
-		emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: 1}) // Push 1
-		emitByte(byte(runtime.OP_SUBTRACT))                              // Decrement
-		emitBytes(setOp, uint8(arg))                                     // Store back to variable
+		emitByte(byte(runtime.OP_POP))                             // Remove old value from stack
+		emitBytes(getOp, uint8(arg))                               // Load variable value
+		emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: 1}) // Push 1
+		emitByte(byte(runtime.OP_SUBTRACT))                        // Decrement
+		emitBytes(setOp, uint8(arg))                               // Store back to variable
 	}
 }
 
@@ -526,6 +726,16 @@ func binary(canAssign bool) {
 		emitByte(byte(runtime.OP_FLOOR))
 	case token.TOKEN_PERCENT_PERCENT:
 		emitByte(byte(runtime.OP_PERCENT))
+	case token.TOKEN_AMPERSAND:
+		emitByte(byte(runtime.OP_BIT_AND))
+	case token.TOKEN_PIPE:
+		emitByte(byte(runtime.OP_BIT_OR))
+	case token.TOKEN_CARET:
+		emitByte(byte(runtime.OP_BIT_XOR))
+	case token.TOKEN_LESS_LESS:
+		emitByte(byte(runtime.OP_SHIFT_LEFT))
+	case token.TOKEN_GREATER_GREATER:
+		emitByte(byte(runtime.OP_SHIFT_RIGHT))
 	case token.TOKEN_BANG_EQUAL:
 		emitBytes(byte(runtime.OP_EQUAL), byte(runtime.OP_NOT))
 	case token.TOKEN_EQUAL_EQUAL:
@@ -538,6 +748,12 @@ func binary(canAssign bool) {
 		emitByte(byte(runtime.OP_LESS))
 	case token.TOKEN_LESS_EQUAL:
 		emitBytes(byte(runtime.OP_GREATER), byte(runtime.OP_NOT))
+	case token.TOKEN_IN:
+		emitByte(byte(runtime.OP_IN))
+	case token.TOKEN_DOT_DOT:
+		emitBytes(byte(runtime.OP_RANGE), 0)
+	case token.TOKEN_DOT_DOT_EQUAL:
+		emitBytes(byte(runtime.OP_RANGE), 1)
 	}
 }
 
@@ -573,7 +789,7 @@ func resolveLocal(comp *Compiler, name token.Token) int {
 }
 
 // addUpvalue adds an upvalue to the compiler's list, avoiding duplicates.
-func addUpvalue(compiler *Compiler, index uint8, isLocal bool) int {
+func addUpvalue(compiler *Compiler, index uint8, isLocal bool, isConst bool) int {
 	upvalueCount := compiler.function.UpvalueCount
 	for i := 0; i < upvalueCount; i++ {
 		upvalue := compiler.upvalues[i]
@@ -585,7 +801,7 @@ func addUpvalue(compiler *Compiler, index uint8, isLocal bool) int {
 		reportError("Too many upvalues in this function (max 256).")
 		return 0
 	}
-	compiler.upvalues[upvalueCount] = Upvalue{index: index, isLocal: isLocal}
+	compiler.upvalues[upvalueCount] = Upvalue{index: index, isLocal: isLocal, isConst: isConst}
 	compiler.function.UpvalueCount++
 	return upvalueCount
 }
@@ -598,51 +814,72 @@ func resolveUpvalue(compiler *Compiler, name token.Token) int {
 	local := resolveLocal(compiler.enclosing, name)
 	if local != -1 {
 		compiler.enclosing.locals[local].isCaptured = true
-		return addUpvalue(compiler, uint8(local), true)
+		return addUpvalue(compiler, uint8(local), true, compiler.enclosing.locals[local].isConst)
 	}
 	upvalue := resolveUpvalue(compiler.enclosing, name)
 	if upvalue != -1 {
-		return addUpvalue(compiler, uint8(upvalue), false)
+		return addUpvalue(compiler, uint8(upvalue), false, compiler.enclosing.upvalues[upvalue].isConst)
 	}
 	return -1
 }
 
-// namedVariable compiles a variable access or assignment, handling locals, upvalues, globals, or postfix operators (x++ and x--).
-func namedVariable(name token.Token, canAssign bool) {
-	var getOp, setOp uint8
-	var arg int
-	if localArg := resolveLocal(current, name); localArg != -1 {
-		arg = localArg
-		getOp = byte(runtime.OP_GET_LOCAL)
-		setOp = byte(runtime.OP_SET_LOCAL)
-	} else if upvalueArg := resolveUpvalue(current, name); upvalueArg != -1 {
-		arg = upvalueArg
-		getOp = byte(runtime.OP_GET_UPVALUE)
-		setOp = byte(runtime.OP_SET_UPVALUE)
-	} else {
-		arg = int(identifierConstant(name))
-		getOp = byte(runtime.OP_GET_GLOBAL)
-		setOp = byte(runtime.OP_SET_GLOBAL)
+// compoundAssignments maps the compound assignment operators to the operation they apply.
+var compoundAssignments = map[token.TokenType]runtime.OpCode{
+	token.TOKEN_PLUS_EQUAL:            runtime.OP_ADD,
+	token.TOKEN_MINUS_EQUAL:           runtime.OP_SUBTRACT,
+	token.TOKEN_STAR_EQUAL:            runtime.OP_MULTIPLY,
+	token.TOKEN_SLASH_EQUAL:           runtime.OP_DIVIDE,
+	token.TOKEN_PERCENT_EQUAL:         runtime.OP_MOD,
+	token.TOKEN_STAR_STAR_EQUAL:       runtime.OP_EXPONENTIAL,
+	token.TOKEN_AMPERSAND_EQUAL:       runtime.OP_BIT_AND,
+	token.TOKEN_PIPE_EQUAL:            runtime.OP_BIT_OR,
+	token.TOKEN_CARET_EQUAL:           runtime.OP_BIT_XOR,
+	token.TOKEN_LESS_LESS_EQUAL:       runtime.OP_SHIFT_LEFT,
+	token.TOKEN_GREATER_GREATER_EQUAL: runtime.OP_SHIFT_RIGHT,
+}
+
+// matchCompoundAssignment consumes a compound assignment operator such as '+=' where an assignment
+// is allowed, returning the operation it applies. Targets compile 'x op= value' as reading x once,
+// applying the operation with value and storing the result, which is the expression's value.
+func matchCompoundAssignment(canAssign bool) (runtime.OpCode, bool) {
+	op, ok := compoundAssignments[parser.current.Type]
+	if !canAssign || !ok {
+		return 0, false
 	}
+	advance()
+	return op, true
+}
 
+// namedVariable compiles a variable access or assignment, handling locals, upvalues, globals, or postfix operators (x++ and x--).
+func namedVariable(name token.Token, canAssign bool) {
+	getOp, setOp, arg, isConst := resolveVariable(name)
 	if canAssign && match(token.TOKEN_EQUAL) {
+		checkAssignable(name, isConst)
+		expression()
+		emitBytes(setOp, uint8(arg))
+	} else if op, ok := matchCompoundAssignment(canAssign); ok {
+		checkAssignable(name, isConst)
+		emitBytes(getOp, uint8(arg))
 		expression()
+		emitByte(byte(op))
 		emitBytes(setOp, uint8(arg))
 	} else if match(token.TOKEN_PLUS_PLUS) {
 		// Postfix increment (x++): Load the variable, duplicate it, increment by 1, store back, and pop
 		// the incremented value, leaving the original value on the stack.
+		checkAssignable(name, isConst)
 		emitBytes(getOp, uint8(arg))
 		emitByte(byte(runtime.OP_DUP))
-		emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: 1})
+		emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: 1})
 		emitByte(byte(runtime.OP_ADD))
 		emitBytes(setOp, uint8(arg))
 		emitByte(byte(runtime.OP_POP))
 	} else if match(token.TOKEN_MINUS_MINUS) {
 		// Postfix decrement (x--): Load the variable, duplicate it, decrement by 1, store back, and pop
 		// the decremented value, leaving the original value on the stack.
+		checkAssignable(name, isConst)
 		emitBytes(getOp, uint8(arg))
 		emitByte(byte(runtime.OP_DUP))
-		emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: 1})
+		emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: 1})
 		emitByte(byte(runtime.OP_SUBTRACT))
 		emitBytes(setOp, uint8(arg))
 		emitByte(byte(runtime.OP_POP))
@@ -651,24 +888,76 @@ func namedVariable(name token.Token, canAssign bool) {
 	}
 }
 
+// resolveVariable resolves a variable name to a local, an upvalue or a global, returning the
+// opcodes that read and write it, their operand and whether it was declared with 'const'.
+func resolveVariable(name token.Token) (getOp uint8, setOp uint8, arg int, isConst bool) {
+	if localArg := resolveLocal(current, name); localArg != -1 {
+		return byte(runtime.OP_GET_LOCAL), byte(runtime.OP_SET_LOCAL), localArg, current.locals[localArg].isConst
+	}
+	if upvalueArg := resolveUpvalue(current, name); upvalueArg != -1 {
+		return byte(runtime.OP_GET_UPVALUE), byte(runtime.OP_SET_UPVALUE), upvalueArg, current.upvalues[upvalueArg].isConst
+	}
+	return byte(runtime.OP_GET_GLOBAL), byte(runtime.OP_SET_GLOBAL), int(identifierConstant(name)), constGlobals[name.Start]
+}
+
+// checkAssignable reports an error when a variable about to be assigned, updated, incremented or
+// decremented was declared with 'const'. Globals declared in other scripts are checked at runtime.
+func checkAssignable(name token.Token, isConst bool) {
+	if isConst {
+		errorAt(name, fmt.Sprintf("Cannot assign to constant '%s'.", name.Start))
+	}
+}
+
 // variable is the entry point for parsing a variable expression.
 func variable(canAssign bool) {
 	namedVariable(parser.previous, canAssign)
 }
 
+// thisExpression compiles 'this', the instance a method was called on.
+func thisExpression(canAssign bool) {
+	if currentStruct == nil {
+		reportError("Cannot use 'this' outside of a struct method.")
+		return
+	}
+	namedVariable(parser.previous, false)
+}
+
+// superExpression compiles 'super.name' and 'super.name(args)', which look the method up in the
+// parent of the struct declaring the current method and bind it to 'this'.
+func superExpression(canAssign bool) {
+	if currentStruct == nil {
+		reportError("Cannot use 'super' outside of a struct method.")
+	} else if !currentStruct.hasParent {
+		reportError("Cannot use 'super' in a struct without a parent.")
+	}
+	consume(token.TOKEN_DOT, "Expected '.' after 'super'.")
+	consume(token.TOKEN_IDENTIFIER, "Expected a parent method name after 'super.'.")
+	name := identifierConstant(parser.previous)
+	namedVariable(token.Token{Start: "this", Length: 4, Line: parser.previous.Line}, false)
+	if match(token.TOKEN_LEFT_PAREN) {
+		argCount := argumentList()
+		emitBytes(byte(runtime.OP_SUPER_INVOKE), name)
+		emitByte(argCount)
+	} else {
+		emitBytes(byte(runtime.OP_GET_SUPER), name)
+	}
+}
+
 // getRule retrieves the parsing rule for a given token type.
 func getRule(typ token.TokenType) ParseRule {
 	return rules[typ]
 }
 
 // initCompiler initializes a new compiler for a function or script and sets up the first local variable.
-func initCompiler(compiler *Compiler, funcType FunctionType, scriptDir string) {
+func initCompiler(compiler *Compiler, funcType FunctionType, scriptPath string) {
 	compiler.enclosing = current
 	compiler.function = runtime.NewFunction()
 	compiler.functionType = funcType
 	compiler.localCount = 0
 	compiler.scopeDepth = 0
-	compiler.scriptDir = scriptDir
+	compiler.scriptPath = scriptPath
+	compiler.function.File = runtime.CopyString(scriptPath)
+	compiler.function.Module = compilingModule
 	current = compiler
 	if funcType != TYPE_SCRIPT {
 		current.function.Name = runtime.CopyString(parser.previous.Start)
@@ -677,19 +966,37 @@ func initCompiler(compiler *Compiler, funcType FunctionType, scriptDir string) {
 	local := &current.locals[current.localCount-1]
 	local.depth = 0
 	local.isCaptured = false
-	local.name.Start = ""
-	local.name.Length = 0
+	// A method receives its instance in slot 0, where 'this' resolves to it.
+	if funcType == TYPE_METHOD || funcType == TYPE_INITIALIZER {
+		local.name.Start = "this"
+		local.name.Length = 4
+	} else {
+		local.name.Start = ""
+		local.name.Length = 0
+	}
 }
 
 // Compile is the entry point for compiling source code into a function object.
 // It initializes the lexer, sets up the compiler state, and processes all declarations.
 func Compile(source string, scriptPath string) *runtime.ObjFunction {
+	return CompileModule(source, scriptPath, nil)
+}
+
+// CompileModule compiles a file imported as module. Its functions define and read globals among
+// the fields of module rather than the globals of the main script, the names it exports are added
+// to module.Exports, and running it returns module once it has checked that they are all defined.
+func CompileModule(source string, scriptPath string, module *runtime.ObjModule) *runtime.ObjFunction {
 	lexer.InitLexer(source)
+	compilingModule = module
+	exporting = false
 	var compiler Compiler
-	scriptDir := filepath.Dir(scriptPath)
-	initCompiler(&compiler, TYPE_SCRIPT, scriptDir) // Top-level: no module path
+	initCompiler(&compiler, TYPE_SCRIPT, scriptPath) // Top-level: no module path
+	currentStruct = nil
+	constGlobals = make(map[string]bool)
 	parser.hadError = false
 	parser.panicMode = false
+	parser.nesting = 0
+	parser.armNesting = -1
 	advance()
 	for !match(token.TOKEN_EOF) {
 		declaration()
@@ -743,6 +1050,49 @@ func or(canAssign bool) {
 	patchJump(endJump)
 }
 
+// conditional compiles 'cond ? a : b', evaluating only the branch the condition selects. The
+// operator groups to the right, so 'a ? b : c ? d : e' chains conditions.
+func conditional(canAssign bool) {
+	elseJump := emitJump(byte(runtime.OP_JUMP_IF_FALSE))
+	emitByte(byte(runtime.OP_POP))
+	parsePrecedence(PREC_CONDITIONAL)
+	consume(token.TOKEN_COLON, "Expected ':' after the first branch of a conditional expression.")
+	endJump := emitJump(byte(runtime.OP_JUMP))
+	patchJump(elseJump)
+	emitByte(byte(runtime.OP_POP))
+	parsePrecedence(PREC_CONDITIONAL)
+	patchJump(endJump)
+}
+
+// coalesce compiles 'a ?? b', which is a unless a is null, and only evaluates b when it is.
+func coalesce(canAssign bool) {
+	elseJump := emitJump(byte(runtime.OP_JUMP_IF_NULL))
+	endJump := emitJump(byte(runtime.OP_JUMP))
+	patchJump(elseJump)
+	emitByte(byte(runtime.OP_POP))
+	parsePrecedence(PREC_COALESCE + 1)
+	patchJump(endJump)
+}
+
+// optionalChain compiles 'value?.name' and 'value?[index]'. When the value is null, the rest of
+// the chain of property accesses, calls and subscripts after it is skipped and the whole chain
+// evaluates to null. An optional chain cannot be assigned to.
+func optionalChain(canAssign bool) {
+	operator := parser.previous.Type
+	skipJump := emitJump(byte(runtime.OP_JUMP_IF_NULL))
+	if operator == token.TOKEN_QUESTION_DOT {
+		dot(false)
+	} else {
+		subscript(false)
+	}
+	for continuesExpression(PREC_CALL) {
+		advance()
+		getRule(parser.previous.Type).Infix(false)
+	}
+	patchJump(skipJump)
+	parser.afterOptionalSubscript = operator == token.TOKEN_QUESTION_BRACKET && parser.previous.Type == token.TOKEN_RIGHT_BRACKET
+}
+
 // emitLoop writes a loop instruction that jumps back to the beginning of the loop.
 func emitLoop(loopStart int) {
 	emitByte(byte(runtime.OP_LOOP))
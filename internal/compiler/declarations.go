@@ -2,8 +2,6 @@ package compiler
 
 import (
 	"fmt"
-	"path/filepath"
-	"strconv"
 
 	"github.com/cryptrunner49/zscript/internal/runtime"
 	"github.com/cryptrunner49/zscript/internal/token"
@@ -13,22 +11,36 @@ import (
 func declareVariable() {
 	// Skip variable declaration for global scope, as globals are defined with defineVariable.
 	if current.scopeDepth == 0 {
+		checkConstRedeclaration(parser.previous)
 		return
 	}
 
-	name := parser.previous
+	declareLocal(parser.previous)
+}
+
+// declareLocal adds a local variable with the given name to the current scope, reporting an error
+// when the scope already declares one.
+func declareLocal(name token.Token) {
 	for i := current.localCount - 1; i >= 0; i-- {
 		local := current.locals[i]
 		if local.depth != -1 && local.depth < current.scopeDepth {
 			break
 		}
 		if identifiersEqual(name, local.name) {
-			reportError(fmt.Sprintf("Variable '%s' is already declared in this scope.", name.Start))
+			errorAt(name, fmt.Sprintf("Variable '%s' is already declared in this scope.", name.Start))
 		}
 	}
 	addLocal(name)
 }
 
+// checkConstRedeclaration reports an error when a global is declared with the name of a constant
+// declared earlier in the script. Constants declared in other scripts are checked at runtime.
+func checkConstRedeclaration(name token.Token) {
+	if constGlobals[name.Start] {
+		errorAt(name, fmt.Sprintf("Cannot redeclare constant '%s'.", name.Start))
+	}
+}
+
 func fnDeclaration() {
 	global := parseVariable("Expected a function name after 'fn' (e.g., 'fn myFunc()').")
 	markInitialized()
@@ -37,6 +49,10 @@ func fnDeclaration() {
 }
 
 func varDeclaration() {
+	if isDestructuringTarget() {
+		destructuringDeclaration(false)
+		return
+	}
 	global := parseVariable("Expected a variable name after 'var' (e.g., 'var x').")
 	if match(token.TOKEN_EQUAL) {
 		expression()
@@ -47,16 +63,48 @@ func varDeclaration() {
 	defineVariable(global)
 }
 
+// constDeclaration compiles 'const NAME = value'. The constant must be given a value and cannot be
+// assigned, updated, incremented or decremented afterwards.
+func constDeclaration() {
+	if isDestructuringTarget() {
+		destructuringDeclaration(true)
+		return
+	}
+	global := parseVariable("Expected a constant name after 'const' (e.g., 'const LIMIT').")
+	name := parser.previous
+	consume(token.TOKEN_EQUAL, "Expected '=' after constant name; constants need a value.")
+	expression()
+	consumeOptionalSemicolon()
+	defineConstant(global, name)
+}
+
 func structDeclaration() {
 	consume(token.TOKEN_IDENTIFIER, "Expected a struct name after 'struct' (e.g., 'struct Point').")
-	nameConstant := identifierConstant(parser.previous)
+	structName := parser.previous
+	nameConstant := identifierConstant(structName)
 	declareVariable()
 
+	// 'struct Name < Parent' inherits the parent's field defaults and methods.
+	var parentName token.Token
+	hasParent := match(token.TOKEN_LESS)
+	if hasParent {
+		consume(token.TOKEN_IDENTIFIER, "Expected a parent struct name after '<' (e.g., 'struct Enemy < Entity').")
+		parentName = parser.previous
+		if identifiersEqual(structName, parentName) {
+			reportError("A struct cannot inherit from itself.")
+		}
+	}
+
 	// If no ':' follows, it's an empty struct
 	if !match(token.TOKEN_COLON) {
 		consumeOptionalSemicolon()
 		emitBytes(byte(runtime.OP_STRUCT), nameConstant)
 		emitByte(0) // No fields
+		emitByte(0) // No initializer
+		emitByte(0) // No methods
+		if hasParent {
+			inheritFrom(parentName)
+		}
 		defineVariable(nameConstant)
 		return
 	}
@@ -66,144 +114,158 @@ func structDeclaration() {
 		return
 	}
 
-	fieldCount := 0
 	fieldNames := make([]*runtime.ObjString, 0)
-	fieldDefaults := make([]runtime.Value, 0)
+	methodNames := make([]uint8, 0)
+	structCompiler := StructCompiler{enclosing: currentStruct, hasParent: hasParent}
+	currentStruct = &structCompiler
+
+	// Field defaults are compiled into a hidden initializer method, so each instance evaluates
+	// them afresh. Its compiler is only current while a default is being compiled; methods are
+	// compiled between defaults as functions of the enclosing compiler.
+	var initializer Compiler
+	initCompiler(&initializer, TYPE_INITIALIZER, current.scriptPath)
+	initializer.function.Name = runtime.CopyString(structName.Start)
+	current = initializer.enclosing
+	hasDefaults := false
 
 	for !check(token.TOKEN_DEDENT) && !check(token.TOKEN_EOF) {
-		consume(token.TOKEN_IDENTIFIER, "Expected a field name in struct (e.g., 'x' in 'x = 0').")
-		fieldName := runtime.NewObjString(parser.previous.Start)
-		fieldNames = append(fieldNames, fieldName)
+		// Methods are compiled into closures left on the stack for OP_STRUCT to collect.
+		if match(token.TOKEN_FUNC) {
+			consume(token.TOKEN_IDENTIFIER, "Expected a method name after 'func'.")
+			methodNames = append(methodNames, identifierConstant(parser.previous))
+			function(TYPE_METHOD)
+			continue
+		}
+		if !check(token.TOKEN_IDENTIFIER) {
+			// Skip the offending token so a malformed member cannot stall the loop.
+			errorAtCurrent("Expected a field name in struct (e.g., 'x' in 'x = 0').")
+			advance()
+			continue
+		}
+		advance()
+		fieldName := parser.previous
+		fieldNames = append(fieldNames, runtime.NewObjString(fieldName.Start))
 
-		var defaultValue runtime.Value
 		if match(token.TOKEN_EQUAL) {
-			if match(token.TOKEN_NUMBER) {
-				val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-				defaultValue = runtime.Value{Type: runtime.VAL_NUMBER, Number: val}
-			} else if match(token.TOKEN_STRING) {
-				text := parser.previous.Start
-				str := text[1 : len(text)-1]
-				defaultValue = runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(str)}
-			} else if match(token.TOKEN_TRUE) {
-				defaultValue = runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
-			} else if match(token.TOKEN_FALSE) {
-				defaultValue = runtime.Value{Type: runtime.VAL_BOOL, Bool: false}
-			} else if match(token.TOKEN_NULL) {
-				defaultValue = runtime.Value{Type: runtime.VAL_NULL}
-			} else if match(token.TOKEN_LEFT_BRACKET) {
-				// Parse array literal and collect elements
-				elements := make([]runtime.Value, 0)
-				if !check(token.TOKEN_RIGHT_BRACKET) {
-					for {
-						if match(token.TOKEN_NUMBER) {
-							val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-							elements = append(elements, runtime.Value{Type: runtime.VAL_NUMBER, Number: val})
-							emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: val})
-						} else if match(token.TOKEN_STRING) {
-							text := parser.previous.Start
-							str := text[1 : len(text)-1]
-							objStr := runtime.NewObjString(str)
-							elements = append(elements, runtime.Value{Type: runtime.VAL_OBJ, Obj: objStr})
-							emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: objStr})
-						} else if match(token.TOKEN_TRUE) {
-							elements = append(elements, runtime.Value{Type: runtime.VAL_BOOL, Bool: true})
-							emitConstant(runtime.Value{Type: runtime.VAL_BOOL, Bool: true})
-						} else if match(token.TOKEN_FALSE) {
-							elements = append(elements, runtime.Value{Type: runtime.VAL_BOOL, Bool: false})
-							emitConstant(runtime.Value{Type: runtime.VAL_BOOL, Bool: false})
-						} else if match(token.TOKEN_NULL) {
-							elements = append(elements, runtime.Value{Type: runtime.VAL_NULL})
-							emitConstant(runtime.Value{Type: runtime.VAL_NULL})
-						} else {
-							reportError("Array elements must be literals (number, string, true, false, null).")
-							elements = append(elements, runtime.Value{Type: runtime.VAL_NULL})
-							expression() // Consume invalid expression
-						}
-						if !match(token.TOKEN_COMMA) {
-							break
-						}
-					}
-				}
-				consume(token.TOKEN_RIGHT_BRACKET, "Expected ']' after array elements.")
-				// Create ObjArray and emit OP_ARRAY
-				objArray := runtime.NewArray(elements)
-				defaultValue = runtime.Value{Type: runtime.VAL_OBJ, Obj: objArray}
-				emitBytes(byte(runtime.OP_ARRAY), byte(len(elements)))
-			} else if match(token.TOKEN_LEFT_BRACE) {
-				// Parse map literal and collect key-value pairs
-				pairs := make(map[*runtime.ObjString]runtime.Value)
-				for !check(token.TOKEN_RIGHT_BRACE) && !check(token.TOKEN_EOF) {
-					var key *runtime.ObjString
-					if match(token.TOKEN_STRING) {
-						key = runtime.NewObjString(parser.previous.Start[1 : len(parser.previous.Start)-1])
-						emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: key})
-					} else if match(token.TOKEN_IDENTIFIER) {
-						key = runtime.NewObjString(parser.previous.Start)
-						emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: key})
-					} else {
-						reportError("Map key must be a string or identifier.")
-						break
-					}
-					consume(token.TOKEN_COLON, "Expected ':' after map key.")
-					var value runtime.Value
-					if match(token.TOKEN_NUMBER) {
-						val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-						value = runtime.Value{Type: runtime.VAL_NUMBER, Number: val}
-						emitConstant(value)
-					} else if match(token.TOKEN_STRING) {
-						text := parser.previous.Start
-						str := text[1 : len(text)-1]
-						objStr := runtime.NewObjString(str)
-						value = runtime.Value{Type: runtime.VAL_OBJ, Obj: objStr}
-						emitConstant(value)
-					} else if match(token.TOKEN_TRUE) {
-						value = runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
-						emitConstant(value)
-					} else if match(token.TOKEN_FALSE) {
-						value = runtime.Value{Type: runtime.VAL_BOOL, Bool: false}
-						emitConstant(value)
-					} else if match(token.TOKEN_NULL) {
-						value = runtime.Value{Type: runtime.VAL_NULL}
-						emitConstant(value)
-					} else {
-						reportError("Map values must be literals (number, string, true, false, null).")
-						value = runtime.Value{Type: runtime.VAL_NULL}
-						expression() // Consume invalid expression
-					}
-					pairs[key] = value
+			hasDefaults = true
+			current = &initializer
+			emitBytes(byte(runtime.OP_GET_LOCAL), 0)
+			expression()
+			emitBytes(byte(runtime.OP_SET_PROPERTY), identifierConstant(fieldName))
+			emitByte(byte(runtime.OP_POP))
+			current = initializer.enclosing
+		}
+
+		consumeOptionalSemicolon()
+	}
+
+	consume(token.TOKEN_DEDENT, "Expected dedent after struct block.")
+	if hasDefaults {
+		current = &initializer
+		emitClosure(endCompiler(), &initializer)
+	}
+	emitBytes(byte(runtime.OP_STRUCT), nameConstant)
+	if len(fieldNames) > 255 {
+		reportError("Too many fields in one struct (max 255).")
+	}
+	emitByte(byte(len(fieldNames)))
+	for _, fieldName := range fieldNames {
+		emitByte(makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: fieldName}))
+	}
+	if hasDefaults {
+		emitByte(1)
+	} else {
+		emitByte(0)
+	}
+	if len(methodNames) > 255 {
+		reportError("Too many methods in one struct (max 255).")
+	}
+	emitByte(byte(len(methodNames)))
+	for _, methodName := range methodNames {
+		emitByte(methodName)
+	}
+	if hasParent {
+		inheritFrom(parentName)
+	}
+	currentStruct = currentStruct.enclosing
+
+	defineVariable(nameConstant)
+}
+
+// inheritFrom makes the struct on top of the stack a child of the named struct. The parent's
+// field defaults and methods are copied into it unless it declares its own.
+func inheritFrom(parentName token.Token) {
+	namedVariable(parentName, false)
+	emitByte(byte(runtime.OP_INHERIT))
+}
+
+// enumDeclaration compiles 'enum Name' followed by its variants, either braced and comma-separated
+// ('enum Color { Red, Green }') or as an indented block with one variant per line. A variant may
+// declare payload fields in parentheses, as in 'Circle(radius)'.
+func enumDeclaration() {
+	consume(token.TOKEN_IDENTIFIER, "Expected an enum name after 'enum' (e.g., 'enum Color').")
+	nameConstant := identifierConstant(parser.previous)
+	declareVariable()
+
+	closing := token.TOKEN_DEDENT
+	if match(token.TOKEN_LEFT_BRACE) {
+		closing = token.TOKEN_RIGHT_BRACE
+	} else {
+		consume(token.TOKEN_COLON, "Expected ':' or '{' after enum name.")
+		consume(token.TOKEN_INDENT, "Expected indented block after ':' (in enum declaration).")
+	}
+
+	variants := make([]token.Token, 0)
+	payloads := make([][]token.Token, 0)
+	for !check(closing) && !check(token.TOKEN_EOF) {
+		consume(token.TOKEN_IDENTIFIER, "Expected a variant name in enum (e.g., 'Red').")
+		variant := parser.previous
+		for _, declared := range variants {
+			if identifiersEqual(declared, variant) {
+				reportError(fmt.Sprintf("Variant '%s' is already declared in this enum.", variant.Start))
+			}
+		}
+
+		fields := make([]token.Token, 0)
+		if match(token.TOKEN_LEFT_PAREN) {
+			if !check(token.TOKEN_RIGHT_PAREN) {
+				for {
+					consume(token.TOKEN_IDENTIFIER, "Expected a payload field name (e.g., 'radius' in 'Circle(radius)').")
+					fields = append(fields, parser.previous)
 					if !match(token.TOKEN_COMMA) {
 						break
 					}
 				}
-				consume(token.TOKEN_RIGHT_BRACE, "Expected '}' after map literal.")
-				// Create ObjMap and emit OP_MAP
-				objMap := runtime.NewMap()
-				for k, v := range pairs {
-					objMap.Entries[k] = v
-				}
-				defaultValue = runtime.Value{Type: runtime.VAL_OBJ, Obj: objMap}
-				emitBytes(byte(runtime.OP_MAP), byte(len(pairs)))
-			} else {
-				reportError("Expected a literal value (number, string, true, false, null, array, or map) for field default.")
-				defaultValue = runtime.Value{Type: runtime.VAL_NULL}
 			}
-		} else {
-			defaultValue = runtime.Value{Type: runtime.VAL_NULL}
+			consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after variant payload fields.")
 		}
-		fieldDefaults = append(fieldDefaults, defaultValue)
-		fieldCount++
+		if len(fields) > 255 {
+			reportError("Enum variant cannot have more than 255 payload fields.")
+		}
+		variants = append(variants, variant)
+		payloads = append(payloads, fields)
 
-		consumeOptionalSemicolon()
+		if !match(token.TOKEN_COMMA) {
+			consumeOptionalSemicolon()
+		}
+	}
+	if closing == token.TOKEN_RIGHT_BRACE {
+		consume(token.TOKEN_RIGHT_BRACE, "Expected '}' after enum variants.")
+	} else {
+		consume(token.TOKEN_DEDENT, "Expected dedent after enum block.")
+	}
+	if len(variants) > 255 {
+		reportError("Enum cannot have more than 255 variants.")
 	}
 
-	consume(token.TOKEN_DEDENT, "Expected dedent after struct block.")
-	emitBytes(byte(runtime.OP_STRUCT), nameConstant)
-	emitByte(byte(fieldCount))
-	for i := 0; i < fieldCount; i++ {
-		nameConst := makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: fieldNames[i]})
-		defaultConst := makeConstant(fieldDefaults[i])
-		emitByte(nameConst)
-		emitByte(defaultConst)
+	emitBytes(byte(runtime.OP_ENUM), nameConstant)
+	emitByte(byte(len(variants)))
+	for i, variant := range variants {
+		emitByte(identifierConstant(variant))
+		emitByte(byte(len(payloads[i])))
+		for _, field := range payloads[i] {
+			emitByte(identifierConstant(field))
+		}
 	}
 
 	defineVariable(nameConstant)
@@ -214,22 +276,10 @@ func compileModuleFunction() runtime.Value {
 
 	// Set up a new compiler instance for the module function, initializing it with the function type
 	// and script directory.
-	initCompiler(&fnCompiler, TYPE_FUNCTION, current.scriptDir)
+	initCompiler(&fnCompiler, TYPE_FUNCTION, current.scriptPath)
 	beginScope()
 	consume(token.TOKEN_LEFT_PAREN, "Expected '(' after function name to start parameter list.")
-	if !check(token.TOKEN_RIGHT_PAREN) {
-		for {
-			current.function.Arity++
-			if current.function.Arity > 255 {
-				errorAtCurrent("Function cannot have more than 255 parameters.")
-			}
-			paramConstant := parseVariable("Expected a parameter name (e.g., 'x' in 'fn foo(x)').")
-			defineVariable(paramConstant)
-			if !match(token.TOKEN_COMMA) {
-				break
-			}
-		}
-	}
+	parameterList()
 	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' to close parameter list.")
 	consume(token.TOKEN_COLON, "Expected ':' after function parameters.")
 	block()
@@ -268,20 +318,23 @@ func modDeclarationField() (*runtime.ObjString, runtime.Value) {
 	// Prepare slices for the nested module's fields.
 	nestedFieldNames := make([]*runtime.ObjString, 0)
 	nestedFieldDefaults := make([]runtime.Value, 0)
+	nestedConstants := make([]*runtime.ObjString, 0)
 
 	// Parse declarations inside the nested module body.
 	for !check(token.TOKEN_DEDENT) && !check(token.TOKEN_EOF) {
-		if match(token.TOKEN_VAR) {
+		if match(token.TOKEN_VAR) || match(token.TOKEN_CONST) {
+			isConst := parser.previous.Type == token.TOKEN_CONST
 			consume(token.TOKEN_IDENTIFIER, "Expected variable name in nested module.")
 			fName := runtime.NewObjString(parser.previous.Start)
 			var defVal runtime.Value
+			if isConst && !check(token.TOKEN_EQUAL) {
+				errorAtCurrent("Expected '=' after constant name; constants need a value.")
+			}
 			if match(token.TOKEN_EQUAL) {
 				if match(token.TOKEN_NUMBER) {
-					val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-					defVal = runtime.Value{Type: runtime.VAL_NUMBER, Number: val}
+					defVal, _ = numberValue()
 				} else if match(token.TOKEN_STRING) {
-					text := parser.previous.Start
-					str := text[1 : len(text)-1]
+					str := parser.previous.Literal
 					defVal = runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(str)}
 				} else if match(token.TOKEN_TRUE) {
 					defVal = runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
@@ -299,6 +352,9 @@ func modDeclarationField() (*runtime.ObjString, runtime.Value) {
 			consumeOptionalSemicolon()
 			nestedFieldNames = append(nestedFieldNames, fName)
 			nestedFieldDefaults = append(nestedFieldDefaults, defVal)
+			if isConst {
+				nestedConstants = append(nestedConstants, fName)
+			}
 		} else if match(token.TOKEN_FUNC) {
 			consume(token.TOKEN_IDENTIFIER, "Expected function name in nested module.")
 			fName := runtime.NewObjString(parser.previous.Start)
@@ -315,7 +371,7 @@ func modDeclarationField() (*runtime.ObjString, runtime.Value) {
 			nestedFieldNames = append(nestedFieldNames, nName)
 			nestedFieldDefaults = append(nestedFieldDefaults, nVal)
 		} else {
-			reportError("Expected 'var', 'fn', or 'mod' in nested module body.")
+			reportError("Expected 'var', 'const', 'fn', or 'mod' in nested module body.")
 			synchronize()
 		}
 	}
@@ -328,6 +384,9 @@ func modDeclarationField() (*runtime.ObjString, runtime.Value) {
 	for i := 0; i < len(nestedFieldNames); i++ {
 		objModule.Fields[nestedFieldNames[i]] = nestedFieldDefaults[i]
 	}
+	for _, name := range nestedConstants {
+		objModule.Constants[name] = true
+	}
 
 	//_ = makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: objModule})
 	// Return the nested module's name and its module value.
@@ -403,20 +462,23 @@ func modDeclaration() {
 	consume(token.TOKEN_INDENT, "Expected indented block after ':'.")
 	fieldNames := make([]*runtime.ObjString, 0)
 	fieldDefaults := make([]runtime.Value, 0)
+	fieldConstants := make([]bool, 0)
 
 	// Parse module body
 	for !check(token.TOKEN_DEDENT) && !check(token.TOKEN_EOF) {
-		if match(token.TOKEN_VAR) {
+		if match(token.TOKEN_VAR) || match(token.TOKEN_CONST) {
+			isConst := parser.previous.Type == token.TOKEN_CONST
 			consume(token.TOKEN_IDENTIFIER, "Expected variable name in module declaration.")
 			fName := runtime.NewObjString(parser.previous.Start)
 			var defVal runtime.Value
+			if isConst && !check(token.TOKEN_EQUAL) {
+				errorAtCurrent("Expected '=' after constant name; constants need a value.")
+			}
 			if match(token.TOKEN_EQUAL) {
 				if match(token.TOKEN_NUMBER) {
-					val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-					defVal = runtime.Value{Type: runtime.VAL_NUMBER, Number: val}
+					defVal, _ = numberValue()
 				} else if match(token.TOKEN_STRING) {
-					text := parser.previous.Start
-					str := text[1 : len(text)-1]
+					str := parser.previous.Literal
 					defVal = runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(str)}
 				} else if match(token.TOKEN_TRUE) {
 					defVal = runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
@@ -430,12 +492,11 @@ func modDeclaration() {
 					if !check(token.TOKEN_RIGHT_BRACKET) {
 						for {
 							if match(token.TOKEN_NUMBER) {
-								val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-								elements = append(elements, runtime.Value{Type: runtime.VAL_NUMBER, Number: val})
-								emitConstant(runtime.Value{Type: runtime.VAL_NUMBER, Number: val})
+								val, _ := numberValue()
+								elements = append(elements, val)
+								emitConstant(val)
 							} else if match(token.TOKEN_STRING) {
-								text := parser.previous.Start
-								str := text[1 : len(text)-1]
+								str := parser.previous.Literal
 								objStr := runtime.NewObjString(str)
 								elements = append(elements, runtime.Value{Type: runtime.VAL_OBJ, Obj: objStr})
 								emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: objStr})
@@ -469,7 +530,7 @@ func modDeclaration() {
 					for !check(token.TOKEN_RIGHT_BRACE) && !check(token.TOKEN_EOF) {
 						var key *runtime.ObjString
 						if match(token.TOKEN_STRING) {
-							key = runtime.NewObjString(parser.previous.Start[1 : len(parser.previous.Start)-1])
+							key = runtime.NewObjString(parser.previous.Literal)
 							emitConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: key})
 						} else if match(token.TOKEN_IDENTIFIER) {
 							key = runtime.NewObjString(parser.previous.Start)
@@ -481,12 +542,10 @@ func modDeclaration() {
 						consume(token.TOKEN_COLON, "Expected ':' after map key.")
 						var value runtime.Value
 						if match(token.TOKEN_NUMBER) {
-							val, _ := strconv.ParseFloat(parser.previous.Start, 64)
-							value = runtime.Value{Type: runtime.VAL_NUMBER, Number: val}
+							value, _ = numberValue()
 							emitConstant(value)
 						} else if match(token.TOKEN_STRING) {
-							text := parser.previous.Start
-							str := text[1 : len(text)-1]
+							str := parser.previous.Literal
 							objStr := runtime.NewObjString(str)
 							value = runtime.Value{Type: runtime.VAL_OBJ, Obj: objStr}
 							emitConstant(value)
@@ -527,6 +586,7 @@ func modDeclaration() {
 			consumeOptionalSemicolon()
 			fieldNames = append(fieldNames, fName)
 			fieldDefaults = append(fieldDefaults, defVal)
+			fieldConstants = append(fieldConstants, isConst)
 		} else if match(token.TOKEN_FUNC) {
 			consume(token.TOKEN_IDENTIFIER, "Expected function name in module declaration.")
 			fName := runtime.NewObjString(parser.previous.Start)
@@ -535,14 +595,16 @@ func modDeclaration() {
 			match(token.TOKEN_SEMICOLON)
 			fieldNames = append(fieldNames, fName)
 			fieldDefaults = append(fieldDefaults, fnCVal)
+			fieldConstants = append(fieldConstants, false)
 		} else if match(token.TOKEN_MOD) {
 			// Nested module
 			nestedName, nestedVal := modDeclarationField()
 			fieldNames = append(fieldNames, nestedName)
 			fieldDefaults = append(fieldDefaults, nestedVal)
+			fieldConstants = append(fieldConstants, false)
 			match(token.TOKEN_SEMICOLON)
 		} else {
-			reportError("Expected 'var', 'fn', or 'mod' declaration in module body.")
+			reportError("Expected 'var', 'const', 'fn', or 'mod' declaration in module body.")
 			synchronize()
 		}
 	}
@@ -556,21 +618,43 @@ func modDeclaration() {
 		defConst := makeConstant(fieldDefaults[i])
 		emitByte(nameConst)
 		emitByte(defConst)
+		if fieldConstants[i] {
+			emitByte(1)
+		} else {
+			emitByte(0)
+		}
 	}
 
 	defineVariable(nameConstant)
 }
 
+// importPath returns the constant holding the file to import, written as the string literal just
+// consumed. The VM resolves it against the search path when the import runs.
+func importPath() (uint8, bool) {
+	specifier := parser.previous.Literal
+	if specifier == "" {
+		reportError("Expected the path of a file to import, not an empty string.")
+		return 0, false
+	}
+	return makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(specifier)}), true
+}
+
+// importDeclaration compiles 'import "file.z"', which defines the names the file exports as
+// globals, and 'import "file.z" as name', which binds the file's namespace to name instead.
 func importDeclaration() {
 	if match(token.TOKEN_STRING) {
-		filename := parser.previous.Start[1 : len(parser.previous.Start)-1]
-		absPath, errs := filepath.Abs(filepath.Join(current.scriptDir, filename))
-		if errs != nil {
-			reportError(fmt.Sprintf("Cannot resolve absolute path for '%s': %v", filename, errs))
+		pathConstant, ok := importPath()
+		if !ok {
 			return
 		}
-		pathConstant := makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(absPath)})
-		emitBytes(byte(runtime.OP_IMPORT), pathConstant)
+		if match(token.TOKEN_AS) {
+			global := parseVariable("Expected a name after 'as' (e.g., 'import \"math.z\" as math').")
+			emitBytes(byte(runtime.OP_IMPORT), pathConstant)
+			defineVariable(global)
+		} else {
+			emitBytes(byte(runtime.OP_IMPORT), pathConstant)
+			emitByte(byte(runtime.OP_IMPORT_ALL))
+		}
 		consumeOptionalSemicolon()
 	} else {
 		var path []string
@@ -592,10 +676,76 @@ func importDeclaration() {
 	}
 }
 
+// fromImportDeclaration compiles 'from "file.z" import a, b', which defines variables holding the
+// named exports of the file.
+func fromImportDeclaration() {
+	consume(token.TOKEN_STRING, "Expected the path of a file after 'from' (e.g., 'from \"math.z\" import add').")
+	pathConstant, ok := importPath()
+	if !ok {
+		return
+	}
+	consume(token.TOKEN_IMPORT, "Expected 'import' after the path in 'from' (e.g., 'from \"math.z\" import add').")
+	for {
+		global := parseVariable("Expected a name to import (e.g., 'add' in 'from \"math.z\" import add').")
+		// The file only runs on its first import; later ones give its namespace straight away.
+		emitBytes(byte(runtime.OP_IMPORT), pathConstant)
+		emitBytes(byte(runtime.OP_GET_PROPERTY), identifierConstant(parser.previous))
+		defineVariable(global)
+		if !match(token.TOKEN_COMMA) {
+			break
+		}
+	}
+	consumeOptionalSemicolon()
+}
+
+// exportDeclaration compiles 'export' followed by a declaration, which exports the globals it
+// defines, or by a list of globals to export, as in 'export add, PI'. Only the exported globals of
+// an imported file are visible to its importers; in the main script 'export' has no effect.
+func exportDeclaration() {
+	if current.functionType != TYPE_SCRIPT || current.scopeDepth > 0 {
+		reportError("Only top-level declarations can be exported.")
+	}
+	if match(token.TOKEN_IDENTIFIER) {
+		for {
+			exportName(parser.previous)
+			if !match(token.TOKEN_COMMA) {
+				break
+			}
+			consume(token.TOKEN_IDENTIFIER, "Expected a name to export after ','.")
+		}
+		consumeOptionalSemicolon()
+		return
+	}
+	switch parser.current.Type {
+	case token.TOKEN_VAR, token.TOKEN_CONST, token.TOKEN_FUNC, token.TOKEN_STRUCT, token.TOKEN_ENUM, token.TOKEN_MOD:
+		exporting = true
+		declaration()
+		exporting = false
+	default:
+		errorAtCurrent("Expected a declaration or a list of names after 'export' (e.g., 'export func add' or 'export add, PI').")
+	}
+}
+
+// exportName adds the global called name to the exports of the file being compiled.
+func exportName(name token.Token) {
+	if compilingModule != nil {
+		compilingModule.Exports[runtime.NewObjString(name.Start)] = true
+	}
+}
+
+// exportGlobal exports the global about to be defined, named by the constant global, when its
+// declaration follows 'export'.
+func exportGlobal(global uint8) {
+	if exporting && current.functionType == TYPE_SCRIPT {
+		name := currentChunk().Constants().Values()[global].Obj.(*runtime.ObjString)
+		exportName(token.Token{Start: name.Chars})
+	}
+}
+
 func useDeclaration() {
 	// Parse library name: use "mylib"
 	consume(token.TOKEN_STRING, "Expected a string literal after 'use' (e.g., 'use \"mylib\";').")
-	libName := parser.previous.Start[1 : len(parser.previous.Start)-1] // Remove quotes
+	libName := parser.previous.Literal
 	libPathConstant := makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(libName)})
 
 	consume(token.TOKEN_COLON, "Expected ':' after library name in 'use' statement.")
@@ -0,0 +1,200 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/cryptrunner49/zscript/internal/lexer"
+	"github.com/cryptrunner49/zscript/internal/runtime"
+	"github.com/cryptrunner49/zscript/internal/token"
+)
+
+// Destructuring is a parsed destructuring target: '[a, b, ...rest]' takes apart an array by
+// position, '{name, age, ...others}' a map by key and 'Point{x, y}' a struct instance by field.
+type Destructuring struct {
+	kind       runtime.UnpackKind
+	structName token.Token   // Struct named by an instance target.
+	names      []token.Token // Variables receiving the elements, values or fields, in order.
+	rest       *token.Token  // Variable receiving the remaining elements or entries, if any.
+}
+
+// variables returns the variables bound by the target in the order OP_UNPACK pushes their values.
+func (d *Destructuring) variables() []token.Token {
+	if d.rest == nil {
+		return d.names
+	}
+	return append(append([]token.Token(nil), d.names...), *d.rest)
+}
+
+// isDestructuringTarget reports whether the current token starts a destructuring target.
+func isDestructuringTarget() bool {
+	return check(token.TOKEN_LEFT_BRACKET) || check(token.TOKEN_LEFT_BRACE) ||
+		(check(token.TOKEN_IDENTIFIER) && lexer.PeekToken().Type == token.TOKEN_LEFT_BRACE)
+}
+
+// isDestructuringAssignment scans ahead to tell a destructuring assignment such as '[a, b] = pair'
+// from an expression statement or a block starting with the same tokens. Nothing is consumed.
+func isDestructuringAssignment() bool {
+	if !isDestructuringTarget() {
+		return false
+	}
+	state := lexer.Save()
+	defer lexer.Restore(state)
+
+	closing := token.TOKEN_RIGHT_BRACE
+	if check(token.TOKEN_LEFT_BRACKET) {
+		closing = token.TOKEN_RIGHT_BRACKET
+	} else if check(token.TOKEN_IDENTIFIER) {
+		lexer.ScanToken() // The '{' after the struct name.
+	}
+	for {
+		next := lexer.ScanToken()
+		if next.Type == token.TOKEN_DOT_DOT_DOT {
+			next = lexer.ScanToken()
+		}
+		if next.Type != token.TOKEN_IDENTIFIER {
+			return false
+		}
+		next = lexer.ScanToken()
+		if next.Type == closing {
+			return lexer.ScanToken().Type == token.TOKEN_EQUAL
+		}
+		if next.Type != token.TOKEN_COMMA {
+			return false
+		}
+	}
+}
+
+// parseDestructuring parses a destructuring target. A rest variable, written '...name', must come
+// last and collects the elements or entries not bound by name; struct targets cannot have one.
+func parseDestructuring() *Destructuring {
+	target := &Destructuring{kind: runtime.UNPACK_ARRAY}
+	closing := token.TOKEN_RIGHT_BRACKET
+	if match(token.TOKEN_IDENTIFIER) {
+		target.kind = runtime.UNPACK_INSTANCE
+		target.structName = parser.previous
+		consume(token.TOKEN_LEFT_BRACE, "Expected '{' after struct name in destructuring pattern.")
+		closing = token.TOKEN_RIGHT_BRACE
+	} else if match(token.TOKEN_LEFT_BRACE) {
+		target.kind = runtime.UNPACK_MAP
+		closing = token.TOKEN_RIGHT_BRACE
+	} else {
+		consume(token.TOKEN_LEFT_BRACKET, "Expected '[', '{' or a struct name to start a destructuring pattern.")
+	}
+
+	seen := make(map[string]bool)
+	for !check(closing) && !check(token.TOKEN_EOF) {
+		if match(token.TOKEN_DOT_DOT_DOT) {
+			if target.kind == runtime.UNPACK_INSTANCE {
+				reportError("A struct destructuring pattern cannot have a rest variable.")
+			}
+			consume(token.TOKEN_IDENTIFIER, "Expected a variable name after '...' in destructuring pattern.")
+			rest := parser.previous
+			target.rest = &rest
+			checkDestructuredName(rest, seen)
+			if !check(closing) {
+				errorAtCurrent("The rest variable must be the last one in a destructuring pattern.")
+			}
+			break
+		}
+		consume(token.TOKEN_IDENTIFIER, "Expected a variable name in destructuring pattern.")
+		target.names = append(target.names, parser.previous)
+		checkDestructuredName(parser.previous, seen)
+		if len(target.names) > 255 {
+			reportError("A destructuring pattern cannot have more than 255 variables.")
+		}
+		if !match(token.TOKEN_COMMA) {
+			break
+		}
+	}
+	if closing == token.TOKEN_RIGHT_BRACKET {
+		consume(closing, "Expected ']' after destructuring pattern.")
+	} else {
+		consume(closing, "Expected '}' after destructuring pattern.")
+	}
+	if len(target.names) == 0 && target.rest == nil {
+		reportError("A destructuring pattern must name at least one variable.")
+	}
+	return target
+}
+
+// checkDestructuredName reports an error when a destructuring pattern binds the same name twice.
+func checkDestructuredName(name token.Token, seen map[string]bool) {
+	if seen[name.Start] {
+		errorAt(name, fmt.Sprintf("Variable '%s' is bound more than once in this pattern.", name.Start))
+	}
+	seen[name.Start] = true
+}
+
+// emitUnpack replaces the value on top of the stack with the values of the target's variables.
+func emitUnpack(target *Destructuring) {
+	if target.kind == runtime.UNPACK_INSTANCE {
+		namedVariable(target.structName, false)
+	}
+	hasRest := byte(0)
+	if target.rest != nil {
+		hasRest = 1
+	}
+	emitBytes(byte(runtime.OP_UNPACK), byte(target.kind))
+	emitBytes(byte(len(target.names)), hasRest)
+	if target.kind != runtime.UNPACK_ARRAY {
+		for _, name := range target.names {
+			emitByte(identifierConstant(name))
+		}
+	}
+}
+
+// defineDestructured declares the target's variables from the values pushed by emitUnpack: in a
+// local scope the values become the variables' slots, at the top level they define globals. With
+// isConst set, as after 'const', the variables are constants.
+func defineDestructured(target *Destructuring, isConst bool) {
+	variables := target.variables()
+	if current.scopeDepth > 0 {
+		for _, name := range variables {
+			declareLocal(name)
+			if isConst {
+				defineConstant(0, name)
+			} else {
+				markInitialized()
+			}
+		}
+		return
+	}
+	for _, name := range variables {
+		checkConstRedeclaration(name)
+	}
+	for i := len(variables) - 1; i >= 0; i-- {
+		global := identifierConstant(variables[i])
+		if isConst {
+			defineConstant(global, variables[i])
+		} else {
+			defineVariable(global)
+		}
+	}
+}
+
+// destructuringDeclaration compiles 'var [a, b] = value' and the map and struct forms after 'var'
+// or 'const'.
+func destructuringDeclaration(isConst bool) {
+	target := parseDestructuring()
+	consume(token.TOKEN_EQUAL, "Expected '=' after destructuring pattern; destructured variables need a value.")
+	expression()
+	consumeOptionalSemicolon()
+	emitUnpack(target)
+	defineDestructured(target, isConst)
+}
+
+// destructuringAssignment compiles '[a, b] = value', assigning existing variables.
+func destructuringAssignment() {
+	target := parseDestructuring()
+	consume(token.TOKEN_EQUAL, "Expected '=' after destructuring pattern.")
+	expression()
+	consumeOptionalSemicolon()
+	emitUnpack(target)
+	variables := target.variables()
+	for i := len(variables) - 1; i >= 0; i-- {
+		_, setOp, arg, isConst := resolveVariable(variables[i])
+		checkAssignable(variables[i], isConst)
+		emitBytes(setOp, uint8(arg))
+		emitByte(byte(runtime.OP_POP))
+	}
+}
@@ -0,0 +1,298 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/cryptrunner49/zscript/internal/runtime"
+	"github.com/cryptrunner49/zscript/internal/token"
+)
+
+// PatternType identifies the kind of a parsed match pattern.
+type PatternType int
+
+const (
+	PATTERN_WILDCARD PatternType = iota // '*': matches anything without binding it.
+	PATTERN_LITERAL                     // A number, string, char, boolean or null compared with '=='.
+	PATTERN_BINDING                     // An identifier that matches anything and binds it.
+	PATTERN_ARRAY                       // '[p1, p2]': an array with exactly that many elements.
+	PATTERN_STRUCT                      // 'Point{x, y = 0}': an instance of the named struct.
+	PATTERN_VARIANT                     // 'Shape::Circle(r)': the named enum variant.
+)
+
+// Pattern is a parsed match pattern. Patterns are parsed before any code is emitted so that the
+// variables they bind can be reserved as locals ahead of the tests that assign them.
+type Pattern struct {
+	patternType PatternType
+	literal     runtime.Value // Value compared against for literal patterns.
+	name        token.Token   // Binding name, or the struct or enum name.
+	variant     token.Token   // Variant name of a variant pattern.
+	elements    []*Pattern    // Element patterns of an array pattern, or payload patterns of a variant.
+	fields      []token.Token // Field names of a struct pattern.
+	subpatterns []*Pattern    // Patterns applied to each field of a struct pattern.
+}
+
+// parsePattern parses a single match pattern.
+func parsePattern() *Pattern {
+	switch {
+	case match(token.TOKEN_STAR):
+		return &Pattern{patternType: PATTERN_WILDCARD}
+	case match(token.TOKEN_NUMBER):
+		return numberPattern(false)
+	case match(token.TOKEN_MINUS):
+		consume(token.TOKEN_NUMBER, "Expected a number after '-' in match pattern.")
+		return numberPattern(true)
+	case match(token.TOKEN_STRING), match(token.TOKEN_CHAR):
+		str := runtime.NewObjString(parser.previous.Literal)
+		return &Pattern{patternType: PATTERN_LITERAL, literal: runtime.ObjVal(str)}
+	case match(token.TOKEN_TRUE):
+		return &Pattern{patternType: PATTERN_LITERAL, literal: runtime.Value{Type: runtime.VAL_BOOL, Bool: true}}
+	case match(token.TOKEN_FALSE):
+		return &Pattern{patternType: PATTERN_LITERAL, literal: runtime.Value{Type: runtime.VAL_BOOL, Bool: false}}
+	case match(token.TOKEN_NULL):
+		return &Pattern{patternType: PATTERN_LITERAL, literal: runtime.Value{Type: runtime.VAL_NULL}}
+	case match(token.TOKEN_LEFT_BRACKET):
+		pattern := &Pattern{patternType: PATTERN_ARRAY}
+		if !check(token.TOKEN_RIGHT_BRACKET) {
+			for {
+				pattern.elements = append(pattern.elements, parsePattern())
+				if len(pattern.elements) > 255 {
+					reportError("Array pattern cannot have more than 255 elements.")
+				}
+				if !match(token.TOKEN_COMMA) {
+					break
+				}
+			}
+		}
+		consume(token.TOKEN_RIGHT_BRACKET, "Expected ']' after array pattern.")
+		return pattern
+	case match(token.TOKEN_IDENTIFIER):
+		name := parser.previous
+		if match(token.TOKEN_COLON_COLON) {
+			return variantPattern(name)
+		}
+		if !match(token.TOKEN_LEFT_BRACE) {
+			return &Pattern{patternType: PATTERN_BINDING, name: name}
+		}
+		return structPattern(name)
+	}
+	errorAtCurrent("Expected a match pattern (literal, '*', identifier, array, struct or enum variant pattern).")
+	return &Pattern{patternType: PATTERN_WILDCARD}
+}
+
+// numberPattern builds a literal pattern from the number token just consumed.
+func numberPattern(negate bool) *Pattern {
+	val, _ := numberValue()
+	if bigInt, ok := val.Obj.(*runtime.ObjBigInt); ok && negate {
+		bigInt.Value.Neg(bigInt.Value)
+	} else if negate {
+		val.Int, val.Number = -val.Int, -val.Number
+	}
+	return &Pattern{patternType: PATTERN_LITERAL, literal: val}
+}
+
+// structPattern parses the field list of a struct pattern after 'Name{'. A bare field name binds
+// the field to a variable of the same name; 'field = pattern' matches the field against a pattern.
+func structPattern(name token.Token) *Pattern {
+	pattern := &Pattern{patternType: PATTERN_STRUCT, name: name}
+	if !check(token.TOKEN_RIGHT_BRACE) {
+		for {
+			consume(token.TOKEN_IDENTIFIER, "Expected a field name in struct pattern (e.g., 'Point{x, y}').")
+			field := parser.previous
+			var sub *Pattern
+			if match(token.TOKEN_EQUAL) {
+				sub = parsePattern()
+			} else {
+				sub = &Pattern{patternType: PATTERN_BINDING, name: field}
+			}
+			pattern.fields = append(pattern.fields, field)
+			pattern.subpatterns = append(pattern.subpatterns, sub)
+			if !match(token.TOKEN_COMMA) {
+				break
+			}
+		}
+	}
+	consume(token.TOKEN_RIGHT_BRACE, "Expected '}' after struct pattern.")
+	return pattern
+}
+
+// variantPattern parses an enum variant pattern after 'Enum::'. Without parentheses it matches the
+// variant whatever its payload; 'Variant(p1, p2)' also matches each payload value against a pattern.
+func variantPattern(name token.Token) *Pattern {
+	consume(token.TOKEN_IDENTIFIER, "Expected a variant name after '::' in match pattern.")
+	pattern := &Pattern{patternType: PATTERN_VARIANT, name: name, variant: parser.previous}
+	if match(token.TOKEN_LEFT_PAREN) {
+		if !check(token.TOKEN_RIGHT_PAREN) {
+			for {
+				pattern.elements = append(pattern.elements, parsePattern())
+				if !match(token.TOKEN_COMMA) {
+					break
+				}
+			}
+		}
+		consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after variant payload patterns.")
+	}
+	return pattern
+}
+
+// declarePatternBindings reserves a local slot, initialized to null, for every variable bound by
+// the pattern. It reports an error when the same name is bound twice in one pattern.
+func declarePatternBindings(pattern *Pattern, seen map[string]bool) {
+	switch pattern.patternType {
+	case PATTERN_BINDING:
+		if seen[pattern.name.Start] {
+			errorAt(pattern.name, fmt.Sprintf("Variable '%s' is bound more than once in this pattern.", pattern.name.Start))
+			return
+		}
+		seen[pattern.name.Start] = true
+		emitByte(byte(runtime.OP_NULL))
+		addLocal(pattern.name)
+		markInitialized()
+	case PATTERN_ARRAY, PATTERN_VARIANT:
+		for _, element := range pattern.elements {
+			declarePatternBindings(element, seen)
+		}
+	case PATTERN_STRUCT:
+		for _, sub := range pattern.subpatterns {
+			declarePatternBindings(sub, seen)
+		}
+	}
+}
+
+// emitPatternTest emits the tests for a pattern against the value pushed by load. Every failed test
+// jumps with its false result left on the stack; the jump offsets are appended to failJumps so the
+// caller can patch them to a single failure path.
+func emitPatternTest(pattern *Pattern, load func(), failJumps *[]int) {
+	switch pattern.patternType {
+	case PATTERN_WILDCARD:
+		// Matches anything.
+	case PATTERN_BINDING:
+		load()
+		emitBytes(byte(runtime.OP_SET_LOCAL), uint8(resolveLocal(current, pattern.name)))
+		emitByte(byte(runtime.OP_POP))
+	case PATTERN_LITERAL:
+		load()
+		emitConstant(pattern.literal)
+		emitByte(byte(runtime.OP_EQUAL))
+		emitPatternFailJump(failJumps)
+	case PATTERN_ARRAY:
+		load()
+		emitBytes(byte(runtime.OP_MATCH), byte(runtime.MATCH_ARRAY))
+		emitByte(byte(len(pattern.elements)))
+		emitPatternFailJump(failJumps)
+		emitElementTests(pattern.elements, load, failJumps)
+	case PATTERN_STRUCT:
+		load()
+		namedVariable(pattern.name, false)
+		emitBytes(byte(runtime.OP_MATCH), byte(runtime.MATCH_INSTANCE))
+		emitByte(0)
+		emitPatternFailJump(failJumps)
+		for i, sub := range pattern.subpatterns {
+			field := identifierConstant(pattern.fields[i])
+			emitPatternTest(sub, func() {
+				load()
+				emitBytes(byte(runtime.OP_GET_PROPERTY), field)
+			}, failJumps)
+		}
+	case PATTERN_VARIANT:
+		load()
+		namedVariable(pattern.name, false)
+		emitBytes(byte(runtime.OP_MATCH), byte(runtime.MATCH_VARIANT))
+		emitByte(identifierConstant(pattern.variant))
+		emitPatternFailJump(failJumps)
+		// A value of the variant made without its payload, such as 'Shape::Circle', has none to test.
+		if len(pattern.elements) > 0 {
+			load()
+			emitBytes(byte(runtime.OP_MATCH), byte(runtime.MATCH_PAYLOAD))
+			emitByte(byte(len(pattern.elements)))
+			emitPatternFailJump(failJumps)
+		}
+		emitElementTests(pattern.elements, load, failJumps)
+	}
+}
+
+// emitElementTests emits the tests of the element patterns of an array or enum variant pattern,
+// each against the value at the same index of the value pushed by load.
+func emitElementTests(elements []*Pattern, load func(), failJumps *[]int) {
+	for i, element := range elements {
+		index := i
+		emitPatternTest(element, func() {
+			load()
+			emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: int64(index)})
+			emitByte(byte(runtime.OP_GET_VALUE))
+		}, failJumps)
+	}
+}
+
+// emitPatternFailJump emits the conditional jump taken when the test result on the stack is false.
+func emitPatternFailJump(failJumps *[]int) {
+	*failJumps = append(*failJumps, emitJump(byte(runtime.OP_JUMP_IF_FALSE)))
+	emitByte(byte(runtime.OP_POP))
+}
+
+// matchArm compiles one '| pattern [if guard]: body' arm against the subject stored in the given
+// local slot. body compiles the arm's consequence. The returned jump must be patched to the end of
+// the match so a successful arm skips the remaining ones.
+func matchArm(subject uint8, body func()) int {
+	beginScope()
+	firstBinding := current.localCount
+	pattern := parsePattern()
+	declarePatternBindings(pattern, make(map[string]bool))
+	bindingCount := current.localCount - firstBinding
+
+	var failJumps []int
+	emitPatternTest(pattern, func() {
+		emitBytes(byte(runtime.OP_GET_LOCAL), subject)
+	}, &failJumps)
+	if match(token.TOKEN_IF) {
+		expression()
+		emitPatternFailJump(&failJumps)
+	}
+	consume(token.TOKEN_COLON, "Expected ':' after match pattern.")
+
+	body()
+	endScope()
+	endJump := emitJump(byte(runtime.OP_JUMP))
+
+	// The failure path drops the test result and the bindings reserved for this arm before falling
+	// through to the next arm.
+	if len(failJumps) > 0 {
+		for _, jump := range failJumps {
+			patchJump(jump)
+		}
+		emitByte(byte(runtime.OP_POP))
+		for i := bindingCount - 1; i >= 0; i-- {
+			if current.locals[firstBinding+i].isCaptured {
+				emitByte(byte(runtime.OP_CLOSE_UPVALUE))
+			} else {
+				emitByte(byte(runtime.OP_POP))
+			}
+		}
+	}
+	return endJump
+}
+
+// beginMatchArms consumes the opening of a match body, either '{' or ':' followed by an indented
+// block, and reports whether the braced form was used.
+func beginMatchArms() bool {
+	if match(token.TOKEN_LEFT_BRACE) {
+		return true
+	}
+	consume(token.TOKEN_COLON, "Expected ':' or '{' after match subject.")
+	consume(token.TOKEN_INDENT, "Expected indented block of '|' arms after ':'.")
+	return false
+}
+
+// endMatchArms consumes the closing of a match body opened by beginMatchArms.
+func endMatchArms(braced bool) {
+	if braced {
+		consume(token.TOKEN_RIGHT_BRACE, "Expected '}' after match arms.")
+	} else {
+		consume(token.TOKEN_DEDENT, "Expected dedent after match arms.")
+	}
+}
+
+// isMatchArmEnd reports whether the current token ends the body of a match arm.
+func isMatchArmEnd() bool {
+	return check(token.TOKEN_PIPE) || check(token.TOKEN_RIGHT_BRACE) ||
+		check(token.TOKEN_DEDENT) || check(token.TOKEN_EOF)
+}
@@ -96,14 +96,17 @@ func whileStatement() {
 		start:           loopStart,
 		exitPatches:     make([]int, 0),
 		continuePatches: make([]int, 0),
+		localCount:      current.localCount,
 	})
-	currentLoop := &current.loops[len(current.loops)-1]
 
 	beginScope()
 	block()
 	endScope()
 	emitLoop(loopStart)
 
+	// Nested loops may have grown current.loops, so look the loop up again after the body.
+	currentLoop := &current.loops[len(current.loops)-1]
+
 	// Patch continue jumps
 	for _, operandPos := range currentLoop.continuePatches {
 		opAddress := operandPos - 1
@@ -156,6 +159,7 @@ func forStatement() {
 		start:           loopStart,
 		exitPatches:     make([]int, 0),
 		continuePatches: make([]int, 0),
+		localCount:      current.localCount,
 		hasIncrement:    false,
 	})
 	currentLoop := &current.loops[len(current.loops)-1]
@@ -185,6 +189,9 @@ func forStatement() {
 
 	emitLoop(loopStart)
 
+	// Nested loops may have grown current.loops, so look the loop up again after the body.
+	currentLoop = &current.loops[len(current.loops)-1]
+
 	if exitJump != -1 {
 		patchJump(exitJump)
 		emitByte(byte(runtime.OP_POP)) // Pop condition result
@@ -222,39 +229,62 @@ func forStatement() {
 
 // breakStatement compiles a break statement, jumping to the end of the innermost loop or match.
 func breakStatement() {
+	emitBreak()
+	consumeOptionalSemicolon()
+}
+
+// emitBreak emits the jump out of the innermost loop or match, passing through the 'finally'
+// clause of any 'try' statement inside it.
+func emitBreak() {
 	if len(current.loops) == 0 {
 		reportError("Cannot use 'break' outside of a loop or match statement.")
 		return
 	}
+	if leavesTry(len(current.loops) - 1) {
+		emitTryExit(TRY_EXIT_BREAK)
+		return
+	}
 	currentLoop := &current.loops[len(current.loops)-1]
+	discardLocals(currentLoop.localCount)
 	emitByte(byte(runtime.OP_BREAK))
 	operandPos := currentChunk().Count()
 	emitByte(0xFF)
 	emitByte(0xFF)
 	currentLoop.exitPatches = append(currentLoop.exitPatches, operandPos)
-	consumeOptionalSemicolon()
 }
 
 // continueStatement compiles a continue statement, applicable only to loops.
 func continueStatement() {
-	if len(current.loops) == 0 {
+	emitContinue()
+	consumeOptionalSemicolon()
+}
+
+// emitContinue emits the jump back to the innermost loop, passing through the 'finally' clause of
+// any 'try' statement inside it.
+func emitContinue() {
+	// A continue inside a 'match ... through' applies to the loop enclosing the match.
+	index := len(current.loops) - 1
+	for index >= 0 && current.loops[index].jumpType == JUMP_MATCH {
+		index--
+	}
+	if index < 0 {
 		reportError("Cannot use 'continue' outside of a loop.")
 		return
 	}
-	currentLoop := &current.loops[len(current.loops)-1]
-	if currentLoop.jumpType == JUMP_MATCH {
-		reportError("Cannot use 'continue' inside a match statement.")
+	if leavesTry(index) {
+		emitTryExit(TRY_EXIT_CONTINUE)
 		return
 	}
+	currentLoop := &current.loops[index]
 
 	// Emit the OP_CONTINUE opcode and reserve space for the jump offset, which will be patched to
 	// the loop’s start or increment position.
+	discardLocals(currentLoop.localCount)
 	emitByte(byte(runtime.OP_CONTINUE))
 	jumpPos := currentChunk().Count()
 	emitByte(0xFF)
 	emitByte(0xFF)
 	currentLoop.continuePatches = append(currentLoop.continuePatches, jumpPos)
-	consumeOptionalSemicolon()
 }
 
 func returnStatement() {
@@ -262,12 +292,25 @@ func returnStatement() {
 		reportError("Cannot use 'return' outside a function at top-level code.")
 	}
 	if match(token.TOKEN_SEMICOLON) {
-		emitReturn()
+		emitByte(byte(runtime.OP_RNULL))
 	} else {
 		expression()
 		consumeOptionalSemicolon()
+	}
+	emitReturnValue()
+}
+
+// emitReturnValue returns the value on top of the stack from the current function, running the
+// 'finally' clause of any enclosing 'try' statement first.
+func emitReturnValue() {
+	if len(current.tries) == 0 {
 		emitByte(byte(runtime.OP_RETURN))
+		return
 	}
+	try := &current.tries[len(current.tries)-1]
+	emitBytes(byte(runtime.OP_SET_LOCAL), try.valueSlot)
+	emitByte(byte(runtime.OP_POP))
+	emitTryExit(TRY_EXIT_RETURN)
 }
 
 // declareTemporary reserves a temporary local variable with a dummy name.
@@ -279,17 +322,28 @@ func declareTemporary() uint8 {
 	return uint8(current.localCount - 1)
 }
 
-func iterVarDeclaration() {
-	// Consume an identifier for the iterator variable.
-	consume(token.TOKEN_IDENTIFIER, "Expected iterator variable name.")
-	// Declare the variable in the current scope.
-	declareVariable()
-	// Mark it as initialized.
+// iterVarDeclaration declares the loop variables of an iter statement from the current value on
+// top of the stack: a single name, or the variables of a destructuring target.
+func iterVarDeclaration(name token.Token, target *Destructuring) {
+	if target != nil {
+		emitUnpack(target)
+		for _, variable := range target.variables() {
+			declareLocal(variable)
+			markInitialized()
+		}
+		return
+	}
+	declareLocal(name)
 	markInitialized()
 }
 
+// iterStatement compiles 'iter (var item in iterable):'. OP_ITERATOR turns the iterable into an
+// iterator, kept in a hidden local, and every iteration calls its done() and next() methods: the
+// protocol of the VM's native iterators, which structs implement to make their instances iterable.
+// 'var key, value' walks the [key, value] pairs of the iterable instead; any other destructuring
+// target takes apart each value.
 func iterStatement() {
-	// Start a new scope for the iterator variables to ensure proper cleanup.
+	// Start a new scope for the hidden local holding the iterator.
 	beginScope()
 
 	// Parse the iterator syntax: expect '(' after 'iter'.
@@ -300,75 +354,100 @@ func iterStatement() {
 		reportError("Expected 'var' after '(' in iter statement.")
 	}
 
-	// Declare the iterator variable (e.g., 'item') and get its slot in the local scope.
-	iterVarDeclaration()
-	iterVarSlot := uint8(current.localCount - 1)
+	// Parse the iterator variable (e.g., 'item'), a key and value pair (e.g., 'key, value') or a
+	// destructuring target (e.g., '[x, y]'). The variables are declared inside the loop.
+	var name token.Token
+	var target *Destructuring
+	entries := false
+	if isDestructuringTarget() {
+		target = parseDestructuring()
+	} else {
+		consume(token.TOKEN_IDENTIFIER, "Expected iterator variable name.")
+		name = parser.previous
+		if match(token.TOKEN_COMMA) {
+			consume(token.TOKEN_IDENTIFIER, "Expected a value variable name after ',' in iter statement.")
+			if identifiersEqual(name, parser.previous) {
+				reportError(fmt.Sprintf("Variable '%s' is bound more than once in this iter statement.", name.Start))
+			}
+			target = &Destructuring{kind: runtime.UNPACK_ARRAY, names: []token.Token{name, parser.previous}}
+			entries = true
+		}
+	}
 
 	// Expect 'in' to separate the variable from the iterable expression.
 	consume(token.TOKEN_IN, "Expected 'in' after iterator variable.")
 
-	// Compile the iterable expression (e.g., [1, 2, 3]), leaving it on the stack.
+	// Compile the iterable expression (e.g., [1, 2, 3]) and replace it with its iterator, which
+	// stays in a hidden local across iterations.
 	expression()
+	if entries {
+		emitBytes(byte(runtime.OP_ITERATOR), 1)
+	} else {
+		emitBytes(byte(runtime.OP_ITERATOR), 0)
+	}
+	iteratorSlot := declareTemporary()
 
 	// Expect ')' to close the iterator declaration.
-	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after condition.")
-	consume(token.TOKEN_COLON, "Expected ':' after while condition.")
-
-	// Store the iterable in a temporary local variable to persist across iterations.
-	arraySlot := declareTemporary()                  // Slot for the array (typically slot 2).
-	emitBytes(byte(runtime.OP_SET_LOCAL), arraySlot) // Assign iterable to local slot.
-	emitByte(byte(runtime.OP_POP))                   // Remove iterable from stack.
+	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after iterable.")
+	consume(token.TOKEN_COLON, "Expected ':' after iter clause.")
 
-	// Generate a unique global name for the iterator 'it' (e.g., "__iter_5").
-	uniqueIteratorName := fmt.Sprintf("__iter_%d", parser.previous.Line)
-	iteratorNameToken := token.Token{Start: uniqueIteratorName, Length: len(uniqueIteratorName), Line: parser.previous.Line}
-	iteratorConstant := identifierConstant(iteratorNameToken)
-
-	// Initialize the iterator by calling array_iter(iterable) and store in global 'it'.
-	constantIndex := identifierConstant(token.Token{Start: "array_iter", Length: len("array_iter"), Line: parser.previous.Line})
-	emitBytes(byte(runtime.OP_GET_GLOBAL), constantIndex)       // Push array_iter function.
-	emitBytes(byte(runtime.OP_GET_LOCAL), arraySlot)            // Push the iterable.
-	emitBytes(byte(runtime.OP_CALL), 1)                         // Call array_iter, returns iterator.
-	emitBytes(byte(runtime.OP_DEFINE_GLOBAL), iteratorConstant) // Define global 'it'.
-
-	// Mark the start of the iteration loop.
+	// Condition: Check if the iterator is done using it.done().
 	loopStart := currentChunk().Count()
+	doneName := identifierConstant(token.Token{Start: "done", Length: len("done"), Line: parser.previous.Line})
+	emitBytes(byte(runtime.OP_GET_LOCAL), iteratorSlot)
+	emitBytes(byte(runtime.OP_INVOKE), doneName)
+	emitByte(0)
+	exitJump := emitJump(byte(runtime.OP_JUMP_IF_TRUE)) // Jump to end if true (done).
+	emitByte(byte(runtime.OP_POP))                      // Pop false result.
 
-	// Condition: Check if the iterator is done using iter_done(it).
-	constantIndex = identifierConstant(token.Token{Start: "iter_done", Length: len("iter_done"), Line: parser.previous.Line})
-	emitBytes(byte(runtime.OP_GET_GLOBAL), constantIndex)    // Push iter_done function.
-	emitBytes(byte(runtime.OP_GET_GLOBAL), iteratorConstant) // Push global 'it'.
-	emitBytes(byte(runtime.OP_CALL), 1)                      // Call iter_done, returns bool.
-	exitJump := emitJump(byte(runtime.OP_JUMP_IF_TRUE))      // Jump to end if true (done).
-	emitByte(byte(runtime.OP_POP))                           // Pop false result.
-
-	// Get the current value from the iterator using iter_value(it).
-	constantIndex = identifierConstant(token.Token{Start: "iter_value", Length: len("iter_value"), Line: parser.previous.Line})
-	emitBytes(byte(runtime.OP_GET_GLOBAL), constantIndex)    // Push iter_value function.
-	emitBytes(byte(runtime.OP_GET_GLOBAL), iteratorConstant) // Push global 'it'.
-	emitBytes(byte(runtime.OP_CALL), 1)                      // Call iter_value, returns value.
-	emitBytes(byte(runtime.OP_SET_LOCAL), iterVarSlot)       // Assign value to 'item'.
+	current.loops = append(current.loops, Loop{
+		jumpType:        JUMP_ITER,
+		start:           loopStart,
+		exitPatches:     make([]int, 0),
+		continuePatches: make([]int, 0),
+		localCount:      current.localCount,
+	})
+
+	// Get the next value using it.next(); it becomes the loop variable, or is destructured into
+	// the loop variables, in a scope of its own.
+	beginScope()
+	nextName := identifierConstant(token.Token{Start: "next", Length: len("next"), Line: parser.previous.Line})
+	emitBytes(byte(runtime.OP_GET_LOCAL), iteratorSlot)
+	emitBytes(byte(runtime.OP_INVOKE), nextName)
+	emitByte(0)
+	iterVarDeclaration(name, target)
 
 	// Compile the loop body (e.g., { print item; }).
 	beginScope()
 	block()
 	endScope()
-
-	// Advance the iterator to the next element using iter_next(it).
-	constantIndex = identifierConstant(token.Token{Start: "iter_next", Length: len("iter_next"), Line: parser.previous.Line})
-	emitBytes(byte(runtime.OP_GET_GLOBAL), constantIndex)
-	emitBytes(byte(runtime.OP_GET_GLOBAL), iteratorConstant)
-	emitBytes(byte(runtime.OP_CALL), 1)
-	emitByte(byte(runtime.OP_POP))
+	endScope()
 
 	// Loop back to the condition check.
 	emitLoop(loopStart)
 
-	// Patch the exit jump to point here when iter_done returns true.
+	// Nested loops may have grown current.loops, so look the loop up again after the body.
+	currentLoop := &current.loops[len(current.loops)-1]
+
+	// Continue jumps back to the condition check.
+	for _, operandPos := range currentLoop.continuePatches {
+		offset := operandPos + 2 - loopStart
+		currentChunk().Code()[operandPos] = byte(offset >> 8)
+		currentChunk().Code()[operandPos+1] = byte(offset)
+	}
+
+	// Patch the exit jump to point here when done() returns true, and pop its result.
 	patchJump(exitJump)
+	emitByte(byte(runtime.OP_POP))
+
+	// Break jumps land after the exit, with the loop variables already discarded.
+	currentLoop.exitAddress = currentChunk().Count()
+	for _, patchPos := range currentLoop.exitPatches {
+		patchJump(patchPos)
+	}
+	current.loops = current.loops[:len(current.loops)-1]
 
-	// Cleanup: Adjust locals for scope exit.
-	current.localCount -= 2
+	// Discard the iterator.
 	endScope()
 }
 
@@ -376,3 +455,79 @@ func iterStatement() {
 func passStatement() {
 	consumeOptionalSemicolon()
 }
+
+// matchStatement compiles 'match subject with' followed by '|' arms, either braced or indented.
+// The first arm whose pattern matches (and whose guard, if any, holds) runs; the rest are skipped.
+// 'match subject through' compiles the fall-through form instead (see throughStatement).
+func matchStatement() {
+	// The subject lives in a hidden local so every arm can test it without re-evaluating it.
+	beginScope()
+	expression()
+	subject := declareTemporary()
+	if match(token.TOKEN_THROUGH) {
+		throughStatement(subject)
+		endScope()
+		return
+	}
+	consume(token.TOKEN_WITH, "Expected 'with' or 'through' after match subject.")
+	braced := beginMatchArms()
+
+	var endJumps []int
+	for match(token.TOKEN_PIPE) {
+		endJumps = append(endJumps, matchArm(subject, matchArmBody))
+	}
+	endMatchArms(braced)
+
+	for _, jump := range endJumps {
+		patchJump(jump)
+	}
+	endScope()
+}
+
+// matchArmBody compiles the body of a match statement arm: an indented block, or the inline
+// statements that follow the ':' up to the next arm.
+func matchArmBody() {
+	if check(token.TOKEN_INDENT) {
+		block()
+		return
+	}
+	inlineArmBody(func() {
+		for !isMatchArmEnd() {
+			if check(token.TOKEN_INDENT) {
+				// The arm's body already started on its ':' line, so an indented line cannot
+				// continue it.
+				reportError("Cannot indent the lines after an inline match arm; start the arm's body on the next line to write a block.")
+				skipIndentedLines()
+				continue
+			}
+			declaration()
+		}
+	})
+}
+
+// skipIndentedLines skips the indented lines starting at the current INDENT after reporting an
+// error about them, and resumes reporting errors at the line that follows.
+func skipIndentedLines() {
+	depth := 0
+	for !check(token.TOKEN_EOF) {
+		if check(token.TOKEN_INDENT) {
+			depth++
+		} else if check(token.TOKEN_DEDENT) {
+			depth--
+		}
+		advance()
+		if depth == 0 {
+			break
+		}
+	}
+	parser.panicMode = false
+}
+
+// inlineArmBody compiles the body of a match arm that follows its ':' on the same line, where a
+// '|' outside any parentheses or brackets starts the next arm rather than a bitwise OR.
+func inlineArmBody(body func()) {
+	enclosing := parser.armNesting
+	parser.armNesting = parser.nesting
+	body()
+	parser.armNesting = enclosing
+}
@@ -0,0 +1,184 @@
+package compiler
+
+import (
+	"github.com/cryptrunner49/zscript/internal/runtime"
+	"github.com/cryptrunner49/zscript/internal/token"
+)
+
+// CaseLabel is the value a 'match ... through' case compares the subject against.
+type CaseLabel struct {
+	isDefault  bool          // '*': taken when no other case matches.
+	isConstant bool          // The label is a literal known at compile time.
+	constant   runtime.Value // Literal value of a constant label.
+	name       token.Token   // Variable referenced by a non-constant label.
+	properties []token.Token // Fields read from that variable, as in 'Limits.max'.
+	variant    *token.Token  // Enum variant selected from it, as in 'Color::Red'.
+	address    int           // Bytecode address of the case body.
+}
+
+// parseCaseLabel parses the value of a 'match ... through' case: a literal, '*', or a variable
+// optionally followed by field accesses and an enum variant.
+func parseCaseLabel() CaseLabel {
+	if match(token.TOKEN_IDENTIFIER) {
+		label := CaseLabel{name: parser.previous}
+		for match(token.TOKEN_DOT) {
+			consume(token.TOKEN_IDENTIFIER, "Expected field name after '.' in case value.")
+			label.properties = append(label.properties, parser.previous)
+		}
+		if match(token.TOKEN_COLON_COLON) {
+			consume(token.TOKEN_IDENTIFIER, "Expected a variant name after '::' in case value.")
+			variant := parser.previous
+			label.variant = &variant
+		}
+		return label
+	}
+
+	pattern := parsePattern()
+	switch pattern.patternType {
+	case PATTERN_WILDCARD:
+		return CaseLabel{isDefault: true}
+	case PATTERN_LITERAL:
+		return CaseLabel{isConstant: true, constant: pattern.literal}
+	}
+	reportError("Case values in 'match ... through' must be literals, variables or '*'.")
+	return CaseLabel{isDefault: true}
+}
+
+// throughStatement compiles the arms of 'match subject through', a C-style switch. Control enters
+// at the first case equal to the subject (or at '| *:' when none is) and falls through the bodies
+// that follow until a 'break'.
+//
+// The case bodies are compiled first, in order, so each one runs straight into the next; the
+// dispatch that jumps into them is emitted after the last body, once every case value is known.
+// When all case values are constant numbers or strings the dispatch is a single OP_SWITCH lookup,
+// otherwise it compares the subject against each case in turn.
+func throughStatement(subject uint8) {
+	braced := beginMatchArms()
+	current.loops = append(current.loops, Loop{
+		jumpType:        JUMP_MATCH,
+		start:           currentChunk().Count(),
+		exitPatches:     make([]int, 0),
+		continuePatches: make([]int, 0),
+		localCount:      current.localCount,
+	})
+
+	dispatchJump := emitJump(byte(runtime.OP_JUMP))
+	var labels []CaseLabel
+	defaultAddress := -1
+	for match(token.TOKEN_PIPE) {
+		label := parseCaseLabel()
+		consume(token.TOKEN_COLON, "Expected ':' after case value.")
+		label.address = currentChunk().Count()
+		if label.isDefault {
+			if defaultAddress != -1 {
+				reportError("A 'match ... through' statement can only have one '*' case.")
+			}
+			defaultAddress = label.address
+		} else {
+			labels = append(labels, label)
+		}
+
+		beginScope()
+		matchArmBody()
+		endScope()
+	}
+	endMatchArms(braced)
+	endJump := emitJump(byte(runtime.OP_JUMP))
+
+	patchJump(dispatchJump)
+	if canUseSwitchTable(labels) {
+		emitSwitchTable(subject, labels, defaultAddress)
+	} else {
+		emitCaseChain(subject, labels, defaultAddress)
+	}
+	patchJump(endJump)
+
+	// Patch break jumps to the end of the statement.
+	currentLoop := &current.loops[len(current.loops)-1]
+	currentLoop.exitAddress = currentChunk().Count()
+	for _, patchPos := range currentLoop.exitPatches {
+		patchJump(patchPos)
+	}
+	current.loops = current.loops[:len(current.loops)-1]
+}
+
+// canUseSwitchTable reports whether every case value is a constant number or string.
+func canUseSwitchTable(labels []CaseLabel) bool {
+	for _, label := range labels {
+		if !label.isConstant {
+			return false
+		}
+		if runtime.IsNumber(label.constant) {
+			continue
+		}
+		if _, ok := label.constant.Obj.(*runtime.ObjString); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// emitSwitchTable emits an OP_SWITCH whose table maps each case value to its body. A value that
+// appears in several cases jumps to the first of them.
+func emitSwitchTable(subject uint8, labels []CaseLabel, defaultAddress int) {
+	emitBytes(byte(runtime.OP_GET_LOCAL), subject)
+	table := runtime.NewSwitchTable(defaultAddress)
+	index := currentChunk().AddSwitchTable(table)
+	if index > 255 {
+		reportError("Too many 'match ... through' statements in one function (max 256).")
+	}
+	emitBytes(byte(runtime.OP_SWITCH), byte(index))
+	if defaultAddress == -1 {
+		table.Default = currentChunk().Count()
+	}
+
+	for _, label := range labels {
+		if runtime.IsNumber(label.constant) {
+			if key, ok := runtime.SwitchIntKey(label.constant); ok {
+				if _, ok := table.Ints[key]; !ok {
+					table.Ints[key] = label.address
+				}
+			} else if _, ok := table.Numbers[label.constant.Number]; !ok {
+				table.Numbers[label.constant.Number] = label.address
+			}
+			continue
+		}
+		str := label.constant.Obj.(*runtime.ObjString)
+		if _, ok := table.Strings[str.Chars]; !ok {
+			table.Strings[str.Chars] = label.address
+		}
+	}
+}
+
+// emitCaseChain emits the dispatch used when some case value is not a constant number or string:
+// the subject is compared against each case in order and the first equal one is entered.
+func emitCaseChain(subject uint8, labels []CaseLabel, defaultAddress int) {
+	for _, label := range labels {
+		emitBytes(byte(runtime.OP_GET_LOCAL), subject)
+		emitCaseLabel(label)
+		emitByte(byte(runtime.OP_EQUAL))
+		nextCase := emitJump(byte(runtime.OP_JUMP_IF_FALSE))
+		emitByte(byte(runtime.OP_POP))
+		emitLoop(label.address)
+		patchJump(nextCase)
+		emitByte(byte(runtime.OP_POP))
+	}
+	if defaultAddress != -1 {
+		emitLoop(defaultAddress)
+	}
+}
+
+// emitCaseLabel pushes the value of a case label.
+func emitCaseLabel(label CaseLabel) {
+	if label.isConstant {
+		emitConstant(label.constant)
+		return
+	}
+	namedVariable(label.name, false)
+	for _, property := range label.properties {
+		emitBytes(byte(runtime.OP_GET_PROPERTY), identifierConstant(property))
+	}
+	if label.variant != nil {
+		emitBytes(byte(runtime.OP_GET_VARIANT), identifierConstant(*label.variant))
+	}
+}
@@ -0,0 +1,190 @@
+package compiler
+
+import (
+	"github.com/cryptrunner49/zscript/internal/runtime"
+	"github.com/cryptrunner49/zscript/internal/token"
+)
+
+// TryExit records why control entered the 'finally' clause of a try statement, and so what has
+// to happen once the clause has run.
+type TryExit int
+
+const (
+	TRY_EXIT_NONE     TryExit = iota // The blocks completed normally or the error was caught.
+	TRY_EXIT_ERROR                   // An error escaped the blocks and is rethrown.
+	TRY_EXIT_RETURN                  // A 'return' left the blocks; its value is returned.
+	TRY_EXIT_BREAK                   // A 'break' left the blocks.
+	TRY_EXIT_CONTINUE                // A 'continue' left the blocks.
+)
+
+// TryBlock tracks a try statement while its 'try' and 'catch' blocks are being compiled.
+type TryBlock struct {
+	kindSlot   uint8            // Hidden local holding the TryExit taken into the 'finally' clause.
+	valueSlot  uint8            // Hidden local holding the pending error or return value.
+	localCount int              // Locals live outside the try statement's blocks.
+	loopDepth  int              // Number of loops enclosing the try statement.
+	exitJumps  []int            // Jumps into the 'finally' clause, patched once it is reached.
+	exits      map[TryExit]bool // Exits other than errors taken from the blocks.
+}
+
+// tryStatement compiles 'try:' followed by an optional 'catch (name):' and an optional 'finally:'.
+//
+// OP_TRY registers a handler that the VM unwinds to when an error is raised in the 'try' block,
+// entering the catch clause with the error on top of the stack. The catch clause is guarded by a
+// second handler, so an error raised while handling the first still runs the 'finally' clause.
+// Every way out of the blocks (falling off the end, an uncaught error, 'return', 'break' and
+// 'continue') records a TryExit and jumps to the 'finally' clause, which is compiled once and
+// followed by a dispatch that carries on with the recorded exit.
+func tryStatement() {
+	beginScope()
+	emitByte(byte(runtime.OP_NULL))
+	valueSlot := declareTemporary()
+	emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: int64(TRY_EXIT_NONE)})
+	kindSlot := declareTemporary()
+	current.tries = append(current.tries, TryBlock{
+		kindSlot:   kindSlot,
+		valueSlot:  valueSlot,
+		localCount: current.localCount,
+		loopDepth:  len(current.loops),
+		exits:      make(map[TryExit]bool),
+	})
+
+	consume(token.TOKEN_COLON, "Expected ':' after 'try'.")
+	handler := emitJump(byte(runtime.OP_TRY))
+	beginScope()
+	block()
+	endScope()
+	emitByte(byte(runtime.OP_END_TRY))
+	addFinallyJump(emitJump(byte(runtime.OP_JUMP)))
+
+	patchJump(handler)
+	hasCatch := match(token.TOKEN_CATCH)
+	if hasCatch {
+		catchClause(valueSlot, kindSlot)
+	} else {
+		emitPendingError(valueSlot, kindSlot)
+	}
+
+	try := current.tries[len(current.tries)-1]
+	current.tries = current.tries[:len(current.tries)-1]
+	for _, jump := range try.exitJumps {
+		patchJump(jump)
+	}
+	if match(token.TOKEN_FINALLY) {
+		consume(token.TOKEN_COLON, "Expected ':' after 'finally'.")
+		beginScope()
+		block()
+		endScope()
+	} else if !hasCatch {
+		errorAtCurrent("Expected 'catch' or 'finally' after 'try' block.")
+	}
+
+	emitExitDispatch(try, TRY_EXIT_ERROR)
+	for _, exit := range []TryExit{TRY_EXIT_RETURN, TRY_EXIT_BREAK, TRY_EXIT_CONTINUE} {
+		if try.exits[exit] {
+			emitExitDispatch(try, exit)
+		}
+	}
+	endScope()
+}
+
+// catchClause compiles 'catch (name):' and its block. The VM enters it with the caught error on
+// top of the stack, which becomes the local 'name'.
+func catchClause(valueSlot uint8, kindSlot uint8) {
+	consume(token.TOKEN_LEFT_PAREN, "Expected '(' after 'catch'.")
+	consume(token.TOKEN_IDENTIFIER, "Expected error variable name in 'catch'.")
+	name := parser.previous
+	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after catch variable.")
+	consume(token.TOKEN_COLON, "Expected ':' after catch clause.")
+
+	beginScope()
+	addLocal(name)
+	markInitialized()
+	errorLocal := current.localCount - 1
+	handler := emitJump(byte(runtime.OP_TRY))
+	block()
+	emitByte(byte(runtime.OP_END_TRY))
+	captured := current.locals[errorLocal].isCaptured
+	endScope()
+	addFinallyJump(emitJump(byte(runtime.OP_JUMP)))
+
+	// An error raised in the block arrives above the caught one, which is discarded with it.
+	patchJump(handler)
+	emitBytes(byte(runtime.OP_SET_LOCAL), valueSlot)
+	emitByte(byte(runtime.OP_POP))
+	if captured {
+		emitByte(byte(runtime.OP_CLOSE_UPVALUE))
+	} else {
+		emitByte(byte(runtime.OP_POP))
+	}
+	emitExitKind(kindSlot, TRY_EXIT_ERROR)
+}
+
+// emitPendingError stores the error on top of the stack so the 'finally' clause rethrows it.
+func emitPendingError(valueSlot uint8, kindSlot uint8) {
+	emitBytes(byte(runtime.OP_SET_LOCAL), valueSlot)
+	emitByte(byte(runtime.OP_POP))
+	emitExitKind(kindSlot, TRY_EXIT_ERROR)
+}
+
+// emitExitKind records the exit taken into the 'finally' clause.
+func emitExitKind(kindSlot uint8, exit TryExit) {
+	emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: int64(exit)})
+	emitBytes(byte(runtime.OP_SET_LOCAL), kindSlot)
+	emitByte(byte(runtime.OP_POP))
+}
+
+// addFinallyJump registers a jump to the 'finally' clause of the innermost try statement.
+func addFinallyJump(jump int) {
+	try := &current.tries[len(current.tries)-1]
+	try.exitJumps = append(try.exitJumps, jump)
+}
+
+// leavesTry reports whether jumping out of the loop at the given index leaves the innermost try
+// statement, which then has to run its 'finally' clause first.
+func leavesTry(loopIndex int) bool {
+	return len(current.tries) > 0 && current.tries[len(current.tries)-1].loopDepth > loopIndex
+}
+
+// emitTryExit leaves the blocks of the innermost try statement for its 'finally' clause: the exit
+// is recorded, the block locals are discarded and the active handler is removed.
+func emitTryExit(exit TryExit) {
+	try := &current.tries[len(current.tries)-1]
+	try.exits[exit] = true
+	emitExitKind(try.kindSlot, exit)
+	discardLocals(try.localCount)
+	emitByte(byte(runtime.OP_END_TRY))
+	addFinallyJump(emitJump(byte(runtime.OP_JUMP)))
+}
+
+// emitExitDispatch emits the code run after the 'finally' clause when the given exit was taken:
+// the pending error is rethrown, or the return, break or continue is carried on outside the try
+// statement.
+func emitExitDispatch(try TryBlock, exit TryExit) {
+	emitBytes(byte(runtime.OP_GET_LOCAL), try.kindSlot)
+	emitConstant(runtime.Value{Type: runtime.VAL_INT, Int: int64(exit)})
+	emitByte(byte(runtime.OP_EQUAL))
+	skip := emitJump(byte(runtime.OP_JUMP_IF_FALSE))
+	emitByte(byte(runtime.OP_POP))
+	switch exit {
+	case TRY_EXIT_ERROR:
+		emitBytes(byte(runtime.OP_GET_LOCAL), try.valueSlot)
+		emitByte(byte(runtime.OP_THROW))
+	case TRY_EXIT_RETURN:
+		emitBytes(byte(runtime.OP_GET_LOCAL), try.valueSlot)
+		emitReturnValue()
+	case TRY_EXIT_BREAK:
+		emitBreak()
+	case TRY_EXIT_CONTINUE:
+		emitContinue()
+	}
+	patchJump(skip)
+	emitByte(byte(runtime.OP_POP))
+}
+
+// throwStatement compiles 'throw value', raising the value as an error.
+func throwStatement() {
+	expression()
+	consumeOptionalSemicolon()
+	emitByte(byte(runtime.OP_THROW))
+}
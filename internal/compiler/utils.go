@@ -17,7 +17,12 @@ func errorAt(t token.Token, message string) {
 		return
 	}
 	parser.panicMode = true
-	fmt.Fprintf(os.Stderr, "[line %d] Error", t.Line)
+	if compilingModule != nil {
+		// An imported file is compiled while another script runs, so its errors name the file.
+		fmt.Fprintf(os.Stderr, "[%s, line %d] Error", current.scriptPath, t.Line)
+	} else {
+		fmt.Fprintf(os.Stderr, "[line %d] Error", t.Line)
+	}
 	if t.Type == token.TOKEN_EOF {
 		fmt.Fprintf(os.Stderr, " at end of file")
 	} else if t.Type == token.TOKEN_ERROR {
@@ -26,6 +31,10 @@ func errorAt(t token.Token, message string) {
 		fmt.Fprintf(os.Stderr, " at '%s'", t.Start)
 	}
 	fmt.Fprintf(os.Stderr, ": %s\n", message)
+	// 'c ?[1] : [2]' reads as the optional subscript 'c?[1]', which then meets the ':'.
+	if t.Type == token.TOKEN_COLON && parser.afterOptionalSubscript {
+		fmt.Fprintf(os.Stderr, "  '?[' is an optional subscript; put a space after the '?' to start a conditional with an array, as in 'c ? [1] : [2]'.\n")
+	}
 	parser.hadError = true
 }
 
@@ -47,6 +56,13 @@ func currentChunk() *runtime.Chunk {
 // advance moves to the next token, skipping over any lexer errors and reporting them.
 func advance() {
 	parser.previous = parser.current
+	parser.afterOptionalSubscript = false
+	switch parser.previous.Type {
+	case token.TOKEN_LEFT_PAREN, token.TOKEN_LEFT_BRACKET, token.TOKEN_LEFT_BRACE:
+		parser.nesting++
+	case token.TOKEN_RIGHT_PAREN, token.TOKEN_RIGHT_BRACKET, token.TOKEN_RIGHT_BRACE:
+		parser.nesting--
+	}
 	for {
 		parser.current = lexer.ScanToken()
 		if parser.current.Type != token.TOKEN_ERROR {
@@ -80,10 +96,28 @@ func match(typ token.TokenType) bool {
 }
 
 // argumentList compiles the list of arguments in a function call and returns the count.
+// Arguments written 'name = value' are keyword arguments and must come after the positional ones.
 func argumentList() uint8 {
 	var argCount uint8 = 0
+	var names []uint8
+	var seen []string
 	if !check(token.TOKEN_RIGHT_PAREN) {
 		for {
+			// 'name = value' passes the argument to the parameter with that name.
+			if check(token.TOKEN_IDENTIFIER) && lexer.PeekToken().Type == token.TOKEN_EQUAL {
+				advance()
+				name := parser.previous
+				for _, other := range seen {
+					if other == name.Start {
+						reportError(fmt.Sprintf("Keyword argument '%s' is given more than once.", name.Start))
+					}
+				}
+				seen = append(seen, name.Start)
+				names = append(names, identifierConstant(name))
+				advance()
+			} else if len(names) > 0 {
+				errorAtCurrent("Positional arguments must come before keyword arguments.")
+			}
 			expression()
 			if argCount == 255 {
 				reportError("Function call cannot have more than 255 arguments.")
@@ -95,6 +129,13 @@ func argumentList() uint8 {
 		}
 	}
 	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' to close argument list (e.g., 'func(a, b)').")
+	// The names of the keyword arguments, which end the argument list, precede the call.
+	if len(names) > 0 {
+		emitBytes(byte(runtime.OP_ARGUMENT_NAMES), byte(len(names)))
+		for _, name := range names {
+			emitByte(name)
+		}
+	}
 	return argCount
 }
 
@@ -106,8 +147,8 @@ func synchronize() {
 			return
 		}
 		switch parser.current.Type {
-		case token.TOKEN_CLASS, token.TOKEN_FUNC, token.TOKEN_VAR, token.TOKEN_FOR,
-			token.TOKEN_IF, token.TOKEN_WHILE, token.TOKEN_RETURN:
+		case token.TOKEN_CLASS, token.TOKEN_STRUCT, token.TOKEN_ENUM, token.TOKEN_FUNC, token.TOKEN_VAR, token.TOKEN_CONST, token.TOKEN_FOR,
+			token.TOKEN_IF, token.TOKEN_WHILE, token.TOKEN_RETURN, token.TOKEN_TRY, token.TOKEN_THROW:
 			return
 		}
 		advance()
@@ -9,27 +9,123 @@ import (
 func function(funcType FunctionType) {
 	var compiler Compiler
 	// Initialize the compiler for the function, setting up the function type and script directory.
-	initCompiler(&compiler, funcType, current.scriptDir) // Regular function: no module context
+	initCompiler(&compiler, funcType, current.scriptPath) // Regular function: no module context
 
 	beginScope()
 	consume(token.TOKEN_LEFT_PAREN, "Expected '(' after function name to start parameter list.")
-	if !check(token.TOKEN_RIGHT_PAREN) {
-		for {
-			current.function.Arity++
-			if current.function.Arity > 255 {
-				errorAtCurrent("Function cannot have more than 255 parameters.")
-			}
-			paramConstant := parseVariable("Expected a parameter name (e.g., 'x' in 'fn foo(x)').")
-			defineVariable(paramConstant)
-			if !match(token.TOKEN_COMMA) {
-				break
-			}
-		}
-	}
+	parameterList()
 	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after parameters.")
 	consume(token.TOKEN_COLON, "Expected ':' after function parameters.")
 	block()
 	function := endCompiler()
+	emitClosure(function, &compiler)
+}
+
+// lambda compiles an anonymous function expression, either 'func(params): expression', which
+// returns the value of the expression, or 'func(params):' followed by an indented block. Like a
+// named function, it becomes a closure capturing the variables it uses from enclosing scopes.
+func lambda(canAssign bool) {
+	var compiler Compiler
+	initCompiler(&compiler, TYPE_FUNCTION, current.scriptPath)
+	compiler.function.Name = runtime.CopyString("lambda")
+
+	beginScope()
+	consume(token.TOKEN_LEFT_PAREN, "Expected '(' after 'func' to start the parameter list of a lambda.")
+	parameterList()
+	consume(token.TOKEN_RIGHT_PAREN, "Expected ')' after parameters.")
+	consume(token.TOKEN_COLON, "Expected ':' after lambda parameters.")
+	if check(token.TOKEN_INDENT) {
+		block()
+	} else {
+		expression()
+		emitByte(byte(runtime.OP_RETURN))
+	}
+	function := endCompiler()
+	emitClosure(function, &compiler)
+}
+
+// yieldExpression compiles 'yield value', which makes the enclosing function a generator. The
+// generator pauses there, handing the value (null when it is left out) to whoever resumed it,
+// and the expression evaluates to the value passed to send() when it is resumed again.
+func yieldExpression(canAssign bool) {
+	switch {
+	case current.function.Inline:
+		reportError("Cannot use 'yield' inside a match expression; use a match statement instead.")
+	case current.functionType == TYPE_SCRIPT:
+		reportError("Cannot use 'yield' outside a function at top-level code.")
+	case current.functionType == TYPE_INITIALIZER:
+		reportError("Cannot use 'yield' in a struct initializer.")
+	}
+	current.function.IsGenerator = true
+	if parser.current.Line != parser.previous.Line || endsYieldValue() {
+		emitByte(byte(runtime.OP_NULL))
+	} else {
+		expression()
+	}
+	emitByte(byte(runtime.OP_YIELD))
+}
+
+// endsYieldValue reports whether the current token closes the expression around a 'yield', so the
+// yield has no value.
+func endsYieldValue() bool {
+	switch parser.current.Type {
+	case token.TOKEN_RIGHT_PAREN, token.TOKEN_RIGHT_BRACKET, token.TOKEN_RIGHT_BRACE, token.TOKEN_COMMA,
+		token.TOKEN_SEMICOLON, token.TOKEN_DEDENT, token.TOKEN_EOF:
+		return true
+	}
+	return false
+}
+
+// parameterList compiles the parameters of the current function. A parameter with a default
+// value ('b = 10') can be left out by callers; its default is compiled at the start of the
+// function behind OP_DEFAULT_ARG, which skips it when the call supplied the argument, so
+// defaults are evaluated on every call that needs them and can use earlier parameters. A final
+// '...rest' parameter receives the surplus arguments as an array.
+func parameterList() {
+	function := current.function
+	if check(token.TOKEN_RIGHT_PAREN) {
+		return
+	}
+	for {
+		if match(token.TOKEN_DOT_DOT_DOT) {
+			paramConstant := parseVariable("Expected a rest parameter name after '...' (e.g., '...rest').")
+			defineVariable(paramConstant)
+			function.Variadic = true
+			if check(token.TOKEN_COMMA) {
+				errorAtCurrent("The rest parameter must be the last parameter.")
+			}
+			break
+		}
+		function.Arity++
+		if function.Arity > 255 {
+			errorAtCurrent("Function cannot have more than 255 parameters.")
+		}
+		paramConstant := parseVariable("Expected a parameter name (e.g., 'x' in 'fn foo(x)').")
+		function.Params = append(function.Params, runtime.CopyString(parser.previous.Start))
+		if match(token.TOKEN_EQUAL) {
+			function.Optional++
+			slot := byte(current.localCount - 1)
+			emitBytes(byte(runtime.OP_DEFAULT_ARG), slot)
+			emitByte(0xff)
+			emitByte(0xff)
+			given := currentChunk().Count() - 2
+			expression()
+			emitBytes(byte(runtime.OP_SET_LOCAL), slot)
+			emitByte(byte(runtime.OP_POP))
+			patchJump(given)
+		} else if function.Optional > 0 {
+			reportError("A parameter without a default value cannot follow one with a default value.")
+		}
+		defineVariable(paramConstant)
+		if !match(token.TOKEN_COMMA) {
+			break
+		}
+	}
+}
+
+// emitClosure emits OP_CLOSURE for a compiled function followed by the (isLocal, index) pair of
+// every upvalue recorded by the compiler that produced it.
+func emitClosure(function *runtime.ObjFunction, compiler *Compiler) {
 	emitBytes(byte(runtime.OP_CLOSURE), makeConstant(runtime.Value{Type: runtime.VAL_OBJ, Obj: function}))
 	for i := 0; i < function.UpvalueCount; i++ {
 		isLocal := compiler.upvalues[i].isLocal
@@ -45,6 +141,36 @@ func function(funcType FunctionType) {
 	}
 }
 
+// matchExpression compiles 'match subject with' used as an expression, where each arm body is an
+// expression whose value becomes the result (null when no arm matches). Arm bindings need real
+// local slots, which cannot be reserved in the middle of an enclosing expression, so the match is
+// compiled as a nested function that is called immediately; outer variables are reached through
+// upvalues exactly as in any other closure. The function is marked inline, so stack traces show
+// its frame as the function the match is written in, and 'yield' is rejected inside it.
+func matchExpression(canAssign bool) {
+	var compiler Compiler
+	initCompiler(&compiler, TYPE_FUNCTION, current.scriptPath)
+	compiler.function.Inline = true
+	beginScope()
+	expression()
+	subject := declareTemporary()
+	consume(token.TOKEN_WITH, "Expected 'with' after match subject.")
+	braced := beginMatchArms()
+
+	for match(token.TOKEN_PIPE) {
+		patchJump(matchArm(subject, func() {
+			inlineArmBody(expression)
+			consumeOptionalSemicolon()
+			emitByte(byte(runtime.OP_RETURN))
+		}))
+	}
+	endMatchArms(braced)
+
+	function := endCompiler()
+	emitClosure(function, &compiler)
+	emitBytes(byte(runtime.OP_CALL), 0)
+}
+
 // arrayLiteral parses an array literal and emits the corresponding bytecode.
 // It collects the elements, enforces a maximum element count of 255, and then
 // emits an OP_ARRAY opcode with the element count.
@@ -71,47 +197,75 @@ func arrayLiteral(canAssign bool) {
 // For slices, it expects an optional start expression, a colon, and an optional end expression.
 // It emits either an OP_ARRAY_SLICE or an OP_ARRAY_GET/OP_ARRAY_SET opcode depending on the context.
 // subscript parses array and map subscript expressions, supporting element access and slice syntax.
-// For slices, it handles an optional start expression, a colon, and an optional end expression,
-// emitting OP_ARRAY_SLICE. For element access, it emits generic OP_GET_VALUE or OP_SET_VALUE opcodes
+// For slices, it handles an optional start expression, a colon, an optional end expression and an
+// optional ':step', emitting OP_ARRAY_SLICE. For element access, it emits generic OP_GET_VALUE or OP_SET_VALUE opcodes
 // based on the context (e.g., assignment or retrieval).
 func subscript(canAssign bool) {
 	// Handle slice start (optional)
-	hasStart := !check(token.TOKEN_COLON) && !check(token.TOKEN_RIGHT_BRACKET)
+	hasStart := !check(token.TOKEN_COLON) && !check(token.TOKEN_COLON_COLON) && !check(token.TOKEN_RIGHT_BRACKET)
 	if hasStart {
 		expression()
 	} else {
 		emitConstant(runtime.Value{Type: runtime.VAL_NULL}) // Default start
 	}
 
-	if match(token.TOKEN_COLON) {
+	if match(token.TOKEN_COLON_COLON) {
+		// 'a[start::step]' omits the end, which lexes as a single '::'.
+		emitConstant(runtime.Value{Type: runtime.VAL_NULL}) // Default end
+		sliceStep()
+	} else if match(token.TOKEN_COLON) {
 		// Handle slice end (optional)
-		hasEnd := !check(token.TOKEN_RIGHT_BRACKET)
+		hasEnd := !check(token.TOKEN_RIGHT_BRACKET) && !check(token.TOKEN_COLON)
 		if hasEnd {
 			expression()
 		} else {
 			emitConstant(runtime.Value{Type: runtime.VAL_NULL}) // Default end
 		}
-		consume(token.TOKEN_RIGHT_BRACKET, "Expected ']' after slice")
-		emitByte(byte(runtime.OP_ARRAY_SLICE)) // Array-specific slice
+		if match(token.TOKEN_COLON) {
+			sliceStep()
+		} else {
+			consume(token.TOKEN_RIGHT_BRACKET, "Expected ']' after slice")
+			emitConstant(runtime.Value{Type: runtime.VAL_NULL}) // Default step
+			emitByte(byte(runtime.OP_ARRAY_SLICE))
+		}
 	} else {
 		// Regular element access - use generic index ops
 		consume(token.TOKEN_RIGHT_BRACKET, "Expected ']' after index")
 		if canAssign && match(token.TOKEN_EQUAL) {
 			expression()
 			emitByte(byte(runtime.OP_SET_VALUE)) // Works for arrays AND maps
+		} else if op, ok := matchCompoundAssignment(canAssign); ok {
+			// Keep the container and index for the store while the current element is read.
+			emitByte(byte(runtime.OP_DUP_TWO))
+			emitByte(byte(runtime.OP_GET_VALUE))
+			expression()
+			emitByte(byte(op))
+			emitByte(byte(runtime.OP_SET_VALUE))
 		} else {
 			emitByte(byte(runtime.OP_GET_VALUE)) // Works for arrays AND maps
 		}
 	}
 }
 
+// sliceStep compiles the optional step of a slice after its second ':' and the closing ']', then
+// emits OP_ARRAY_SLICE.
+func sliceStep() {
+	if check(token.TOKEN_RIGHT_BRACKET) {
+		emitConstant(runtime.Value{Type: runtime.VAL_NULL}) // Default step
+	} else {
+		expression()
+	}
+	consume(token.TOKEN_RIGHT_BRACKET, "Expected ']' after slice step")
+	emitByte(byte(runtime.OP_ARRAY_SLICE))
+}
+
 func mapLiteral(canAssign bool) {
 	pairs := 0
 	for !check(token.TOKEN_RIGHT_BRACE) && !check(token.TOKEN_EOF) {
 		// Parse key
 		if match(token.TOKEN_STRING) {
 			// Key is a string literal
-			key := parser.previous.Start[1 : len(parser.previous.Start)-1]
+			key := parser.previous.Literal
 			emitConstant(runtime.ObjVal(runtime.NewObjString(key)))
 		} else if match(token.TOKEN_IDENTIFIER) {
 			// Key is an identifier (treated as string)
@@ -150,6 +304,8 @@ func instance(canAssign bool) {
 		return
 	}
 
+	// The instance is created, with its field defaults, before the initializers are evaluated.
+	emitByte(byte(runtime.OP_NEW))
 	argCount := instanceArgumentList()
 
 	// Emit the force flag as a constant (true if '!' was used, false otherwise)
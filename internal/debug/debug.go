@@ -2,6 +2,7 @@ package debug
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cryptrunner49/zscript/internal/runtime"
 )
@@ -43,6 +44,8 @@ func DisassembleInstruction(ch *runtime.Chunk, offset int) int {
 		return byteInstruction("OP_GET_LOCAL", ch, offset)
 	case uint8(runtime.OP_DEFINE_GLOBAL):
 		return constantInstruction("OP_DEFINE_GLOBAL", ch, offset)
+	case uint8(runtime.OP_DEFINE_CONST):
+		return constantInstruction("OP_DEFINE_CONST", ch, offset)
 	case uint8(runtime.OP_SET_GLOBAL):
 		return constantInstruction("OP_SET_GLOBAL", ch, offset)
 	case uint8(runtime.OP_GET_GLOBAL):
@@ -107,6 +110,8 @@ func DisassembleInstruction(ch *runtime.Chunk, offset int) int {
 		return jumpInstruction("OP_JUMP_IF_FALSE", 1, ch, offset)
 	case uint8(runtime.OP_JUMP_IF_TRUE):
 		return jumpInstruction("OP_JUMP_IF_TRUE", 1, ch, offset)
+	case uint8(runtime.OP_JUMP_IF_NULL):
+		return jumpInstruction("OP_JUMP_IF_NULL", 1, ch, offset)
 	case uint8(runtime.OP_LOOP):
 		return jumpInstruction("OP_LOOP", -1, ch, offset)
 	case uint8(runtime.OP_BREAK):
@@ -115,6 +120,42 @@ func DisassembleInstruction(ch *runtime.Chunk, offset int) int {
 		return jumpInstruction("OP_CONTINUE", 1, ch, offset)
 	case uint8(runtime.OP_STRUCT):
 		return structInstruction(ch, offset)
+	case uint8(runtime.OP_ENUM):
+		return enumInstruction(ch, offset)
+	case uint8(runtime.OP_GET_VARIANT):
+		return constantInstruction("OP_GET_VARIANT", ch, offset)
+	case uint8(runtime.OP_TRY):
+		return jumpInstruction("OP_TRY", 1, ch, offset)
+	case uint8(runtime.OP_END_TRY):
+		return simpleInstruction("OP_END_TRY", offset)
+	case uint8(runtime.OP_THROW):
+		return simpleInstruction("OP_THROW", offset)
+	case uint8(runtime.OP_INVOKE):
+		return invokeInstruction("OP_INVOKE", ch, offset)
+	case uint8(runtime.OP_INHERIT):
+		return simpleInstruction("OP_INHERIT", offset)
+	case uint8(runtime.OP_GET_SUPER):
+		return constantInstruction("OP_GET_SUPER", ch, offset)
+	case uint8(runtime.OP_SUPER_INVOKE):
+		return invokeInstruction("OP_SUPER_INVOKE", ch, offset)
+	case uint8(runtime.OP_NEW):
+		return simpleInstruction("OP_NEW", offset)
+	case uint8(runtime.OP_DEFAULT_ARG):
+		return defaultArgInstruction(ch, offset)
+	case uint8(runtime.OP_ARGUMENT_NAMES):
+		return argumentNamesInstruction(ch, offset)
+	case uint8(runtime.OP_RANGE):
+		return byteInstruction("OP_RANGE", ch, offset)
+	case uint8(runtime.OP_IN):
+		return simpleInstruction("OP_IN", offset)
+	case uint8(runtime.OP_YIELD):
+		return simpleInstruction("OP_YIELD", offset)
+	case uint8(runtime.OP_INTERPOLATE):
+		return byteInstruction("OP_INTERPOLATE", ch, offset)
+	case uint8(runtime.OP_ITERATOR):
+		return byteInstruction("OP_ITERATOR", ch, offset)
+	case uint8(runtime.OP_UNPACK):
+		return unpackInstruction(ch, offset)
 	case uint8(runtime.OP_INSTANCE):
 		return byteInstruction("OP_INSTANCE", ch, offset)
 	case uint8(runtime.OP_GET_VALUE):
@@ -135,6 +176,10 @@ func DisassembleInstruction(ch *runtime.Chunk, offset int) int {
 		return constantInstruction("OP_MODULE", ch, offset)
 	case uint8(runtime.OP_IMPORT):
 		return constantInstruction("OP_IMPORT", ch, offset)
+	case uint8(runtime.OP_IMPORT_ALL):
+		return simpleInstruction("OP_IMPORT_ALL", offset)
+	case uint8(runtime.OP_NAMESPACE):
+		return simpleInstruction("OP_NAMESPACE", offset)
 	case uint8(runtime.OP_USE):
 		return constantInstruction("OP_USE", ch, offset)
 	case uint8(runtime.OP_DEFINE_EXTERN):
@@ -160,15 +205,31 @@ func DisassembleInstruction(ch *runtime.Chunk, offset int) int {
 		fmt.Println("'")
 		return offset + 1
 	case uint8(runtime.OP_MATCH):
-		return simpleInstruction("OP_MATCH", offset)
+		return matchInstruction(ch, offset)
+	case uint8(runtime.OP_SWITCH):
+		return switchInstruction(ch, offset)
 	case uint8(runtime.OP_DUP):
 		return simpleInstruction("OP_DUP", offset)
+	case uint8(runtime.OP_DUP_TWO):
+		return simpleInstruction("OP_DUP_TWO", offset)
 	case uint8(runtime.OP_EXPONENTIAL):
 		return simpleInstruction("OP_EXPONENTIAL", offset)
 	case uint8(runtime.OP_FLOOR):
 		return simpleInstruction("OP_FLOOR", offset)
 	case uint8(runtime.OP_PERCENT):
 		return simpleInstruction("OP_PERCENT", offset)
+	case uint8(runtime.OP_BIT_AND):
+		return simpleInstruction("OP_BIT_AND", offset)
+	case uint8(runtime.OP_BIT_OR):
+		return simpleInstruction("OP_BIT_OR", offset)
+	case uint8(runtime.OP_BIT_XOR):
+		return simpleInstruction("OP_BIT_XOR", offset)
+	case uint8(runtime.OP_BIT_NOT):
+		return simpleInstruction("OP_BIT_NOT", offset)
+	case uint8(runtime.OP_SHIFT_LEFT):
+		return simpleInstruction("OP_SHIFT_LEFT", offset)
+	case uint8(runtime.OP_SHIFT_RIGHT):
+		return simpleInstruction("OP_SHIFT_RIGHT", offset)
 	default:
 		fmt.Printf("Unknown opcode %d\n", instruction)
 		return offset + 1
@@ -192,6 +253,67 @@ func constantInstruction(name string, ch *runtime.Chunk, offset int) int {
 	return offset + 2
 }
 
+// invokeInstruction disassembles an instruction with a method name constant and an argument
+// count, returning the next offset.
+func invokeInstruction(name string, ch *runtime.Chunk, offset int) int {
+	constant := ch.Code()[offset+1]
+	argCount := ch.Code()[offset+2]
+	fmt.Printf("%-16s (%d args) %4d '", name, argCount, constant)
+	runtime.PrintValue(ch.Constants().Values()[constant])
+	fmt.Println("'")
+	return offset + 3
+}
+
+// defaultArgInstruction disassembles OP_DEFAULT_ARG, printing the parameter slot and the offset
+// reached when the call supplied the argument, and returning the next offset.
+func defaultArgInstruction(ch *runtime.Chunk, offset int) int {
+	slot := ch.Code()[offset+1]
+	jump := int(ch.Code()[offset+2])<<8 | int(ch.Code()[offset+3])
+	fmt.Printf("%-16s %4d %4d -> %d\n", "OP_DEFAULT_ARG", slot, offset, offset+4+jump)
+	return offset + 4
+}
+
+// argumentNamesInstruction disassembles OP_ARGUMENT_NAMES, printing the names of the keyword
+// arguments, and returning the next offset.
+func argumentNamesInstruction(ch *runtime.Chunk, offset int) int {
+	count := int(ch.Code()[offset+1])
+	fmt.Printf("%-16s %4d", "OP_ARGUMENT_NAMES", count)
+	for i := 0; i < count; i++ {
+		fmt.Print(" '")
+		runtime.PrintValue(ch.Constants().Values()[ch.Code()[offset+2+i]])
+		fmt.Print("'")
+	}
+	fmt.Println()
+	return offset + 2 + count
+}
+
+// unpackInstruction disassembles OP_UNPACK, printing the kind of destructuring, the number of
+// variables and the names they are taken from, and returning the next offset.
+func unpackInstruction(ch *runtime.Chunk, offset int) int {
+	kind := runtime.UnpackKind(ch.Code()[offset+1])
+	count := int(ch.Code()[offset+2])
+	rest := ""
+	if ch.Code()[offset+3] == 1 {
+		rest = " + rest"
+	}
+	switch kind {
+	case runtime.UNPACK_ARRAY:
+		fmt.Printf("%-16s array %d%s\n", "OP_UNPACK", count, rest)
+		return offset + 4
+	case runtime.UNPACK_MAP:
+		fmt.Printf("%-16s map %d%s", "OP_UNPACK", count, rest)
+	default:
+		fmt.Printf("%-16s instance %d", "OP_UNPACK", count)
+	}
+	for i := 0; i < count; i++ {
+		fmt.Print(" '")
+		runtime.PrintValue(ch.Constants().Values()[ch.Code()[offset+4+i]])
+		fmt.Print("'")
+	}
+	fmt.Println()
+	return offset + 4 + count
+}
+
 // byteInstruction disassembles an instruction with a single byte operand, printing the opcode
 // name and operand value, and returning the next offset.
 func byteInstruction(name string, ch *runtime.Chunk, offset int) int {
@@ -209,7 +331,8 @@ func jumpInstruction(name string, sign int, ch *runtime.Chunk, offset int) int {
 }
 
 // structInstruction disassembles the OP_STRUCT opcode, printing the struct name constant, field
-// count, and each field’s name and default value constants, and returning the next offset.
+// count and field name constants, whether it has an initializer, and its method names, and
+// returning the next offset.
 func structInstruction(ch *runtime.Chunk, offset int) int {
 	// Read the struct name constant.
 	constant := ch.Code()[offset+1]
@@ -221,20 +344,99 @@ func structInstruction(ch *runtime.Chunk, offset int) int {
 	fmt.Printf("          field count: %d\n", fieldCount)
 	// Advance past opcode, struct name, and field count.
 	offset += 3
-	// For each field, print the field name and its default value.
+	// For each field, print the field name.
 	for i := 0; i < fieldCount; i++ {
-		// Field name constant.
 		nameConstant := ch.Code()[offset]
 		fmt.Printf("%04d      | field name constant %d: '", offset, nameConstant)
 		runtime.PrintValue(ch.Constants().Values()[nameConstant])
 		fmt.Println("'")
 		offset++
-		// Field default value constant.
-		defConstant := ch.Code()[offset]
-		fmt.Printf("%04d      | field default constant %d: '", offset, defConstant)
-		runtime.PrintValue(ch.Constants().Values()[defConstant])
+	}
+	// Whether the field initializer closure sits on top of the method closures.
+	fmt.Printf("%04d      | has initializer: %t\n", offset, ch.Code()[offset] != 0)
+	offset++
+	// Read the method count and each method name constant.
+	methodCount := int(ch.Code()[offset])
+	fmt.Printf("%04d      | method count: %d\n", offset, methodCount)
+	offset++
+	for i := 0; i < methodCount; i++ {
+		nameConstant := ch.Code()[offset]
+		fmt.Printf("%04d      | method name constant %d: '", offset, nameConstant)
+		runtime.PrintValue(ch.Constants().Values()[nameConstant])
 		fmt.Println("'")
 		offset++
 	}
 	return offset
 }
+
+// enumInstruction disassembles the OP_ENUM opcode, printing the enum name constant and each
+// variant with its payload field names, and returning the next offset.
+func enumInstruction(ch *runtime.Chunk, offset int) int {
+	constant := ch.Code()[offset+1]
+	fmt.Printf("%-16s %4d '", "OP_ENUM", constant)
+	runtime.PrintValue(ch.Constants().Values()[constant])
+	fmt.Println("'")
+	variantCount := int(ch.Code()[offset+2])
+	fmt.Printf("          variant count: %d\n", variantCount)
+	offset += 3
+	for i := 0; i < variantCount; i++ {
+		nameConstant := ch.Code()[offset]
+		fieldCount := int(ch.Code()[offset+1])
+		fmt.Printf("%04d      | variant '", offset)
+		runtime.PrintValue(ch.Constants().Values()[nameConstant])
+		fmt.Printf("' with %d payload fields\n", fieldCount)
+		offset += 2 + fieldCount
+	}
+	return offset
+}
+
+// matchInstruction disassembles the OP_MATCH opcode, printing the kind of structural test and its
+// operand, and returning the next offset.
+func matchInstruction(ch *runtime.Chunk, offset int) int {
+	kind := runtime.MatchKind(ch.Code()[offset+1])
+	operand := ch.Code()[offset+2]
+	switch kind {
+	case runtime.MATCH_ARRAY:
+		fmt.Printf("%-16s array of %d\n", "OP_MATCH", operand)
+	case runtime.MATCH_INSTANCE:
+		fmt.Printf("%-16s instance\n", "OP_MATCH")
+	case runtime.MATCH_VARIANT:
+		fmt.Printf("%-16s variant %d '", "OP_MATCH", operand)
+		runtime.PrintValue(ch.Constants().Values()[operand])
+		fmt.Println("'")
+	case runtime.MATCH_PAYLOAD:
+		fmt.Printf("%-16s payload of %d\n", "OP_MATCH", operand)
+	default:
+		fmt.Printf("%-16s unknown kind %d\n", "OP_MATCH", kind)
+	}
+	return offset + 3
+}
+
+// switchInstruction disassembles the OP_SWITCH opcode, printing its jump table ordered by target
+// address, and returning the next offset.
+func switchInstruction(ch *runtime.Chunk, offset int) int {
+	index := ch.Code()[offset+1]
+	table := ch.SwitchTables()[index]
+	fmt.Printf("%-16s %4d\n", "OP_SWITCH", index)
+
+	type switchCase struct {
+		label   string
+		address int
+	}
+	cases := make([]switchCase, 0, len(table.Numbers)+len(table.Ints)+len(table.Strings))
+	for number, address := range table.Numbers {
+		cases = append(cases, switchCase{fmt.Sprintf("%g", number), address})
+	}
+	for number, address := range table.Ints {
+		cases = append(cases, switchCase{fmt.Sprintf("%d", number), address})
+	}
+	for str, address := range table.Strings {
+		cases = append(cases, switchCase{fmt.Sprintf("%q", str), address})
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].address < cases[j].address })
+	for _, c := range cases {
+		fmt.Printf("%04d      | case %s -> %d\n", offset, c.label, c.address)
+	}
+	fmt.Printf("%04d      | default -> %d\n", offset, table.Default)
+	return offset + 2
+}
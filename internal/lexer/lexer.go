@@ -2,6 +2,7 @@ package lexer
 
 import (
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -18,7 +19,8 @@ type Lexer struct {
 	pendingIndents int
 	pendingDedents int
 	atLineStart    bool
-	braceNesting   int // Tracks nesting level of brace-delimited contexts
+	braceNesting   int             // Tracks nesting level of brace-delimited contexts
+	interpolations []interpolation // The '${' expressions being scanned, innermost last
 }
 
 var lexer Lexer
@@ -78,6 +80,11 @@ func ScanToken() token.Token {
 		return lexer.number()
 	}
 
+	if r == 'r' && lexer.peek() == '"' {
+		lexer.advance()
+		return lexer.string(true)
+	}
+
 	// Parse and return an identifier or keyword if the current rune
 	// is not an operator, whitespace.
 	if !isOperatorRune(r) && !unicode.IsSpace(r) {
@@ -93,6 +100,13 @@ func ScanToken() token.Token {
 		lexer.braceNesting++ // Entering a brace-delimited context
 		return lexer.makeToken(token.TOKEN_LEFT_BRACE)
 	case '}':
+		if n := len(lexer.interpolations); n > 0 && lexer.interpolations[n-1].braceNesting == lexer.braceNesting {
+			// The end of an interpolated expression: the string carries on after it.
+			kind := lexer.interpolations[n-1].kind
+			lexer.interpolations = lexer.interpolations[:n-1]
+			lexer.braceNesting--
+			return lexer.stringPart(kind)
+		}
 		lexer.braceNesting-- // Exiting a brace-delimited context
 		if lexer.braceNesting < 0 {
 			return lexer.errorToken("Unmatched closing brace '}'.")
@@ -107,38 +121,51 @@ func ScanToken() token.Token {
 	case ',':
 		return lexer.makeToken(token.TOKEN_COMMA)
 	case '.':
+		if lexer.match('.') {
+			if lexer.match('.') {
+				return lexer.makeToken(token.TOKEN_DOT_DOT_DOT)
+			} else if lexer.match('=') {
+				return lexer.makeToken(token.TOKEN_DOT_DOT_EQUAL)
+			}
+			return lexer.makeToken(token.TOKEN_DOT_DOT)
+		}
 		return lexer.makeToken(token.TOKEN_DOT)
 	case '-':
 		if lexer.match('-') {
 			return lexer.makeToken(token.TOKEN_MINUS_MINUS)
 		} else if lexer.match('=') {
-			return lexer.errorToken("Compound assignment operator '-=' is not supported.")
+			return lexer.makeToken(token.TOKEN_MINUS_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_MINUS)
 	case '+':
 		if lexer.match('+') {
 			return lexer.makeToken(token.TOKEN_PLUS_PLUS)
 		} else if lexer.match('=') {
-			return lexer.errorToken("Compound assignment operator '+=' is not supported.")
+			return lexer.makeToken(token.TOKEN_PLUS_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_PLUS)
 	case '*':
 		if lexer.match('*') {
+			if lexer.match('=') {
+				return lexer.makeToken(token.TOKEN_STAR_STAR_EQUAL)
+			}
 			return lexer.makeToken(token.TOKEN_STAR_STAR)
 		} else if lexer.match('=') {
-			return lexer.errorToken("Compound assignment operator '*=' is not supported.")
+			return lexer.makeToken(token.TOKEN_STAR_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_STAR)
 	case '/':
 		if lexer.match('_') {
 			return lexer.makeToken(token.TOKEN_FLOOR)
 		} else if lexer.match('=') {
-			return lexer.errorToken("Compound assignment operator '/=' is not supported.")
+			return lexer.makeToken(token.TOKEN_SLASH_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_SLASH)
 	case '%':
 		if lexer.match('%') {
 			return lexer.makeToken(token.TOKEN_PERCENT_PERCENT)
+		} else if lexer.match('=') {
+			return lexer.makeToken(token.TOKEN_PERCENT_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_PERCENT)
 	case '!':
@@ -152,22 +179,54 @@ func ScanToken() token.Token {
 		}
 		return lexer.makeToken(token.TOKEN_EQUAL)
 	case '<':
-		if lexer.match('=') {
+		if lexer.match('<') {
+			if lexer.match('=') {
+				return lexer.makeToken(token.TOKEN_LESS_LESS_EQUAL)
+			}
+			return lexer.makeToken(token.TOKEN_LESS_LESS)
+		} else if lexer.match('=') {
 			return lexer.makeToken(token.TOKEN_LESS_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_LESS)
 	case '>':
-		if lexer.match('=') {
+		if lexer.match('>') {
+			if lexer.match('=') {
+				return lexer.makeToken(token.TOKEN_GREATER_GREATER_EQUAL)
+			}
+			return lexer.makeToken(token.TOKEN_GREATER_GREATER)
+		} else if lexer.match('=') {
 			return lexer.makeToken(token.TOKEN_GREATER_EQUAL)
 		}
 		return lexer.makeToken(token.TOKEN_GREATER)
 	case '"':
-		return lexer.string()
+		return lexer.string(false)
 	case '\'':
 		return lexer.char()
 	case '|':
+		if lexer.match('=') {
+			return lexer.makeToken(token.TOKEN_PIPE_EQUAL)
+		}
 		return lexer.makeToken(token.TOKEN_PIPE)
+	case '&':
+		if lexer.match('=') {
+			return lexer.makeToken(token.TOKEN_AMPERSAND_EQUAL)
+		}
+		return lexer.makeToken(token.TOKEN_AMPERSAND)
+	case '^':
+		if lexer.match('=') {
+			return lexer.makeToken(token.TOKEN_CARET_EQUAL)
+		}
+		return lexer.makeToken(token.TOKEN_CARET)
+	case '~':
+		return lexer.makeToken(token.TOKEN_TILDE)
 	case '?':
+		if lexer.match('?') {
+			return lexer.makeToken(token.TOKEN_QUESTION_QUESTION)
+		} else if lexer.match('.') {
+			return lexer.makeToken(token.TOKEN_QUESTION_DOT)
+		} else if lexer.match('[') {
+			return lexer.makeToken(token.TOKEN_QUESTION_BRACKET)
+		}
 		return lexer.makeToken(token.TOKEN_QUESTION)
 	case '@':
 		return lexer.makeToken(token.TOKEN_AT)
@@ -176,12 +235,41 @@ func ScanToken() token.Token {
 	case '$':
 		return lexer.makeToken(token.TOKEN_DOLLAR)
 	case ':':
+		if lexer.match(':') {
+			return lexer.makeToken(token.TOKEN_COLON_COLON)
+		}
 		return lexer.makeToken(token.TOKEN_COLON)
 	}
 
 	return lexer.errorToken("Unexpected character.")
 }
 
+// State is a saved position of the lexer, returned by Save and restored by Restore.
+type State struct {
+	lexer Lexer
+}
+
+// Save records the current position of the lexer so tokens can be scanned ahead and given back.
+func Save() State {
+	saved := lexer
+	saved.indents = append([]int(nil), lexer.indents...)
+	saved.interpolations = append([]interpolation(nil), lexer.interpolations...)
+	return State{lexer: saved}
+}
+
+// Restore rewinds the lexer to a position recorded by Save.
+func Restore(state State) {
+	lexer = state.lexer
+}
+
+// PeekToken returns the token after the most recently scanned one without consuming it.
+func PeekToken() token.Token {
+	state := Save()
+	next := ScanToken()
+	Restore(state)
+	return next
+}
+
 func (l *Lexer) isAtEnd() bool {
 	return l.current >= len(l.source)
 }
@@ -288,20 +376,6 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func (l *Lexer) string() token.Token {
-	for l.peek() != '"' && !l.isAtEnd() {
-		if l.peek() == '\n' {
-			l.line++
-		}
-		l.advance()
-	}
-	if l.isAtEnd() {
-		return l.errorToken("Unterminated string.")
-	}
-	l.advance() // Closing quote
-	return l.makeToken(token.TOKEN_STRING)
-}
-
 func (l *Lexer) char() token.Token {
 	// If we reached the end, we have an error.
 	if l.isAtEnd() {
@@ -311,14 +385,17 @@ func (l *Lexer) char() token.Token {
 	// Read the character value (supporting escape sequences if your language allows them)
 	var value []rune
 
-	// Handle escape sequences (e.g., '\n') by consuming the backslash and the following character.
+	// Decode escape sequences (e.g., '\n') the same way string literals do.
 	if l.peek() == '\\' {
 		l.advance() // Consume the backslash
 		if l.isAtEnd() {
 			return l.errorToken("Unterminated escape sequence in character literal.")
 		}
-		// Consume escaped character (this is simplistic, adapt if you support more escapes)
-		value = append(value, rune(l.advance()))
+		decoded, problem := l.escape()
+		if problem != "" {
+			return l.errorToken(problem)
+		}
+		value = []rune(decoded)
 	} else {
 		// Normal character
 		value = append(value, rune(l.advance()))
@@ -343,11 +420,19 @@ func (l *Lexer) char() token.Token {
 	}
 	l.advance() // Consume the closing quote
 
-	// Assuming that l.makeToken takes the token type and uses the text from start to the current position.
-	return l.makeToken(token.TOKEN_CHAR)
+	return l.stringToken(token.TOKEN_CHAR, string(value))
 }
 
 func (l *Lexer) number() token.Token {
+	// Integers written in hexadecimal, octal or binary; the compiler checks the digits.
+	if l.source[l.start] == '0' && strings.ContainsRune("xXoObB", l.peek()) && isHexDigit(l.peekNext()) {
+		l.advance()
+		for isHexDigit(l.peek()) {
+			l.advance()
+		}
+		l.bigIntSuffix()
+		return l.makeToken(token.TOKEN_NUMBER)
+	}
 	for unicode.IsDigit(l.peek()) {
 		l.advance()
 	}
@@ -357,9 +442,18 @@ func (l *Lexer) number() token.Token {
 			l.advance()
 		}
 	}
+	l.bigIntSuffix()
 	return l.makeToken(token.TOKEN_NUMBER)
 }
 
+// bigIntSuffix consumes the 'n' ending a BigInt literal such as '12n', unless it starts a name.
+func (l *Lexer) bigIntSuffix() {
+	next := l.peekNext()
+	if l.peek() == 'n' && (next == 0 || unicode.IsSpace(next) || isOperatorRune(next)) {
+		l.advance()
+	}
+}
+
 func (l *Lexer) identifier() token.Token {
 	for {
 		r := l.peek()
@@ -371,9 +465,13 @@ func (l *Lexer) identifier() token.Token {
 	return l.makeToken(l.identifierType())
 }
 
+func isHexDigit(r rune) bool {
+	return strings.ContainsRune("0123456789abcdefABCDEF", r)
+}
+
 func isOperatorRune(r rune) bool {
 	switch r {
-	case '(', ')', '{', '}', '[', ']', '|', ':', '?', ';', ',', '.', '-', '+', '/', '%', '@', '#', '$', '*', '!', '=', '<', '>', '"', '\'':
+	case '(', ')', '{', '}', '[', ']', '|', '&', '^', '~', ':', '?', ';', ',', '.', '-', '+', '/', '%', '@', '#', '$', '*', '!', '=', '<', '>', '"', '\'':
 		return true
 	default:
 		return false
@@ -401,14 +499,22 @@ func (l *Lexer) identifierType() token.TokenType {
 		return token.TOKEN_OR
 	case "return":
 		return token.TOKEN_RETURN
+	case "yield":
+		return token.TOKEN_YIELD
 	case "struct":
 		return token.TOKEN_STRUCT
+	case "enum":
+		return token.TOKEN_ENUM
 	case "this":
 		return token.TOKEN_THIS
+	case "super":
+		return token.TOKEN_SUPER
 	case "true":
 		return token.TOKEN_TRUE
 	case "var":
 		return token.TOKEN_VAR
+	case "const":
+		return token.TOKEN_CONST
 	case "while":
 		return token.TOKEN_WHILE
 	case "iter":
@@ -425,6 +531,14 @@ func (l *Lexer) identifierType() token.TokenType {
 		return token.TOKEN_WITH
 	case "through":
 		return token.TOKEN_THROUGH
+	case "try":
+		return token.TOKEN_TRY
+	case "catch":
+		return token.TOKEN_CATCH
+	case "finally":
+		return token.TOKEN_FINALLY
+	case "throw":
+		return token.TOKEN_THROW
 	case "import":
 		return token.TOKEN_IMPORT
 	case "export":
@@ -0,0 +1,223 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cryptrunner49/zscript/internal/token"
+)
+
+// stringKind describes the string literal being scanned, so that scanning can resume after an
+// interpolated expression.
+type stringKind struct {
+	raw    bool   // r"...": backslashes and '${' are taken literally.
+	triple bool   // """...""": spans lines and ends at the next '"""'.
+	indent string // Indentation stripped from every line of a triple-quoted string.
+}
+
+// interpolation is a '${' whose expression is being scanned.
+type interpolation struct {
+	braceNesting int        // Brace nesting inside the '${', which the closing '}' brings back.
+	kind         stringKind // The string the expression is embedded in.
+}
+
+// string scans a string literal after its opening quote, working out whether it is
+// triple-quoted. Raw strings start with an 'r' before the quote.
+func (l *Lexer) string(raw bool) token.Token {
+	kind := stringKind{raw: raw}
+	if l.peek() == '"' && l.peekNext() == '"' {
+		l.advance()
+		l.advance()
+		kind.triple = true
+		// A line break straight after the opening quotes is not part of the string.
+		if l.peek() == '\r' && l.peekNext() == '\n' {
+			l.advance()
+		}
+		if l.peek() == '\n' {
+			l.advance()
+			l.line++
+		}
+		kind.indent = l.commonIndent()
+		l.skipIndent(kind.indent)
+	}
+	return l.stringPart(kind)
+}
+
+// stringPart scans the text of a string literal up to its closing quote, or up to the next
+// interpolated expression. The decoded text becomes the token's Literal. A part ending in '${' is
+// a TOKEN_INTERPOLATION; the expression's tokens follow it, and the '}' closing the expression
+// resumes the string. '${{' is the escape for a literal '${'.
+func (l *Lexer) stringPart(kind stringKind) token.Token {
+	var sb strings.Builder
+	var problem string
+	for !l.isAtEnd() {
+		r := l.peek()
+		if r == '"' && (!kind.triple || l.atTripleQuote()) {
+			break
+		}
+		switch {
+		case r == '\n' && kind.triple:
+			l.advance()
+			l.line++
+			// The line holding the closing quotes is not part of the string.
+			if l.closingLine() {
+				continue
+			}
+			sb.WriteRune('\n')
+			l.skipIndent(kind.indent)
+		case r == '\n':
+			l.advance()
+			l.line++
+			sb.WriteRune('\n')
+		case r == '\\' && !kind.raw:
+			l.advance()
+			decoded, err := l.escape()
+			if err != "" && problem == "" {
+				problem = err
+			}
+			sb.WriteString(decoded)
+		case r == '$' && l.peekNext() == '{' && !kind.raw:
+			l.advance()
+			l.advance()
+			if l.match('{') {
+				sb.WriteString("${")
+				continue
+			}
+			if problem != "" {
+				return l.errorToken(problem)
+			}
+			l.braceNesting++
+			l.interpolations = append(l.interpolations, interpolation{braceNesting: l.braceNesting, kind: kind})
+			return l.stringToken(token.TOKEN_INTERPOLATION, sb.String())
+		default:
+			sb.WriteRune(l.advance())
+		}
+	}
+	if l.isAtEnd() {
+		return l.errorToken("Unterminated string.")
+	}
+	l.advance() // Closing quote
+	if kind.triple {
+		l.advance()
+		l.advance()
+	}
+	if problem != "" {
+		return l.errorToken(problem)
+	}
+	return l.stringToken(token.TOKEN_STRING, sb.String())
+}
+
+// stringToken makes a string token carrying the decoded text of the literal.
+func (l *Lexer) stringToken(typ token.TokenType, literal string) token.Token {
+	tok := l.makeToken(typ)
+	tok.Literal = literal
+	return tok
+}
+
+// escape decodes the escape sequence after a backslash. An invalid sequence is kept as written
+// and reported through the returned message.
+func (l *Lexer) escape() (string, string) {
+	if l.isAtEnd() {
+		return "", "Unterminated escape sequence."
+	}
+	r := l.advance()
+	switch r {
+	case 'n':
+		return "\n", ""
+	case 't':
+		return "\t", ""
+	case 'r':
+		return "\r", ""
+	case '0':
+		return "\x00", ""
+	case '\\', '"', '\'', '$':
+		return string(r), ""
+	case '\n':
+		l.line++
+		return "\\\n", "Invalid escape sequence '\\' at the end of a line."
+	case 'x':
+		digits := l.hexDigits(2)
+		if len(digits) != 2 {
+			return "\\x" + digits, "Escape sequence '\\x' must be followed by exactly two hex digits."
+		}
+		code, _ := strconv.ParseUint(digits, 16, 8)
+		return string(rune(code)), ""
+	case 'u':
+		if !l.match('{') {
+			return "\\u", "Escape sequence '\\u' must be written '\\u{XXXX}' with 1 to 6 hex digits."
+		}
+		digits := l.hexDigits(6)
+		if !l.match('}') || len(digits) == 0 {
+			return "\\u{" + digits, "Escape sequence '\\u' must be written '\\u{XXXX}' with 1 to 6 hex digits."
+		}
+		code, _ := strconv.ParseUint(digits, 16, 32)
+		if !utf8.ValidRune(rune(code)) {
+			return "\\u{" + digits + "}", fmt.Sprintf("Escape sequence '\\u{%s}' is not a valid Unicode character.", digits)
+		}
+		return string(rune(code)), ""
+	}
+	return "\\" + string(r), fmt.Sprintf("Invalid escape sequence '\\%c'.", r)
+}
+
+// hexDigits consumes up to limit hexadecimal digits and returns them.
+func (l *Lexer) hexDigits(limit int) string {
+	start := l.current
+	for l.current-start < limit && strings.ContainsRune("0123456789abcdefABCDEF", l.peek()) && !l.isAtEnd() {
+		l.advance()
+	}
+	return l.source[start:l.current]
+}
+
+// atTripleQuote reports whether the lexer is at the '"""' closing a triple-quoted string.
+func (l *Lexer) atTripleQuote() bool {
+	return strings.HasPrefix(l.source[l.current:], `"""`)
+}
+
+// closingLine skips the rest of the line when it holds nothing but whitespace before the closing
+// quotes of a triple-quoted string, and reports whether it did.
+func (l *Lexer) closingLine() bool {
+	rest := strings.TrimLeft(l.source[l.current:], " \t")
+	if !strings.HasPrefix(rest, `"""`) {
+		return false
+	}
+	l.current = len(l.source) - len(rest)
+	return true
+}
+
+// commonIndent returns the indentation shared by the lines of the triple-quoted string starting at
+// the current position: the longest run of spaces and tabs every line starts with, so a tab and a
+// space are never taken for the same column. Blank lines and the line of the closing quotes do not
+// count.
+func (l *Lexer) commonIndent() string {
+	text := l.source[l.current:]
+	if end := strings.Index(text, `"""`); end >= 0 {
+		text = text[:end]
+	}
+	lines := strings.Split(text, "\n")
+	indent, first := "", true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prefix := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			indent, first = prefix, false
+			continue
+		}
+		n := 0
+		for n < len(indent) && n < len(prefix) && indent[n] == prefix[n] {
+			n++
+		}
+		indent = indent[:n]
+	}
+	return indent
+}
+
+// skipIndent skips as much of the given indentation as a line of a triple-quoted string starts with.
+func (l *Lexer) skipIndent(indent string) {
+	for i := 0; i < len(indent) && l.peek() == rune(indent[i]); i++ {
+		l.advance()
+	}
+}
@@ -0,0 +1,306 @@
+package runtime
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	str "strings"
+)
+
+// ObjBigInt is an integer of any size, written with an 'n' suffix ('12n') or made by bigint().
+type ObjBigInt struct {
+	Obj
+	Value *big.Int
+}
+
+// NewBigInt creates a BigInt holding value, which it takes ownership of.
+func NewBigInt(value *big.Int) *ObjBigInt {
+	return &ObjBigInt{
+		Obj:   Obj{Type: OBJ_BIGINT},
+		Value: value,
+	}
+}
+
+// String formats the BigInt in decimal digits.
+func (b *ObjBigInt) String() string {
+	return b.Value.String()
+}
+
+// ObjDecimal is an exact decimal number, Coefficient / 10^Scale. The scale is never negative and
+// is the number of digits printed after the decimal point, so 1.10 keeps its trailing zero.
+type ObjDecimal struct {
+	Obj
+	Coefficient *big.Int
+	Scale       int
+}
+
+// NewDecimal creates a Decimal worth coefficient / 10^scale, taking ownership of coefficient.
+func NewDecimal(coefficient *big.Int, scale int) *ObjDecimal {
+	return &ObjDecimal{
+		Obj:         Obj{Type: OBJ_DECIMAL},
+		Coefficient: coefficient,
+		Scale:       scale,
+	}
+}
+
+// String formats the Decimal with exactly Scale digits after the decimal point.
+func (d *ObjDecimal) String() string {
+	digits := new(big.Int).Abs(d.Coefficient).String()
+	sign := ""
+	if d.Coefficient.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = str.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// RoundingMode is how a Decimal is rounded when digits after the decimal point are dropped.
+type RoundingMode int
+
+const (
+	ROUND_HALF_EVEN RoundingMode = iota // To the nearest, ties to the even neighbour.
+	ROUND_HALF_UP                       // To the nearest, ties away from zero.
+	ROUND_HALF_DOWN                     // To the nearest, ties towards zero.
+	ROUND_UP                            // Away from zero.
+	ROUND_DOWN                          // Towards zero.
+	ROUND_CEILING                       // Towards positive infinity.
+	ROUND_FLOOR                         // Towards negative infinity.
+)
+
+// RoundingModes are the names of the rounding modes, indexed by mode.
+var RoundingModes = []string{"half_even", "half_up", "half_down", "up", "down", "ceiling", "floor"}
+
+// powerOfTen returns 10^n.
+func powerOfTen(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// divideRounded divides num by den, which must not be zero, rounding the quotient with mode.
+func divideRounded(num, den *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	// The sign of the exact quotient, and how the dropped remainder compares with half the divisor.
+	sign := num.Sign() * den.Sign()
+	half := new(big.Int).Abs(remainder)
+	half.Lsh(half, 1)
+	toHalf := half.Cmp(new(big.Int).Abs(den))
+	away := false
+	switch mode {
+	case ROUND_HALF_EVEN:
+		away = toHalf > 0 || (toHalf == 0 && quotient.Bit(0) == 1)
+	case ROUND_HALF_UP:
+		away = toHalf >= 0
+	case ROUND_HALF_DOWN:
+		away = toHalf > 0
+	case ROUND_UP:
+		away = true
+	case ROUND_CEILING:
+		away = sign > 0
+	case ROUND_FLOOR:
+		away = sign < 0
+	}
+	if away {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient
+}
+
+// Round returns the Decimal with scale digits after the decimal point, rounded with mode when
+// digits are dropped.
+func (d *ObjDecimal) Round(scale int, mode RoundingMode) *ObjDecimal {
+	if scale >= d.Scale {
+		return NewDecimal(new(big.Int).Mul(d.Coefficient, powerOfTen(scale-d.Scale)), scale)
+	}
+	return NewDecimal(divideRounded(d.Coefficient, powerOfTen(d.Scale-scale), mode), scale)
+}
+
+// aligned returns the coefficients of d and e brought to the larger of their scales.
+func (d *ObjDecimal) aligned(e *ObjDecimal) (*big.Int, *big.Int, int) {
+	scale := max(d.Scale, e.Scale)
+	return d.Round(scale, ROUND_DOWN).Coefficient, e.Round(scale, ROUND_DOWN).Coefficient, scale
+}
+
+// Add returns d + e, with the larger of their scales.
+func (d *ObjDecimal) Add(e *ObjDecimal) *ObjDecimal {
+	a, b, scale := d.aligned(e)
+	return NewDecimal(a.Add(a, b), scale)
+}
+
+// Sub returns d - e, with the larger of their scales.
+func (d *ObjDecimal) Sub(e *ObjDecimal) *ObjDecimal {
+	a, b, scale := d.aligned(e)
+	return NewDecimal(a.Sub(a, b), scale)
+}
+
+// Mul returns d * e, exactly.
+func (d *ObjDecimal) Mul(e *ObjDecimal) *ObjDecimal {
+	return NewDecimal(new(big.Int).Mul(d.Coefficient, e.Coefficient), d.Scale+e.Scale)
+}
+
+// Quo returns d / e rounded to scale digits after the decimal point, then drops the trailing
+// zeros beyond the scales of d and e. e must not be zero.
+func (d *ObjDecimal) Quo(e *ObjDecimal, scale int, mode RoundingMode) *ObjDecimal {
+	num := new(big.Int).Mul(d.Coefficient, powerOfTen(e.Scale+scale))
+	den := new(big.Int).Mul(e.Coefficient, powerOfTen(d.Scale))
+	quotient := NewDecimal(divideRounded(num, den, mode), scale)
+	return quotient.trimmed(max(d.Scale, e.Scale))
+}
+
+// Rem returns the remainder of d / e with the quotient truncated, so it takes the sign of d. e
+// must not be zero.
+func (d *ObjDecimal) Rem(e *ObjDecimal) *ObjDecimal {
+	a, b, scale := d.aligned(e)
+	return NewDecimal(a.Rem(a, b), scale)
+}
+
+// FloorQuo returns d / e rounded down to an integer, as a Decimal. e must not be zero.
+func (d *ObjDecimal) FloorQuo(e *ObjDecimal) *ObjDecimal {
+	a, b, _ := d.aligned(e)
+	return NewDecimal(divideRounded(a, b, ROUND_FLOOR), 0)
+}
+
+// Pow returns d raised to a non-negative integer power, exactly.
+func (d *ObjDecimal) Pow(exponent int64) *ObjDecimal {
+	power := new(big.Int).Exp(d.Coefficient, big.NewInt(exponent), nil)
+	return NewDecimal(power, d.Scale*int(exponent))
+}
+
+// Percent returns d percent of e, d * e / 100, exactly.
+func (d *ObjDecimal) Percent(e *ObjDecimal) *ObjDecimal {
+	product := d.Mul(e)
+	return NewDecimal(product.Coefficient, product.Scale+2).trimmed(product.Scale)
+}
+
+// Neg returns -d.
+func (d *ObjDecimal) Neg() *ObjDecimal {
+	return NewDecimal(new(big.Int).Neg(d.Coefficient), d.Scale)
+}
+
+// IsZero reports whether d is zero.
+func (d *ObjDecimal) IsZero() bool {
+	return d.Coefficient.Sign() == 0
+}
+
+// trimmed drops trailing zeros after the decimal point, keeping at least minScale digits.
+func (d *ObjDecimal) trimmed(minScale int) *ObjDecimal {
+	coefficient, scale := new(big.Int).Set(d.Coefficient), d.Scale
+	ten, digit := big.NewInt(10), new(big.Int)
+	for scale > minScale {
+		quotient, _ := new(big.Int).QuoRem(coefficient, ten, digit)
+		if digit.Sign() != 0 {
+			break
+		}
+		coefficient, scale = quotient, scale-1
+	}
+	return NewDecimal(coefficient, scale)
+}
+
+// Integer returns the integer part of d, truncating towards zero, and whether d had no fraction.
+func (d *ObjDecimal) Integer() (*big.Int, bool) {
+	quotient, remainder := new(big.Int).QuoRem(d.Coefficient, powerOfTen(d.Scale), new(big.Int))
+	return quotient, remainder.Sign() == 0
+}
+
+// Rat returns the exact value of d as a fraction.
+func (d *ObjDecimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Coefficient, powerOfTen(d.Scale))
+}
+
+// Float64 returns the float nearest to d.
+func (d *ObjDecimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// maxDecimalExponent bounds the exponent ParseDecimal accepts, which sets the number of digits
+// the Decimal needs.
+const maxDecimalExponent = 100000
+
+// ParseDecimal parses a decimal number such as "-12.50" or "1.5e3". The scale is the number of
+// digits written after the decimal point, adjusted by the exponent.
+func ParseDecimal(text string) (*ObjDecimal, bool) {
+	mantissa, exponent := text, 0
+	if i := str.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil || e > maxDecimalExponent || e < -maxDecimalExponent {
+			return nil, false
+		}
+		mantissa, exponent = text[:i], e
+	}
+	scale := 0
+	if point := str.IndexByte(mantissa, '.'); point >= 0 {
+		scale = len(mantissa) - point - 1
+		mantissa = mantissa[:point] + mantissa[point+1:]
+	}
+	digits := str.TrimLeft(mantissa, "+-")
+	if digits == "" || str.Trim(digits, "0123456789") != "" || len(mantissa)-len(digits) > 1 {
+		return nil, false
+	}
+	coefficient, _ := new(big.Int).SetString(mantissa, 10)
+	scale -= exponent
+	if scale < 0 {
+		return NewDecimal(coefficient.Mul(coefficient, powerOfTen(-scale)), 0), true
+	}
+	return NewDecimal(coefficient, scale), true
+}
+
+// ToDecimal converts an int, BigInt or Decimal to a Decimal. A float becomes the Decimal of its
+// shortest printed form, so 0.1 gives 0.1 rather than the binary value closest to it.
+func ToDecimal(v Value) (*ObjDecimal, bool) {
+	switch v.Type {
+	case VAL_INT:
+		return NewDecimal(big.NewInt(v.Int), 0), true
+	case VAL_NUMBER:
+		if math.IsNaN(v.Number) || math.IsInf(v.Number, 0) {
+			return nil, false
+		}
+		return ParseDecimal(strconv.FormatFloat(v.Number, 'f', -1, 64))
+	case VAL_OBJ:
+		switch obj := v.Obj.(type) {
+		case *ObjBigInt:
+			return NewDecimal(new(big.Int).Set(obj.Value), 0), true
+		case *ObjDecimal:
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// IsBigNumber reports whether a value is a BigInt or a Decimal.
+func IsBigNumber(v Value) bool {
+	switch v.Obj.(type) {
+	case *ObjBigInt, *ObjDecimal:
+		return true
+	}
+	return false
+}
+
+// ExactRat returns the exact value of a number of any kind as a fraction. NaN and the
+// infinities have none.
+func ExactRat(v Value) (*big.Rat, bool) {
+	switch v.Type {
+	case VAL_INT:
+		return new(big.Rat).SetInt64(v.Int), true
+	case VAL_NUMBER:
+		if math.IsNaN(v.Number) || math.IsInf(v.Number, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(v.Number), true
+	case VAL_OBJ:
+		switch obj := v.Obj.(type) {
+		case *ObjBigInt:
+			return new(big.Rat).SetInt(obj.Value), true
+		case *ObjDecimal:
+			return obj.Rat(), true
+		}
+	}
+	return nil, false
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"errors"
+	"math"
 )
 
 type Chunk struct {
@@ -10,6 +11,64 @@ type Chunk struct {
 	count     int
 	capacity  int
 	constants ValueArray
+	switches  []*SwitchTable
+}
+
+// SwitchTable maps the constant case values of a 'match ... through' statement to the bytecode
+// addresses of their bodies. It is the operand of OP_SWITCH.
+type SwitchTable struct {
+	Numbers map[float64]int // Case bodies keyed by the value of fractional numbers.
+	Ints    map[int64]int   // Case bodies keyed by integer value, whole floats included.
+	Strings map[string]int  // Case bodies keyed by string contents.
+	Default int             // Address used when no case matches.
+}
+
+// NewSwitchTable creates an empty switch table whose default is the given address.
+func NewSwitchTable(defaultAddress int) *SwitchTable {
+	return &SwitchTable{
+		Numbers: make(map[float64]int),
+		Ints:    make(map[int64]int),
+		Strings: make(map[string]int),
+		Default: defaultAddress,
+	}
+}
+
+// Lookup returns the address of the case body matching the value, or the default address.
+func (t *SwitchTable) Lookup(val Value) int {
+	if IsNumber(val) || IsBigNumber(val) {
+		if key, ok := SwitchIntKey(val); ok {
+			if address, ok := t.Ints[key]; ok {
+				return address
+			}
+		} else if address, ok := t.Numbers[val.Number]; ok {
+			return address
+		}
+	} else if str, ok := val.Obj.(*ObjString); ok {
+		if address, ok := t.Strings[str.Chars]; ok {
+			return address
+		}
+	}
+	return t.Default
+}
+
+// SwitchIntKey returns the key of a number in the Ints of a switch table: its value if it is an
+// integer or a float, BigInt or Decimal with a whole value, so that 1, 1.0 and 1n select the same
+// case.
+func SwitchIntKey(val Value) (int64, bool) {
+	if val.Type == VAL_INT {
+		return val.Int, true
+	}
+	if IsBigNumber(val) {
+		rat, _ := ExactRat(val)
+		if rat.IsInt() && rat.Num().IsInt64() {
+			return rat.Num().Int64(), true
+		}
+		return 0, false
+	}
+	if val.Number == math.Trunc(val.Number) && math.Abs(val.Number) < 1<<63 {
+		return int64(val.Number), true
+	}
+	return 0, false
 }
 
 func New() *Chunk {
@@ -24,6 +83,7 @@ func (c *Chunk) init() {
 	c.count = 0
 	c.capacity = 0
 	c.constants.Init()
+	c.switches = nil
 }
 
 func (c *Chunk) Write(byte uint8, line int) error {
@@ -43,6 +103,11 @@ func (c *Chunk) AddConstant(val Value) int {
 	return c.constants.Count() - 1
 }
 
+func (c *Chunk) AddSwitchTable(table *SwitchTable) int {
+	c.switches = append(c.switches, table)
+	return len(c.switches) - 1
+}
+
 func (c *Chunk) Free() {
 	c.constants.Free()
 	c.init()
@@ -64,6 +129,10 @@ func (c *Chunk) Constants() *ValueArray {
 	return &c.constants
 }
 
+func (c *Chunk) SwitchTables() []*SwitchTable {
+	return c.switches
+}
+
 func (c *Chunk) grow() error {
 	newCapacity := growCapacity(c.capacity)
 	if newCapacity < 0 {
@@ -3,6 +3,7 @@ package runtime
 import (
 	"fmt"
 	"hash/fnv"
+	"math"
 	"time"
 )
 
@@ -14,11 +15,14 @@ type ObjType int
 const (
 	OBJ_UPVALUE        ObjType = iota // Upvalue: a variable captured from an outer scope.
 	OBJ_CLOSURE                       // Closure: a function plus its captured environment.
+	OBJ_BOUND_METHOD                  // Bound Method: a struct method bound to an instance.
 	OBJ_FUNCTION                      // Function: a user-defined function.
 	OBJ_NATIVE                        // Native: a built-in (native) function.
 	OBJ_STRING                        // String: an immutable string.
 	OBJ_STRUCT                        // Struct: a user-defined struct type.
 	OBJ_INSTANCE                      // Instance: an instance of a struct.
+	OBJ_ENUM                          // Enum: a user-defined enum type.
+	OBJ_ENUM_VALUE                    // Enum Value: a variant of an enum, with optional payload.
 	OBJ_ARRAY                         // Array: a dynamic array.
 	OBJ_ARRAY_ITERATOR                // Array Iterator: iterator for arrays.
 	OBJ_MODULE                        // Module: a module containing functions, variables and other modules.
@@ -26,6 +30,11 @@ const (
 	OBJ_DATE                          // Date object (year, month, day)
 	OBJ_TIME                          // Time object (hour, minute, second)
 	OBJ_DATETIME                      // DateTime represents a combined date and time.
+	OBJ_ITERATOR                      // Iterator: a native iterator driven by done() and next().
+	OBJ_RANGE                         // Range: a lazy sequence of numbers.
+	OBJ_GENERATOR                     // Generator: a paused call of a function containing 'yield'.
+	OBJ_BIGINT                        // BigInt: an integer of any size.
+	OBJ_DECIMAL                       // Decimal: an exact decimal number.
 )
 
 // Obj is the header for all heap-allocated objects.
@@ -34,8 +43,9 @@ type Obj struct {
 	Next *Obj    // Linked list pointer for garbage collection.
 }
 
-// NativeFn is the function signature for native (built-in) functions.
-type NativeFn func(argCount int, args []Value) Value
+// NativeFn is the function signature for native (built-in) functions. A native that fails
+// returns a non-nil error, which the VM raises as a runtime error in place of the result.
+type NativeFn func(argCount int, args []Value) (Value, error)
 
 // ObjNative represents a native (built-in) function object.
 type ObjNative struct {
@@ -57,15 +67,23 @@ type ObjClosure struct {
 	Function     *ObjFunction  // The function object.
 	Upvalues     []*ObjUpvalue // Array of pointers to captured upvalues.
 	UpvalueCount int           // Number of upvalues captured.
+	Owner        *ObjStruct    // Struct declaring the method this closure is or is nested in.
 }
 
 // ObjFunction represents a user-defined function.
 type ObjFunction struct {
-	Obj          Obj        // Object header.
-	Arity        int        // Number of expected arguments.
-	UpvalueCount int        // Number of upvalues the function captures.
-	Chunk        Chunk      // Bytecode chunk containing the function's code.
-	Name         *ObjString // Optional function name.
+	Obj          Obj          // Object header.
+	Arity        int          // Number of positional parameters, including those with defaults.
+	Optional     int          // Number of trailing positional parameters with a default value.
+	Variadic     bool         // Whether a rest parameter collects the surplus arguments.
+	Params       []*ObjString // Names of the positional parameters, for keyword arguments.
+	IsGenerator  bool         // Whether the function contains 'yield', so calls return a generator.
+	UpvalueCount int          // Number of upvalues the function captures.
+	Chunk        Chunk        // Bytecode chunk containing the function's code.
+	Name         *ObjString   // Optional function name.
+	File         *ObjString   // Path of the script the function was compiled from.
+	Module       *ObjModule   // Namespace holding the globals of the imported file, or nil for the main script.
+	Inline       bool         // Whether the function is a match expression called in place; traces show it as its caller.
 }
 
 // ObjString represents an immutable string.
@@ -77,9 +95,20 @@ type ObjString struct {
 
 // ObjStruct represents a struct type with named fields and default values.
 type ObjStruct struct {
-	Obj    Obj
-	Name   *ObjString           // The name of the struct.
-	Fields map[*ObjString]Value // Map of field names to their default values.
+	Obj         Obj
+	Name        *ObjString                 // The name of the struct.
+	Fields      map[*ObjString]Value       // Map of field names to the values new instances start with.
+	Methods     map[*ObjString]*ObjClosure // Map of method names to their closures.
+	Parent      *ObjStruct                 // The struct this one inherits from, if any.
+	Initializer *ObjClosure                // Method evaluating the field defaults of each new instance, if any.
+}
+
+// ObjBoundMethod represents a struct method read from an instance, which the method receives as
+// 'this' when called.
+type ObjBoundMethod struct {
+	Obj      Obj
+	Receiver Value       // The instance the method was read from.
+	Method   *ObjClosure // The method's closure.
 }
 
 // ObjInstance represents an instance of a struct.
@@ -89,6 +118,25 @@ type ObjInstance struct {
 	Fields    map[*ObjString]Value // Instance field values.
 }
 
+// ObjEnum represents an enum type with its variants in declaration order.
+type ObjEnum struct {
+	Obj      Obj
+	Name     *ObjString                   // The name of the enum.
+	Variants []*ObjEnumValue              // Variants in declaration order.
+	Lookup   map[*ObjString]*ObjEnumValue // Variants by name.
+}
+
+// ObjEnumValue represents a variant of an enum. The variant stored in its enum carries no payload;
+// calling a variant that declares payload fields creates a new value holding the arguments.
+type ObjEnumValue struct {
+	Obj     Obj
+	Enum    *ObjEnum     // The enum the variant belongs to.
+	Name    *ObjString   // The name of the variant.
+	Index   int          // Position of the variant in the enum declaration.
+	Fields  []*ObjString // Names of the payload fields declared by the variant.
+	Payload []Value      // Payload values, or nil for the variant itself.
+}
+
 // strings is a map for interning strings, storing ObjString objects by their hash to reuse
 // identical strings and reduce memory usage.
 var strings = make(map[uint32]*ObjString)
@@ -168,9 +216,46 @@ func ObjVal(obj interface{}) Value {
 // NewStruct creates a new struct type with the given name and an empty field map.
 func NewStruct(name *ObjString) *ObjStruct {
 	return &ObjStruct{
-		Obj:    Obj{Type: OBJ_STRUCT},
-		Name:   name,
-		Fields: make(map[*ObjString]Value),
+		Obj:     Obj{Type: OBJ_STRUCT},
+		Name:    name,
+		Fields:  make(map[*ObjString]Value),
+		Methods: make(map[*ObjString]*ObjClosure),
+	}
+}
+
+// Inherit makes the struct a child of parent, copying the parent's field defaults and methods
+// that the struct does not declare itself.
+func (s *ObjStruct) Inherit(parent *ObjStruct) {
+	s.Parent = parent
+	for name, value := range parent.Fields {
+		if _, found := s.Fields[name]; !found {
+			s.Fields[name] = value
+		}
+	}
+	for name, method := range parent.Methods {
+		if _, found := s.Methods[name]; !found {
+			s.Methods[name] = method
+		}
+	}
+}
+
+// IsInstanceOf reports whether the instance was created from the given struct or from a struct
+// inheriting from it.
+func (i *ObjInstance) IsInstanceOf(structure *ObjStruct) bool {
+	for s := i.Structure; s != nil; s = s.Parent {
+		if s == structure {
+			return true
+		}
+	}
+	return false
+}
+
+// NewBoundMethod binds a method closure to the instance it was read from.
+func NewBoundMethod(receiver Value, method *ObjClosure) *ObjBoundMethod {
+	return &ObjBoundMethod{
+		Obj:      Obj{Type: OBJ_BOUND_METHOD},
+		Receiver: receiver,
+		Method:   method,
 	}
 }
 
@@ -189,6 +274,41 @@ func NewInstance(structure *ObjStruct) *ObjInstance {
 	return instance
 }
 
+// NewEnum creates a new enum type with the given name and no variants.
+func NewEnum(name *ObjString) *ObjEnum {
+	return &ObjEnum{
+		Obj:    Obj{Type: OBJ_ENUM},
+		Name:   name,
+		Lookup: make(map[*ObjString]*ObjEnumValue),
+	}
+}
+
+// AddVariant appends a variant with the given payload field names to the enum.
+func (e *ObjEnum) AddVariant(name *ObjString, fields []*ObjString) *ObjEnumValue {
+	variant := &ObjEnumValue{
+		Obj:    Obj{Type: OBJ_ENUM_VALUE},
+		Enum:   e,
+		Name:   name,
+		Index:  len(e.Variants),
+		Fields: fields,
+	}
+	e.Variants = append(e.Variants, variant)
+	e.Lookup[name] = variant
+	return variant
+}
+
+// WithPayload creates a value of the variant carrying the given payload.
+func (v *ObjEnumValue) WithPayload(payload []Value) *ObjEnumValue {
+	return &ObjEnumValue{
+		Obj:     Obj{Type: OBJ_ENUM_VALUE},
+		Enum:    v.Enum,
+		Name:    v.Name,
+		Index:   v.Index,
+		Fields:  v.Fields,
+		Payload: payload,
+	}
+}
+
 // ObjArray represents an array object that holds a slice of values.
 type ObjArray struct {
 	Obj
@@ -220,19 +340,302 @@ func NewArrayIterator(array *ObjArray) *ObjArrayIterator {
 	}
 }
 
+// ObjIterator is a native iterator, created for the values 'iter' loops walk and by natives that
+// produce sequences. Scripts drive it with its done() and next() methods, the same protocol a
+// struct implements to make its instances iterable.
+type ObjIterator struct {
+	Obj
+	Done func() bool  // Reports whether every value has been produced.
+	Next func() Value // Produces the next value and advances past it.
+}
+
+// NewIterator creates a native iterator from its done and next functions.
+func NewIterator(done func() bool, next func() Value) *ObjIterator {
+	return &ObjIterator{
+		Obj:  Obj{Type: OBJ_ITERATOR},
+		Done: done,
+		Next: next,
+	}
+}
+
+// ObjRange is a lazy sequence of numbers from Start towards End, counting by Step. 'a..b' stops
+// before End; an inclusive range, 'a..=b', contains End when a step lands on it. A range made from
+// integers keeps its bounds and step in IntStart, IntEnd and IntStep so its values stay exact.
+type ObjRange struct {
+	Obj
+	Start     float64
+	End       float64
+	Step      float64
+	IntStart  int64
+	IntEnd    int64
+	IntStep   int64
+	Inclusive bool
+	Integer   bool // The range was made from integers, so its values are integers too.
+}
+
+// NewRange creates a range of floats. The step must not be zero.
+func NewRange(start, end, step float64, inclusive bool) *ObjRange {
+	return &ObjRange{
+		Obj:       Obj{Type: OBJ_RANGE},
+		Start:     start,
+		End:       end,
+		Step:      step,
+		Inclusive: inclusive,
+	}
+}
+
+// NewIntRange creates a range of integers. The step must not be zero.
+func NewIntRange(start, end, step int64, inclusive bool) *ObjRange {
+	return &ObjRange{
+		Obj:       Obj{Type: OBJ_RANGE},
+		Start:     float64(start),
+		End:       float64(end),
+		Step:      float64(step),
+		IntStart:  start,
+		IntEnd:    end,
+		IntStep:   step,
+		Inclusive: inclusive,
+		Integer:   true,
+	}
+}
+
+// Len returns the number of values in the range and whether that number fits in an int. A range
+// with more values than that reports math.MaxInt.
+func (r *ObjRange) Len() (int, bool) {
+	if !r.Integer {
+		span := (r.End - r.Start) / r.Step
+		if span < 0 {
+			return 0, true
+		}
+		if r.Inclusive {
+			span = math.Floor(span) + 1
+		} else {
+			span = math.Ceil(span)
+		}
+		if span >= math.MaxInt {
+			return math.MaxInt, false
+		}
+		return int(span), true
+	}
+
+	// The distance between the bounds and the size of the step are counted in uint64, which
+	// holds them exactly even when they span the whole int64 range.
+	var distance uint64
+	if r.IntStep > 0 && r.IntEnd >= r.IntStart {
+		distance = uint64(r.IntEnd) - uint64(r.IntStart)
+	} else if r.IntStep < 0 && r.IntEnd <= r.IntStart {
+		distance = uint64(r.IntStart) - uint64(r.IntEnd)
+	} else {
+		return 0, true
+	}
+	step := r.stepSize()
+	count := distance / step
+	if r.Inclusive {
+		if count == math.MaxUint64 {
+			return math.MaxInt, false
+		}
+		count++
+	} else if distance%step != 0 {
+		count++
+	}
+	if count > math.MaxInt {
+		return math.MaxInt, false
+	}
+	return int(count), true
+}
+
+// stepSize returns the size of an integer range's step, which fits in a uint64 even for the
+// smallest int64.
+func (r *ObjRange) stepSize() uint64 {
+	if r.IntStep < 0 {
+		return uint64(-r.IntStep)
+	}
+	return uint64(r.IntStep)
+}
+
+// ValueAt returns the value at the given position of the range, as an integer for integer ranges.
+// The position must be less than the range's length.
+func (r *ObjRange) ValueAt(index int) Value {
+	if r.Integer {
+		// The value lies between the bounds, so the wrapping arithmetic gives it exactly.
+		return Value{Type: VAL_INT, Int: int64(uint64(r.IntStart) + uint64(index)*uint64(r.IntStep))}
+	}
+	return Value{Type: VAL_NUMBER, Number: r.Start + float64(index)*r.Step}
+}
+
+// IndexOf returns the position of a number in the range, and whether the number is one of the
+// range's values.
+func (r *ObjRange) IndexOf(n Value) (int, bool) {
+	length, _ := r.Len()
+	if !r.Integer {
+		index := (AsFloat(n) - r.Start) / r.Step
+		if index != math.Trunc(index) || index < 0 || index >= float64(length) {
+			return 0, false
+		}
+		return int(index), true
+	}
+
+	value := n.Int
+	if n.Type != VAL_INT {
+		if n.Number != math.Trunc(n.Number) || n.Number < math.MinInt64 || n.Number >= math.MaxInt64 {
+			return 0, false
+		}
+		value = int64(n.Number)
+	}
+	var distance uint64
+	if r.IntStep > 0 && value >= r.IntStart {
+		distance = uint64(value) - uint64(r.IntStart)
+	} else if r.IntStep < 0 && value <= r.IntStart {
+		distance = uint64(r.IntStart) - uint64(value)
+	} else {
+		return 0, false
+	}
+	step := r.stepSize()
+	if distance%step != 0 || distance/step >= uint64(length) {
+		return 0, false
+	}
+	return int(distance / step), true
+}
+
+// Contains reports whether a number is one of the values of the range.
+func (r *ObjRange) Contains(n Value) bool {
+	_, found := r.IndexOf(n)
+	return found
+}
+
+// Within returns the positions [first, last) of the range that hold its values from lo up to,
+// but not including, hi. For float ranges the positions are widened by one on each side against
+// rounding, so callers check each value.
+func (r *ObjRange) Within(lo, hi int64) (int, int) {
+	length, _ := r.Len()
+	if !r.Integer {
+		from, to := (float64(lo)-r.Start)/r.Step, (float64(hi)-r.Start)/r.Step
+		if r.Step < 0 {
+			from, to = to, from
+		}
+		first := max(0, min(from-1, float64(length)))
+		last := max(0, min(to+1, float64(length)))
+		return int(first), int(math.Ceil(last))
+	}
+
+	// Skip to the first value that has reached the interval, then walk while values stay in it.
+	step := r.stepSize()
+	first := uint64(0)
+	if r.IntStep > 0 && r.IntStart < lo {
+		first = ceilDiv(uint64(lo)-uint64(r.IntStart), step)
+	} else if r.IntStep < 0 && r.IntStart >= hi {
+		first = ceilDiv(uint64(r.IntStart)-uint64(hi-1), step)
+	}
+	if first >= uint64(length) {
+		return length, length
+	}
+	last := int(first)
+	for last < length {
+		value := r.ValueAt(last).Int
+		if value < lo || value >= hi {
+			break
+		}
+		last++
+	}
+	return int(first), last
+}
+
+// ceilDiv divides a by b, rounding up.
+func ceilDiv(a, b uint64) uint64 {
+	if a%b != 0 {
+		return a/b + 1
+	}
+	return a / b
+}
+
+// String formats the range as it is written: 'a..b', 'a..=b', or range(a, b, step) when the step
+// is not 1.
+func (r *ObjRange) String() string {
+	if r.Integer {
+		if r.IntStep != 1 {
+			return fmt.Sprintf("range(%d, %d, %d)", r.IntStart, r.IntEnd, r.IntStep)
+		}
+		if r.Inclusive {
+			return fmt.Sprintf("%d..=%d", r.IntStart, r.IntEnd)
+		}
+		return fmt.Sprintf("%d..%d", r.IntStart, r.IntEnd)
+	}
+	if r.Step != 1 {
+		return fmt.Sprintf("range(%g, %g, %g)", r.Start, r.End, r.Step)
+	}
+	if r.Inclusive {
+		return fmt.Sprintf("%g..=%g", r.Start, r.End)
+	}
+	return fmt.Sprintf("%g..%g", r.Start, r.End)
+}
+
+// GeneratorState is the stage of its run a generator is at.
+type GeneratorState int
+
+const (
+	GENERATOR_CREATED   GeneratorState = iota // Called, but the body has not started running.
+	GENERATOR_SUSPENDED                       // Paused at a 'yield'.
+	GENERATOR_RUNNING                         // Resumed; its frame is on the VM's call stack.
+	GENERATOR_DONE                            // Returned, or stopped by an uncaught error.
+)
+
+// GeneratorHandler is an exception handler of a 'try' block a paused generator is inside, kept
+// relative to the generator's frame until the frame is back on the stack.
+type GeneratorHandler struct {
+	StackOffset int // Stack height to restore, counted from the frame's first slot.
+	Address     int // Bytecode address of the handler.
+}
+
+// ObjGenerator is the call of a function containing 'yield'. While the generator is paused its
+// frame lives here rather than on the VM's call stack: the instruction to resume at, the
+// frame's stack slots and the upvalues and exception handlers that refer to them.
+type ObjGenerator struct {
+	Obj
+	Closure      *ObjClosure
+	State        GeneratorState
+	IP           int                // Instruction to resume at.
+	Slots        []Value            // The frame's stack slots, from the callee's slot up.
+	Upvalues     []*ObjUpvalue      // Upvalues of the frame's slots, closed while paused.
+	UpvalueSlots []int              // The slot each of Upvalues refers to.
+	Handlers     []GeneratorHandler // Handlers of the 'try' blocks paused in, innermost last.
+	Peeked       *Value             // A value done() ran ahead to, returned by the next next().
+}
+
+// NewGenerator creates a generator for a call of closure with the given frame slots.
+func NewGenerator(closure *ObjClosure, slots []Value) *ObjGenerator {
+	return &ObjGenerator{
+		Obj:     Obj{Type: OBJ_GENERATOR},
+		Closure: closure,
+		State:   GENERATOR_CREATED,
+		Slots:   append([]Value(nil), slots...),
+	}
+}
+
 // ObjModule represents a module
 type ObjModule struct {
-	Obj    Obj
-	Name   *ObjString // The name of the module.
-	Fields map[*ObjString]Value
+	Obj       Obj
+	Name      *ObjString // The name of the module.
+	Fields    map[*ObjString]Value
+	Constants map[*ObjString]bool // Fields declared with 'const', which cannot be assigned.
+	Exports   map[*ObjString]bool // Fields visible to importers of a file; nil for a 'mod' declaration, whose fields all are.
+}
+
+// Lookup returns the field called name if it is visible from outside the module.
+func (m *ObjModule) Lookup(name *ObjString) (Value, bool) {
+	if m.Exports != nil && !m.Exports[name] {
+		return Value{}, false
+	}
+	value, found := m.Fields[name]
+	return value, found
 }
 
 // NewModule creates a new module
 func NewModule(name *ObjString) *ObjModule {
 	return &ObjModule{
-		Obj:    Obj{Type: OBJ_MODULE},
-		Name:   name,
-		Fields: make(map[*ObjString]Value),
+		Obj:       Obj{Type: OBJ_MODULE},
+		Name:      name,
+		Fields:    make(map[*ObjString]Value),
+		Constants: make(map[*ObjString]bool),
 	}
 }
 
@@ -302,12 +705,29 @@ func PrintObject(obj interface{}) {
 		} else {
 			fmt.Printf("<fn %s>", o.Name.Chars)
 		}
+	case *ObjBoundMethod:
+		fmt.Printf("<fn %s>", o.Method.Function.Name.Chars)
 	case *ObjNative:
 		fmt.Print("<native fn>")
 	case *ObjString:
 		fmt.Print(o.Chars)
 	case *ObjStruct:
 		fmt.Printf("<struct %s>", o.Name.Chars)
+	case *ObjEnum:
+		fmt.Printf("<enum %s>", o.Name.Chars)
+	case *ObjEnumValue:
+		// Print the variant as Enum::Variant, followed by its payload as (value1, value2) if it has one.
+		fmt.Printf("%s::%s", o.Enum.Name.Chars, o.Name.Chars)
+		if o.Payload != nil {
+			fmt.Print("(")
+			for i, value := range o.Payload {
+				if i > 0 {
+					fmt.Print(", ")
+				}
+				PrintValue(value)
+			}
+			fmt.Print(")")
+		}
 	case *ObjInstance:
 		// Print the instance as <(struct structName), field1=value1, field2=value2>, including all
 		// field names and their values.
@@ -334,6 +754,16 @@ func PrintObject(obj interface{}) {
 		fmt.Print("]")
 	case *ObjArrayIterator:
 		fmt.Printf("<array iterator at %d>", o.Index)
+	case *ObjIterator:
+		fmt.Print("<iterator>")
+	case *ObjGenerator:
+		fmt.Printf("<generator %s>", o.Closure.Function.Name.Chars)
+	case *ObjRange:
+		fmt.Print(o.String())
+	case *ObjBigInt:
+		fmt.Print(o.String())
+	case *ObjDecimal:
+		fmt.Print(o.String())
 	case *ObjModule:
 		fmt.Printf("<mod %s>", o.Name.Chars)
 	case *ObjMap:
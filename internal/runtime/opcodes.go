@@ -12,6 +12,7 @@ const (
 	OP_SET_LOCAL
 	OP_GET_LOCAL
 	OP_DEFINE_GLOBAL
+	OP_DEFINE_CONST
 	OP_SET_GLOBAL
 	OP_GET_GLOBAL
 	OP_GET_UPVALUE
@@ -31,6 +32,7 @@ const (
 	OP_JUMP
 	OP_JUMP_IF_FALSE
 	OP_JUMP_IF_TRUE
+	OP_JUMP_IF_NULL
 	OP_LOOP
 	OP_CALL
 	OP_CLOSURE
@@ -48,11 +50,58 @@ const (
 	OP_MAP
 	OP_MODULE
 	OP_IMPORT
+	OP_IMPORT_ALL
+	OP_NAMESPACE
 	OP_USE
 	OP_DEFINE_EXTERN
 	OP_MATCH
 	OP_DUP
+	OP_DUP_TWO
 	OP_EXPONENTIAL
 	OP_FLOOR
 	OP_PERCENT
+	OP_BIT_AND
+	OP_BIT_OR
+	OP_BIT_XOR
+	OP_BIT_NOT
+	OP_SHIFT_LEFT
+	OP_SHIFT_RIGHT
+	OP_SWITCH
+	OP_ENUM
+	OP_GET_VARIANT
+	OP_TRY
+	OP_END_TRY
+	OP_THROW
+	OP_INVOKE
+	OP_INHERIT
+	OP_GET_SUPER
+	OP_SUPER_INVOKE
+	OP_NEW
+	OP_DEFAULT_ARG
+	OP_ARGUMENT_NAMES
+	OP_UNPACK
+	OP_ITERATOR
+	OP_RANGE
+	OP_IN
+	OP_YIELD
+	OP_INTERPOLATE
+)
+
+// MatchKind is the operand of OP_MATCH and selects the structural test performed on a value.
+type MatchKind uint8
+
+const (
+	MATCH_ARRAY    MatchKind = iota // Value is an array with exactly N elements.
+	MATCH_INSTANCE                  // Value is an instance of the struct on top of the stack.
+	MATCH_VARIANT                   // Value is the named variant of the enum on top of the stack.
+	MATCH_PAYLOAD                   // Value is an enum value with at least N payload values.
+)
+
+// UnpackKind is the operand of OP_UNPACK and selects how a destructured value is taken apart.
+type UnpackKind uint8
+
+const (
+	UNPACK_ARRAY    UnpackKind = iota // Elements of an array, by position.
+	UNPACK_MAP                        // Values of a map, by key.
+	UNPACK_INSTANCE                   // Fields of an instance of the struct on top of the stack.
 )
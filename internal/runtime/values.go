@@ -10,6 +10,7 @@ const (
 	VAL_BOOL ValueType = iota
 	VAL_NULL
 	VAL_NUMBER
+	VAL_INT
 	VAL_OBJ
 )
 
@@ -17,9 +18,31 @@ type Value struct {
 	Type   ValueType
 	Bool   bool
 	Number float64
+	Int    int64
 	Obj    interface{}
 }
 
+// IsNumber reports whether a value is an integer or a floating-point number.
+func IsNumber(v Value) bool {
+	return v.Type == VAL_INT || v.Type == VAL_NUMBER
+}
+
+// AsFloat returns the value of a number as a float64, converting integers.
+func AsFloat(v Value) float64 {
+	if v.Type == VAL_INT {
+		return float64(v.Int)
+	}
+	return v.Number
+}
+
+// AsInt returns the value of a number as an int64, truncating floats towards zero.
+func AsInt(v Value) int64 {
+	if v.Type == VAL_INT {
+		return v.Int
+	}
+	return int64(v.Number)
+}
+
 type ValueArray struct {
 	values   []Value
 	count    int
@@ -70,12 +93,24 @@ func PrintValue(v Value) {
 		fmt.Print("null")
 	case VAL_NUMBER:
 		fmt.Printf("%g", v.Number)
+	case VAL_INT:
+		fmt.Print(v.Int)
 	case VAL_OBJ:
 		PrintObject(v.Obj)
 	}
 }
 
 func Equal(a, b Value) bool {
+	// An integer equals the float with the same value.
+	if a.Type != b.Type && IsNumber(a) && IsNumber(b) {
+		return AsFloat(a) == AsFloat(b)
+	}
+	// BigInts and Decimals equal the numbers of any kind with exactly the same value.
+	if IsBigNumber(a) || IsBigNumber(b) {
+		ratA, okA := ExactRat(a)
+		ratB, okB := ExactRat(b)
+		return okA && okB && ratA.Cmp(ratB) == 0
+	}
 	if a.Type != b.Type {
 		return false
 	}
@@ -86,14 +121,38 @@ func Equal(a, b Value) bool {
 		return true
 	case VAL_NUMBER:
 		return a.Number == b.Number
+	case VAL_INT:
+		return a.Int == b.Int
 	case VAL_OBJ:
 		aStr, okA := a.Obj.(*ObjString)
 		bStr, okB := b.Obj.(*ObjString)
 		if okA && okB {
 			return aStr.Chars == bStr.Chars
 		}
+		aEnum, okA := a.Obj.(*ObjEnumValue)
+		bEnum, okB := b.Obj.(*ObjEnumValue)
+		if okA && okB {
+			return enumValuesEqual(aEnum, bEnum)
+		}
 		return false
 	default:
 		return false
 	}
 }
+
+// enumValuesEqual reports whether two enum values are the same variant of the same enum with equal
+// payloads.
+func enumValuesEqual(a, b *ObjEnumValue) bool {
+	if a.Enum != b.Enum || a.Index != b.Index || (a.Payload == nil) != (b.Payload == nil) {
+		return false
+	}
+	if len(a.Payload) != len(b.Payload) {
+		return false
+	}
+	for i := range a.Payload {
+		if !Equal(a.Payload[i], b.Payload[i]) {
+			return false
+		}
+	}
+	return true
+}
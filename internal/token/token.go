@@ -19,6 +19,9 @@ const (
 	TOKEN_PERCENT
 	TOKEN_STAR
 	TOKEN_PIPE
+	TOKEN_AMPERSAND
+	TOKEN_CARET
+	TOKEN_TILDE
 	TOKEN_QUESTION
 	TOKEN_AT
 	TOKEN_HASH
@@ -34,17 +37,38 @@ const (
 	TOKEN_GREATER_EQUAL
 	TOKEN_LESS
 	TOKEN_LESS_EQUAL
+	TOKEN_LESS_LESS
+	TOKEN_GREATER_GREATER
 	TOKEN_PLUS_PLUS
 	TOKEN_MINUS_MINUS
 	TOKEN_STAR_STAR
 	TOKEN_FLOOR
 	TOKEN_PERCENT_PERCENT
+	TOKEN_COLON_COLON
+	TOKEN_DOT_DOT_DOT
+	TOKEN_DOT_DOT
+	TOKEN_DOT_DOT_EQUAL
+	TOKEN_PLUS_EQUAL
+	TOKEN_MINUS_EQUAL
+	TOKEN_STAR_EQUAL
+	TOKEN_SLASH_EQUAL
+	TOKEN_PERCENT_EQUAL
+	TOKEN_STAR_STAR_EQUAL
+	TOKEN_AMPERSAND_EQUAL
+	TOKEN_PIPE_EQUAL
+	TOKEN_CARET_EQUAL
+	TOKEN_LESS_LESS_EQUAL
+	TOKEN_GREATER_GREATER_EQUAL
+	TOKEN_QUESTION_QUESTION
+	TOKEN_QUESTION_DOT
+	TOKEN_QUESTION_BRACKET
 
 	// Literals
 	TOKEN_IDENTIFIER
 	TOKEN_USE_TYPE
 	TOKEN_CHAR
 	TOKEN_STRING
+	TOKEN_INTERPOLATION
 	TOKEN_NUMBER
 
 	// Indentation
@@ -55,6 +79,7 @@ const (
 	// Keywords
 	TOKEN_AND
 	TOKEN_STRUCT
+	TOKEN_ENUM
 	TOKEN_CLASS
 	TOKEN_ELSE
 	TOKEN_FALSE
@@ -64,10 +89,12 @@ const (
 	TOKEN_NULL
 	TOKEN_OR
 	TOKEN_RETURN
+	TOKEN_YIELD
 	TOKEN_SUPER
 	TOKEN_THIS
 	TOKEN_TRUE
 	TOKEN_VAR
+	TOKEN_CONST
 	TOKEN_WHILE
 	TOKEN_ITER
 	TOKEN_IN
@@ -76,6 +103,10 @@ const (
 	TOKEN_MATCH
 	TOKEN_WITH
 	TOKEN_THROUGH
+	TOKEN_TRY
+	TOKEN_CATCH
+	TOKEN_FINALLY
+	TOKEN_THROW
 	TOKEN_RANDOM
 	TOKEN_IMPORT
 	TOKEN_EXPORT
@@ -88,8 +119,9 @@ const (
 )
 
 type Token struct {
-	Type   TokenType
-	Start  string
-	Length int
-	Line   int
+	Type    TokenType
+	Start   string
+	Length  int
+	Line    int
+	Literal string // Decoded text of a string or character literal, or of a part of an interpolated string.
 }
@@ -14,5 +14,5 @@ func defineArgs(args []string) {
 
 	// Define the "args" global as an array.
 	argsName := runtime.NewObjString("args")
-	vm.globals[argsName] = runtime.ObjVal(runtime.NewArray(elements))
+	vm.builtins[argsName] = runtime.ObjVal(runtime.NewArray(elements))
 }
@@ -0,0 +1,427 @@
+package vm
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/cryptrunner49/zscript/internal/runtime"
+)
+
+// Defaults of the Decimal context, which decimal_precision and decimal_rounding change.
+const (
+	DEFAULT_DECIMAL_PRECISION = 20                      // Digits kept after the decimal point by '/'.
+	DEFAULT_DECIMAL_ROUNDING  = runtime.ROUND_HALF_EVEN // Rounding of '/' and decimal_round.
+)
+
+// maxBigShift bounds the shift counts of BigInts, whose results would not fit in memory.
+const maxBigShift = 1 << 32
+
+// arithmeticOperators are the symbols of the arithmetic operators, used in error messages.
+var arithmeticOperators = map[runtime.OpCode]string{
+	runtime.OP_ADD:         "+",
+	runtime.OP_SUBTRACT:    "-",
+	runtime.OP_MULTIPLY:    "*",
+	runtime.OP_DIVIDE:      "/",
+	runtime.OP_MOD:         "%",
+	runtime.OP_EXPONENTIAL: "**",
+	runtime.OP_FLOOR:       "/_",
+	runtime.OP_PERCENT:     "%%",
+}
+
+// operatorSymbol returns the symbol of an arithmetic or bitwise operator.
+func operatorSymbol(op runtime.OpCode) string {
+	if symbol, ok := bitwiseOperators[op]; ok {
+		return symbol
+	}
+	return arithmeticOperators[op]
+}
+
+// bigOperands reports whether a binary operator works on BigInts or Decimals: one operand is a
+// BigInt or a Decimal and the other a number of any kind.
+func bigOperands(a, b runtime.Value) bool {
+	return (runtime.IsBigNumber(a) || runtime.IsBigNumber(b)) &&
+		(runtime.IsNumber(a) || runtime.IsBigNumber(a)) && (runtime.IsNumber(b) || runtime.IsBigNumber(b))
+}
+
+// toBigInt returns the value of an int or a BigInt as a big.Int, which must not be modified.
+func toBigInt(v runtime.Value) (*big.Int, bool) {
+	if v.Type == runtime.VAL_INT {
+		return big.NewInt(v.Int), true
+	}
+	if b, ok := v.Obj.(*runtime.ObjBigInt); ok {
+		return b.Value, true
+	}
+	return nil, false
+}
+
+// bigBinary pops the operands of a binary operator working on BigInts or Decimals and pushes its
+// result.
+func bigBinary(op runtime.OpCode) InterpretResult {
+	b := Pop()
+	a := Pop()
+	result, err := bigArithmetic(op, a, b)
+	if err != INTERPRET_OK {
+		return err
+	}
+	Push(result)
+	return INTERPRET_OK
+}
+
+// bigArithmetic applies a binary operator to two numbers, one of them a BigInt or a Decimal. A
+// Decimal operand makes the result a Decimal. Otherwise an int operand is promoted and the result
+// is a BigInt, except for '/' and '%%', whose results are Decimals. Floats are not exact, so they
+// are never promoted: mixing one in is an error until it is converted explicitly.
+func bigArithmetic(op runtime.OpCode, a, b runtime.Value) (runtime.Value, InterpretResult) {
+	symbol := operatorSymbol(op)
+	if a.Type == runtime.VAL_NUMBER || b.Type == runtime.VAL_NUMBER {
+		exact := typeName(a)
+		if a.Type == runtime.VAL_NUMBER {
+			exact = typeName(b)
+		}
+		return a, runtimeError("Cannot mix %s and float in '%s'; convert the float with %s() or the %s with to_float() first.", exact, symbol, exact, exact)
+	}
+	x, xIsInteger := toBigInt(a)
+	y, yIsInteger := toBigInt(b)
+	if xIsInteger && yIsInteger && op != runtime.OP_DIVIDE && op != runtime.OP_PERCENT {
+		return bigIntArithmetic(op, x, y)
+	}
+	if _, isBitwise := bitwiseOperators[op]; isBitwise {
+		return a, runtimeError("Operands for '%s' must be integers (got %s).", symbol, integerOperandName(nonInteger(a, b)))
+	}
+
+	d, _ := runtime.ToDecimal(a)
+	e, _ := runtime.ToDecimal(b)
+	switch op {
+	case runtime.OP_ADD:
+		return runtime.ObjVal(d.Add(e)), INTERPRET_OK
+	case runtime.OP_SUBTRACT:
+		return runtime.ObjVal(d.Sub(e)), INTERPRET_OK
+	case runtime.OP_MULTIPLY:
+		return runtime.ObjVal(d.Mul(e)), INTERPRET_OK
+	case runtime.OP_PERCENT:
+		return runtime.ObjVal(d.Percent(e)), INTERPRET_OK
+	case runtime.OP_EXPONENTIAL:
+		return decimalPower(d, b)
+	}
+	if e.IsZero() {
+		return a, runtimeError("Division by zero in '%s'.", symbol)
+	}
+	switch op {
+	case runtime.OP_DIVIDE:
+		return runtime.ObjVal(d.Quo(e, vm.decimalPrecision, vm.decimalRounding)), INTERPRET_OK
+	case runtime.OP_MOD:
+		return runtime.ObjVal(d.Rem(e)), INTERPRET_OK
+	default: // OP_FLOOR
+		return runtime.ObjVal(d.FloorQuo(e)), INTERPRET_OK
+	}
+}
+
+// bigIntArithmetic applies a binary operator to two integers, giving a BigInt.
+func bigIntArithmetic(op runtime.OpCode, x, y *big.Int) (runtime.Value, InterpretResult) {
+	symbol := operatorSymbol(op)
+	result := new(big.Int)
+	switch op {
+	case runtime.OP_ADD:
+		result.Add(x, y)
+	case runtime.OP_SUBTRACT:
+		result.Sub(x, y)
+	case runtime.OP_MULTIPLY:
+		result.Mul(x, y)
+	case runtime.OP_MOD, runtime.OP_FLOOR:
+		if y.Sign() == 0 {
+			return runtime.ObjVal(runtime.NewBigInt(result)), runtimeError("Division by zero in '%s'.", symbol)
+		}
+		remainder := new(big.Int)
+		result.QuoRem(x, y, remainder)
+		if op == runtime.OP_MOD {
+			result = remainder
+		} else if remainder.Sign() != 0 && remainder.Sign() != y.Sign() {
+			result.Sub(result, big.NewInt(1))
+		}
+	case runtime.OP_EXPONENTIAL:
+		if y.Sign() < 0 {
+			return runtime.ObjVal(runtime.NewBigInt(result)), runtimeError("A bigint cannot be raised to a negative power; convert it with decimal() first.")
+		}
+		result.Exp(x, y, nil)
+	case runtime.OP_BIT_AND:
+		result.And(x, y)
+	case runtime.OP_BIT_OR:
+		result.Or(x, y)
+	case runtime.OP_BIT_XOR:
+		result.Xor(x, y)
+	case runtime.OP_SHIFT_LEFT, runtime.OP_SHIFT_RIGHT:
+		if y.Sign() < 0 {
+			return runtime.ObjVal(runtime.NewBigInt(result)), runtimeError("Shift count for '%s' cannot be negative (got %s).", symbol, y)
+		}
+		if op == runtime.OP_SHIFT_RIGHT {
+			if y.Cmp(big.NewInt(int64(x.BitLen()))) >= 0 {
+				result.SetInt64(int64(min(x.Sign(), 0)))
+			} else {
+				result.Rsh(x, uint(y.Uint64()))
+			}
+		} else if x.Sign() != 0 {
+			if y.Cmp(big.NewInt(maxBigShift)) > 0 {
+				return runtime.ObjVal(runtime.NewBigInt(result)), runtimeError("Shift count for '%s' is too large (got %s).", symbol, y)
+			}
+			result.Lsh(x, uint(y.Uint64()))
+		}
+	}
+	return runtime.ObjVal(runtime.NewBigInt(result)), INTERPRET_OK
+}
+
+// decimalPower raises a Decimal to an integer power. A negative power divides like '/'.
+func decimalPower(d *runtime.ObjDecimal, exponent runtime.Value) (runtime.Value, InterpretResult) {
+	n, ok := toBigInt(exponent)
+	if !ok {
+		return runtime.ObjVal(d), runtimeError("The exponent of '**' on a decimal must be an integer (got %s).", integerOperandName(exponent))
+	}
+	if !n.IsInt64() || n.Int64() > math.MaxInt32 || n.Int64() < -math.MaxInt32 {
+		return runtime.ObjVal(d), runtimeError("The exponent of '**' is too large (got %s).", n)
+	}
+	if n.Sign() >= 0 {
+		return runtime.ObjVal(d.Pow(n.Int64())), INTERPRET_OK
+	}
+	if d.IsZero() {
+		return runtime.ObjVal(d), runtimeError("Division by zero in '**'.")
+	}
+	one := runtime.NewDecimal(big.NewInt(1), 0)
+	return runtime.ObjVal(one.Quo(d.Pow(-n.Int64()), vm.decimalPrecision, vm.decimalRounding)), INTERPRET_OK
+}
+
+// compareNumbers compares two numbers of any kind exactly, returning -1, 0 or +1. It fails when
+// either of them is NaN.
+func compareNumbers(a, b runtime.Value) (int, bool) {
+	// An infinite float is beyond every BigInt and Decimal.
+	if a.Type == runtime.VAL_NUMBER && math.IsInf(a.Number, 0) {
+		return int(math.Copysign(1, a.Number)), true
+	}
+	if b.Type == runtime.VAL_NUMBER && math.IsInf(b.Number, 0) {
+		return -int(math.Copysign(1, b.Number)), true
+	}
+	ratA, okA := runtime.ExactRat(a)
+	ratB, okB := runtime.ExactRat(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	return ratA.Cmp(ratB), true
+}
+
+// negateBig applies unary '-' to a BigInt or a Decimal.
+func negateBig(v runtime.Value) runtime.Value {
+	if b, ok := v.Obj.(*runtime.ObjBigInt); ok {
+		return runtime.ObjVal(runtime.NewBigInt(new(big.Int).Neg(b.Value)))
+	}
+	return runtime.ObjVal(v.Obj.(*runtime.ObjDecimal).Neg())
+}
+
+// decimalFormat formats a Decimal for printf and sprintf. '%.2f' rounds it with the rounding mode
+// of the Decimal context; other verbs print it as it is.
+type decimalFormat struct {
+	decimal *runtime.ObjDecimal
+}
+
+// Format implements fmt.Formatter.
+func (f decimalFormat) Format(state fmt.State, verb rune) {
+	text := f.decimal.String()
+	if places, ok := state.Precision(); ok && (verb == 'f' || verb == 'F') {
+		text = f.decimal.Round(places, vm.decimalRounding).String()
+	}
+	if width, ok := state.Width(); ok && len(text) < width {
+		padding := strings.Repeat(" ", width-len(text))
+		if state.Flag('-') {
+			text += padding
+		} else {
+			text = padding + text
+		}
+	}
+	fmt.Fprint(state, text)
+}
+
+// formatArg returns the argument passed to fmt for a BigInt or a Decimal in printf and sprintf.
+func formatArg(obj interface{}) (interface{}, bool) {
+	switch o := obj.(type) {
+	case *runtime.ObjBigInt:
+		return o.Value, true
+	case *runtime.ObjDecimal:
+		return decimalFormat{o}, true
+	}
+	return nil, false
+}
+
+// ============================================================================
+// Native Functions: Numbers
+// ============================================================================
+
+// bigintNative converts an int, a whole float or Decimal, or a string of digits to a BigInt.
+func bigintNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("'bigint' expects 1 argument.")
+	}
+	value := args[0]
+	switch {
+	case value.Type == runtime.VAL_INT:
+		return runtime.ObjVal(runtime.NewBigInt(big.NewInt(value.Int))), nil
+	case value.Type == runtime.VAL_NUMBER:
+		if math.IsNaN(value.Number) || math.IsInf(value.Number, 0) || value.Number != math.Trunc(value.Number) {
+			return nativeError("Cannot convert %g to a bigint; it is not a whole number.", value.Number)
+		}
+		integer, _ := big.NewFloat(value.Number).Int(nil)
+		return runtime.ObjVal(runtime.NewBigInt(integer)), nil
+	}
+	switch obj := value.Obj.(type) {
+	case *runtime.ObjBigInt:
+		return value, nil
+	case *runtime.ObjDecimal:
+		integer, whole := obj.Integer()
+		if !whole {
+			return nativeError("Cannot convert %s to a bigint; it is not a whole number. Round it with decimal_round() first.", obj)
+		}
+		return runtime.ObjVal(runtime.NewBigInt(integer)), nil
+	case *runtime.ObjString:
+		integer, ok := new(big.Int).SetString(obj.Chars, 0)
+		if !ok {
+			return nativeError("Cannot convert '%s' to a bigint.", obj.Chars)
+		}
+		return runtime.ObjVal(runtime.NewBigInt(integer)), nil
+	}
+	return nativeError("Cannot convert %s to a bigint.", typeName(value))
+}
+
+// decimalNative converts a number or a string such as "19.99" to a Decimal. A float becomes the
+// Decimal of its shortest printed form.
+func decimalNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("'decimal' expects 1 argument.")
+	}
+	if str, ok := args[0].Obj.(*runtime.ObjString); ok {
+		decimal, ok := runtime.ParseDecimal(str.Chars)
+		if !ok {
+			return nativeError("Cannot convert '%s' to a decimal.", str.Chars)
+		}
+		return runtime.ObjVal(decimal), nil
+	}
+	decimal, ok := runtime.ToDecimal(args[0])
+	if !ok {
+		return nativeError("Cannot convert %s to a decimal.", toStr(1, args).Obj.(*runtime.ObjString).Chars)
+	}
+	return runtime.ObjVal(decimal), nil
+}
+
+// decimalRoundNative rounds a Decimal, int or BigInt to a number of digits after the decimal
+// point, with the given rounding mode or that of the Decimal context.
+func decimalRoundNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 2 && argCount != 3 {
+		return nativeError("'decimal_round' expects 2 or 3 arguments: a decimal, the digits to keep and optionally a rounding mode.")
+	}
+	if args[0].Type == runtime.VAL_NUMBER {
+		return nativeError("'decimal_round' expects a decimal, not a float; convert it with decimal() first.")
+	}
+	decimal, ok := runtime.ToDecimal(args[0])
+	if !ok {
+		return nativeError("'decimal_round' expects a decimal, got %s.", typeName(args[0]))
+	}
+	if args[1].Type != runtime.VAL_INT || args[1].Int < 0 || args[1].Int > math.MaxInt32 {
+		return nativeError("'decimal_round' expects a non-negative integer number of digits.")
+	}
+	mode := vm.decimalRounding
+	if argCount == 3 {
+		var err error
+		if mode, err = roundingMode(args[2]); err != nil {
+			return runtime.Value{Type: runtime.VAL_NULL}, err
+		}
+	}
+	return runtime.ObjVal(decimal.Round(int(args[1].Int), mode)), nil
+}
+
+// decimalPrecisionNative returns the number of digits '/' keeps after the decimal point in
+// Decimal divisions, first setting it when given one.
+func decimalPrecisionNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount > 1 {
+		return nativeError("'decimal_precision' expects at most 1 argument.")
+	}
+	previous := runtime.Value{Type: runtime.VAL_INT, Int: int64(vm.decimalPrecision)}
+	if argCount == 1 {
+		if args[0].Type != runtime.VAL_INT || args[0].Int < 0 || args[0].Int > math.MaxInt32 {
+			return nativeError("'decimal_precision' expects a non-negative integer.")
+		}
+		vm.decimalPrecision = int(args[0].Int)
+	}
+	return previous, nil
+}
+
+// decimalRoundingNative returns the name of the rounding mode of the Decimal context, first
+// setting it when given one.
+func decimalRoundingNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount > 1 {
+		return nativeError("'decimal_rounding' expects at most 1 argument.")
+	}
+	previous := runtime.ObjVal(runtime.NewObjString(runtime.RoundingModes[vm.decimalRounding]))
+	if argCount == 1 {
+		mode, err := roundingMode(args[0])
+		if err != nil {
+			return runtime.Value{Type: runtime.VAL_NULL}, err
+		}
+		vm.decimalRounding = mode
+	}
+	return previous, nil
+}
+
+// roundingMode returns the rounding mode a string names.
+func roundingMode(name runtime.Value) (runtime.RoundingMode, error) {
+	if str, ok := name.Obj.(*runtime.ObjString); ok {
+		if mode := slices.Index(runtime.RoundingModes, str.Chars); mode >= 0 {
+			return runtime.RoundingMode(mode), nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown rounding mode '%s'; expected one of '%s'.", toStr(1, []runtime.Value{name}).Obj.(*runtime.ObjString).Chars, strings.Join(runtime.RoundingModes, "', '"))
+}
+
+// toIntNative converts a number of any kind to an int, truncating towards zero.
+func toIntNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("'to_int' expects 1 argument.")
+	}
+	value := args[0]
+	switch {
+	case value.Type == runtime.VAL_INT:
+		return value, nil
+	case value.Type == runtime.VAL_NUMBER:
+		if math.IsNaN(value.Number) || math.Abs(value.Number) >= 1<<63 {
+			return nativeError("Cannot convert %g to an int; it is out of range.", value.Number)
+		}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(value.Number)}, nil
+	}
+	var integer *big.Int
+	switch obj := value.Obj.(type) {
+	case *runtime.ObjBigInt:
+		integer = obj.Value
+	case *runtime.ObjDecimal:
+		integer, _ = obj.Integer()
+	default:
+		return nativeError("Cannot convert %s to an int.", typeName(value))
+	}
+	if !integer.IsInt64() {
+		return nativeError("Cannot convert %s to an int; it does not fit in 64 bits.", integer)
+	}
+	return runtime.Value{Type: runtime.VAL_INT, Int: integer.Int64()}, nil
+}
+
+// toFloatNative converts a number of any kind to the nearest float.
+func toFloatNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("'to_float' expects 1 argument.")
+	}
+	value := args[0]
+	if runtime.IsNumber(value) {
+		return runtime.Value{Type: runtime.VAL_NUMBER, Number: runtime.AsFloat(value)}, nil
+	}
+	rat, ok := runtime.ExactRat(value)
+	if !ok {
+		return nativeError("Cannot convert %s to a float.", typeName(value))
+	}
+	f, _ := rat.Float64()
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: f}, nil
+}
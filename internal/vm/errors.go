@@ -0,0 +1,143 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cryptrunner49/zscript/internal/runtime"
+)
+
+// ExceptionHandler is registered by OP_TRY and records where execution resumes when an error is
+// raised inside the guarded block.
+type ExceptionHandler struct {
+	frameCount int // Number of call frames active when the handler was registered.
+	stackTop   int // Stack height to restore before the error value is pushed.
+	address    int // Bytecode address of the handler in the registering frame.
+}
+
+// Names of the fields of the built-in Error struct.
+var (
+	errorMessage = runtime.NewObjString("message")
+	errorFile    = runtime.NewObjString("file")
+	errorLine    = runtime.NewObjString("line")
+	errorStack   = runtime.NewObjString("stack")
+	errorValue   = runtime.NewObjString("value")
+)
+
+// defineErrorStruct registers the built-in Error struct, whose instances are the values raised
+// by runtime errors and caught by 'catch'. Scripts can create and throw them as well.
+func defineErrorStruct() {
+	name := runtime.NewObjString("Error")
+	vm.errorStruct = runtime.NewStruct(name)
+	for _, field := range []*runtime.ObjString{errorMessage, errorFile, errorLine, errorStack, errorValue} {
+		vm.errorStruct.Fields[field] = runtime.Value{Type: runtime.VAL_NULL}
+	}
+	vm.builtins[name] = runtime.ObjVal(vm.errorStruct)
+}
+
+// newError creates an Error carrying the message and the location of the current instruction.
+func newError(message string) *runtime.ObjInstance {
+	err := runtime.NewInstance(vm.errorStruct)
+	err.Fields[errorMessage] = runtime.ObjVal(runtime.NewObjString(message))
+	setErrorLocation(err)
+	return err
+}
+
+// setErrorLocation fills in the file, line and stack trace of an Error from the active call
+// frames. The stack trace lists the innermost frame first. The frame of a match expression is
+// shown as the function the match is written in, at the line it is running.
+func setErrorLocation(err *runtime.ObjInstance) {
+	trace := make([]runtime.Value, 0, vm.frameCount)
+	inlineLine := 0
+	for i := vm.frameCount - 1; i >= 0; i-- {
+		frame := &vm.frames[i]
+		function := frame.closure.Function
+		line := frameLine(frame)
+		if i == vm.frameCount-1 {
+			if function.File != nil {
+				err.Fields[errorFile] = runtime.ObjVal(function.File)
+			}
+			err.Fields[errorLine] = runtime.Value{Type: runtime.VAL_INT, Int: int64(line)}
+		}
+		if inlineLine > 0 {
+			line = inlineLine
+		}
+		if function.Inline {
+			inlineLine = line
+			continue
+		}
+		inlineLine = 0
+		where := "top-level script"
+		if function.Name != nil {
+			where = fmt.Sprintf("function '%s()'", function.Name.Chars)
+		}
+		// Frames of imported files name the file; the frame below the top-level script of an
+		// imported file is the import that runs it.
+		location := fmt.Sprintf("[line %d]", line)
+		if function.Module != nil {
+			location = fmt.Sprintf("[%s, line %d]", function.File.Chars, line)
+		}
+		trace = append(trace, runtime.ObjVal(runtime.NewObjString(location+" in "+where)))
+	}
+	err.Fields[errorStack] = runtime.ObjVal(runtime.NewArray(trace))
+}
+
+// frameLine returns the line of the instruction a call frame is running.
+func frameLine(frame *CallFrame) int {
+	return frame.closure.Function.Chunk.Lines()[max(frame.ip-1, 0)]
+}
+
+// throwValue raises a value thrown by a script. An Error is raised as is, keeping its location
+// when it is being rethrown; any other value is wrapped in an Error whose 'value' field holds it.
+func throwValue(value runtime.Value) InterpretResult {
+	if err, ok := value.Obj.(*runtime.ObjInstance); ok && err.IsInstanceOf(vm.errorStruct) {
+		if err.Fields[errorStack].Type == runtime.VAL_NULL {
+			setErrorLocation(err)
+		}
+		vm.pendingError = err
+		return INTERPRET_RUNTIME_ERROR
+	}
+	message := toStr(1, []runtime.Value{value}).Obj.(*runtime.ObjString).Chars
+	err := newError(message)
+	err.Fields[errorValue] = value
+	vm.pendingError = err
+	return INTERPRET_RUNTIME_ERROR
+}
+
+// catchError unwinds to the innermost exception handler, closing the upvalues of the discarded
+// stack slots, and resumes at the handler with the pending error on top of the stack. It returns
+// false when no handler is registered.
+func catchError() bool {
+	if len(vm.handlers) == 0 {
+		return false
+	}
+	handler := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	closeUpvalues(&vm.stack[handler.stackTop])
+	stopGenerators(handler.frameCount)
+	vm.frameCount = handler.frameCount
+	vm.stackTop = handler.stackTop
+	vm.frames[vm.frameCount-1].ip = handler.address
+	vm.argNames = nil
+	Push(runtime.ObjVal(vm.pendingError))
+	vm.pendingError = nil
+	return true
+}
+
+// reportUncaughtError prints an error no handler caught, with its stack trace, and resets the VM.
+func reportUncaughtError() {
+	err := vm.pendingError
+	fmt.Fprintf(os.Stderr, "Runtime Error: %s\n", toStr(1, []runtime.Value{err.Fields[errorMessage]}).Obj.(*runtime.ObjString).Chars)
+	if trace, ok := err.Fields[errorStack].Obj.(*runtime.ObjArray); ok {
+		for _, entry := range trace.Elements {
+			fmt.Fprintf(os.Stderr, "  at %s\n", toStr(1, []runtime.Value{entry}).Obj.(*runtime.ObjString).Chars)
+		}
+	}
+	resetStack()
+}
+
+// errorToString formats an Error as its struct name followed by its message.
+func errorToString(err *runtime.ObjInstance) string {
+	message := toStr(1, []runtime.Value{err.Fields[errorMessage]}).Obj.(*runtime.ObjString).Chars
+	return err.Structure.Name.Chars + ": " + message
+}
@@ -234,10 +234,9 @@ var typeToCode = map[string]C.TypeCode{
 // arguments and return values to C types using libffi, and handling type validation and errors.
 func createNativeFunc(funcName string, cFunc unsafe.Pointer, returnType string, paramTypes []string) *runtime.ObjNative {
 	return &runtime.ObjNative{
-		Function: func(argCount int, args []runtime.Value) runtime.Value {
+		Function: func(argCount int, args []runtime.Value) (runtime.Value, error) {
 			if argCount != len(paramTypes) {
-				runtimeError("Function '%s' expects %d arguments but got %d.", funcName, len(paramTypes), argCount)
-				return runtime.Value{Type: runtime.VAL_NULL}
+				return nativeError("Function '%s' expects %d arguments but got %d.", funcName, len(paramTypes), argCount)
 			}
 
 			// Map parameter types to TypeCode
@@ -245,8 +244,7 @@ func createNativeFunc(funcName string, cFunc unsafe.Pointer, returnType string,
 			for i, pt := range paramTypes {
 				code, ok := typeToCode[pt]
 				if !ok {
-					runtimeError("Unsupported parameter type '%s' for '%s'.", pt, funcName)
-					return runtime.Value{Type: runtime.VAL_NULL}
+					return nativeError("Unsupported parameter type '%s' for '%s'.", pt, funcName)
 				}
 				cParamTypes[i] = code
 			}
@@ -254,8 +252,7 @@ func createNativeFunc(funcName string, cFunc unsafe.Pointer, returnType string,
 			// Map return type to TypeCode
 			cReturnType, ok := typeToCode[returnType]
 			if !ok {
-				runtimeError("Unsupported return type '%s' for '%s'.", returnType, funcName)
-				return runtime.Value{Type: runtime.VAL_NULL}
+				return nativeError("Unsupported return type '%s' for '%s'.", returnType, funcName)
 			}
 
 			// Convert ZScript arguments to C arguments
@@ -265,187 +262,156 @@ func createNativeFunc(funcName string, cFunc unsafe.Pointer, returnType string,
 				cArgs[i].argType = cParamTypes[i]
 				switch pt {
 				case "int8_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*int8)(unsafe.Pointer(&cArgs[i].value[0])) = int8(args[i].Number)
+					*(*int8)(unsafe.Pointer(&cArgs[i].value[0])) = int8(runtime.AsInt(args[i]))
 				case "uint8_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint8)(unsafe.Pointer(&cArgs[i].value[0])) = uint8(args[i].Number)
+					*(*uint8)(unsafe.Pointer(&cArgs[i].value[0])) = uint8(runtime.AsInt(args[i]))
 				case "int16_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*int16)(unsafe.Pointer(&cArgs[i].value[0])) = int16(args[i].Number)
+					*(*int16)(unsafe.Pointer(&cArgs[i].value[0])) = int16(runtime.AsInt(args[i]))
 				case "uint16_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint16)(unsafe.Pointer(&cArgs[i].value[0])) = uint16(args[i].Number)
+					*(*uint16)(unsafe.Pointer(&cArgs[i].value[0])) = uint16(runtime.AsInt(args[i]))
 				case "int32_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*int32)(unsafe.Pointer(&cArgs[i].value[0])) = int32(args[i].Number)
+					*(*int32)(unsafe.Pointer(&cArgs[i].value[0])) = int32(runtime.AsInt(args[i]))
 				case "uint32_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint32)(unsafe.Pointer(&cArgs[i].value[0])) = uint32(args[i].Number)
+					*(*uint32)(unsafe.Pointer(&cArgs[i].value[0])) = uint32(runtime.AsInt(args[i]))
 				case "int64_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*int64)(unsafe.Pointer(&cArgs[i].value[0])) = int64(args[i].Number)
+					*(*int64)(unsafe.Pointer(&cArgs[i].value[0])) = runtime.AsInt(args[i])
 				case "uint64_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint64)(unsafe.Pointer(&cArgs[i].value[0])) = uint64(args[i].Number)
+					*(*uint64)(unsafe.Pointer(&cArgs[i].value[0])) = uint64(runtime.AsInt(args[i]))
 				case "float":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*float32)(unsafe.Pointer(&cArgs[i].value[0])) = float32(args[i].Number)
+					*(*float32)(unsafe.Pointer(&cArgs[i].value[0])) = float32(runtime.AsFloat(args[i]))
 				case "double":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*float64)(unsafe.Pointer(&cArgs[i].value[0])) = args[i].Number
+					*(*float64)(unsafe.Pointer(&cArgs[i].value[0])) = runtime.AsFloat(args[i])
 				case "float _Complex":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number (for real part).", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number (for real part).", i+1, funcName)
 					}
-					*(*float32)(unsafe.Pointer(&cArgs[i].value[0])) = float32(args[i].Number) // Real part only
+					*(*float32)(unsafe.Pointer(&cArgs[i].value[0])) = float32(runtime.AsFloat(args[i])) // Real part only
 				case "double _Complex":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number (for real part).", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number (for real part).", i+1, funcName)
 					}
-					*(*float64)(unsafe.Pointer(&cArgs[i].value[0])) = args[i].Number // Real part only
+					*(*float64)(unsafe.Pointer(&cArgs[i].value[0])) = runtime.AsFloat(args[i]) // Real part only
 				case "bool":
 					if args[i].Type != runtime.VAL_BOOL {
-						runtimeError("Argument %d of '%s' must be a boolean.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a boolean.", i+1, funcName)
 					}
 					*(*bool)(unsafe.Pointer(&cArgs[i].value[0])) = args[i].Bool
 				case "char":
 					if args[i].Type != runtime.VAL_OBJ {
-						runtimeError("Argument %d of '%s' must be a string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a string.", i+1, funcName)
 					}
 					objString, ok := args[i].Obj.(*runtime.ObjString)
 					if !ok {
-						runtimeError("Argument %d of '%s' must be a string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a string.", i+1, funcName)
 					}
 					s := objString.Chars
 					if len(s) != 1 {
-						runtimeError("Argument %d of '%s' must be a single-character string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a single-character string.", i+1, funcName)
 					}
 					*(*int8)(unsafe.Pointer(&cArgs[i].value[0])) = int8(s[0])
 				case "unsigned char":
 					if args[i].Type != runtime.VAL_OBJ {
-						runtimeError("Argument %d of '%s' must be a string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a string.", i+1, funcName)
 					}
 					objString, ok := args[i].Obj.(*runtime.ObjString)
 					if !ok {
-						runtimeError("Argument %d of '%s' must be a string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a string.", i+1, funcName)
 					}
 					s := objString.Chars
 					if len(s) != 1 {
-						runtimeError("Argument %d of '%s' must be a single-character string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a single-character string.", i+1, funcName)
 					}
 					*(*uint8)(unsafe.Pointer(&cArgs[i].value[0])) = uint8(s[0])
 				case "signed char":
 					if args[i].Type != runtime.VAL_OBJ {
-						runtimeError("Argument %d of '%s' must be a string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a string.", i+1, funcName)
 					}
 					objString, ok := args[i].Obj.(*runtime.ObjString)
 					if !ok {
-						runtimeError("Argument %d of '%s' must be a string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a string.", i+1, funcName)
 					}
 					s := objString.Chars
 					if len(s) != 1 {
-						runtimeError("Argument %d of '%s' must be a single-character string.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be a single-character string.", i+1, funcName)
 					}
 					*(*int8)(unsafe.Pointer(&cArgs[i].value[0])) = int8(s[0])
 				case "intptr_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*int)(unsafe.Pointer(&cArgs[i].value[0])) = int(args[i].Number)
+					*(*int)(unsafe.Pointer(&cArgs[i].value[0])) = int(runtime.AsInt(args[i]))
 				case "uintptr_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint)(unsafe.Pointer(&cArgs[i].value[0])) = uint(args[i].Number)
+					*(*uint)(unsafe.Pointer(&cArgs[i].value[0])) = uint(runtime.AsInt(args[i]))
 				case "intmax_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*int64)(unsafe.Pointer(&cArgs[i].value[0])) = int64(args[i].Number)
+					*(*int64)(unsafe.Pointer(&cArgs[i].value[0])) = runtime.AsInt(args[i])
 				case "uintmax_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint64)(unsafe.Pointer(&cArgs[i].value[0])) = uint64(args[i].Number)
+					*(*uint64)(unsafe.Pointer(&cArgs[i].value[0])) = uint64(runtime.AsInt(args[i]))
 				case "size_t":
-					if args[i].Type != runtime.VAL_NUMBER {
-						runtimeError("Argument %d of '%s' must be a number.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+					if !runtime.IsNumber(args[i]) {
+						return nativeError("Argument %d of '%s' must be a number.", i+1, funcName)
 					}
-					*(*uint64)(unsafe.Pointer(&cArgs[i].value[0])) = uint64(args[i].Number)
+					*(*uint64)(unsafe.Pointer(&cArgs[i].value[0])) = uint64(runtime.AsInt(args[i]))
 				case "char*":
 					if args[i].Type == runtime.VAL_NULL {
 						*(*unsafe.Pointer)(unsafe.Pointer(&cArgs[i].value[0])) = nil
 					} else if args[i].Type == runtime.VAL_OBJ {
 						objString, ok := args[i].Obj.(*runtime.ObjString)
 						if !ok {
-							runtimeError("Argument %d of '%s' must be null or a string for 'char*'.", i+1, funcName)
-							return runtime.Value{Type: runtime.VAL_NULL}
+							return nativeError("Argument %d of '%s' must be null or a string for 'char*'.", i+1, funcName)
 						}
 						cStr := C.CString(objString.Chars)
 						*(*unsafe.Pointer)(unsafe.Pointer(&cArgs[i].value[0])) = unsafe.Pointer(cStr)
 						cStrings = append(cStrings, unsafe.Pointer(cStr))
 					} else {
-						runtimeError("Argument %d of '%s' must be null or a string for 'char*'.", i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Argument %d of '%s' must be null or a string for 'char*'.", i+1, funcName)
 					}
 				default:
 					if strings.HasSuffix(pt, "*") {
 						if args[i].Type != runtime.VAL_NULL {
-							runtimeError("Argument %d of '%s' must be null for pointer type '%s'.", i+1, funcName, pt)
-							return runtime.Value{Type: runtime.VAL_NULL}
+							return nativeError("Argument %d of '%s' must be null for pointer type '%s'.", i+1, funcName, pt)
 						}
 						*(*unsafe.Pointer)(unsafe.Pointer(&cArgs[i].value[0])) = nil
 					} else {
-						runtimeError("Unsupported parameter type '%s' for argument %d of '%s'.", pt, i+1, funcName)
-						return runtime.Value{Type: runtime.VAL_NULL}
+						return nativeError("Unsupported parameter type '%s' for argument %d of '%s'.", pt, i+1, funcName)
 					}
 				}
 			}
@@ -466,63 +432,61 @@ func createNativeFunc(funcName string, cFunc unsafe.Pointer, returnType string,
 			// Convert return value back to ZScript
 			switch cReturnType {
 			case C.TYPE_VOID:
-				return runtime.Value{Type: runtime.VAL_NULL}
+				return runtime.Value{Type: runtime.VAL_NULL}, nil
 			case C.TYPE_INT8:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*int8)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*int8)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_UINT8:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint8)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint8)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_INT16:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*int16)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*int16)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_UINT16:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint16)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint16)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_INT32:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*int32)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*int32)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_UINT32:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint32)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint32)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_INT64:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*int64)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*int64)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_UINT64:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint64)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint64)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_FLOAT:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*float32)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*float32)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_DOUBLE:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: *(*float64)(unsafe.Pointer(&ret[0]))}
+				return runtime.Value{Type: runtime.VAL_NUMBER, Number: *(*float64)(unsafe.Pointer(&ret[0]))}, nil
 			case C.TYPE_FLOAT_COMPLEX:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*float32)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*float32)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_DOUBLE_COMPLEX:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: *(*float64)(unsafe.Pointer(&ret[0]))}
+				return runtime.Value{Type: runtime.VAL_NUMBER, Number: *(*float64)(unsafe.Pointer(&ret[0]))}, nil
 			case C.TYPE_BOOL:
-				return runtime.Value{Type: runtime.VAL_BOOL, Bool: *(*bool)(unsafe.Pointer(&ret[0]))}
+				return runtime.Value{Type: runtime.VAL_BOOL, Bool: *(*bool)(unsafe.Pointer(&ret[0]))}, nil
 			case C.TYPE_CHAR:
-				return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(string(rune(*(*int8)(unsafe.Pointer(&ret[0])))))}
+				return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(string(rune(*(*int8)(unsafe.Pointer(&ret[0])))))}, nil
 			case C.TYPE_UCHAR:
-				return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(string(rune(*(*uint8)(unsafe.Pointer(&ret[0])))))}
+				return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(string(rune(*(*uint8)(unsafe.Pointer(&ret[0])))))}, nil
 			case C.TYPE_SCHAR:
-				return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(string(rune(*(*int8)(unsafe.Pointer(&ret[0])))))}
+				return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(string(rune(*(*int8)(unsafe.Pointer(&ret[0])))))}, nil
 			case C.TYPE_INTPTR:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*int)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*int)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_UINTPTR:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_INTMAX:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*int64)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*int64)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_UINTMAX:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint64)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint64)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_SIZE:
-				return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(*(*uint64)(unsafe.Pointer(&ret[0])))}
+				return runtime.Value{Type: runtime.VAL_INT, Int: int64(*(*uint64)(unsafe.Pointer(&ret[0])))}, nil
 			case C.TYPE_PTR:
 				ptr := *(*unsafe.Pointer)(unsafe.Pointer(&ret[0]))
 				if ptr == nil {
-					return runtime.Value{Type: runtime.VAL_NULL}
+					return runtime.Value{Type: runtime.VAL_NULL}, nil
 				}
 				if returnType == "char*" {
-					return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(C.GoString((*C.char)(ptr)))}
+					return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(C.GoString((*C.char)(ptr)))}, nil
 				}
-				// For non-char* pointers, return a null value since we don’t have an opaque type
-				runtimeError("Non-char* pointer return type '%s' not fully supported; returning null.", returnType)
-				return runtime.Value{Type: runtime.VAL_NULL}
+				// Non-char* pointers cannot be represented since there is no opaque pointer type.
+				return nativeError("Non-char* pointer return type '%s' is not supported.", returnType)
 			default:
-				runtimeError("Unexpected return type code %d for '%s'.", cReturnType, funcName)
-				return runtime.Value{Type: runtime.VAL_NULL}
+				return nativeError("Unexpected return type code %d for '%s'.", cReturnType, funcName)
 			}
 		},
 	}
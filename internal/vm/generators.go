@@ -0,0 +1,166 @@
+package vm
+
+import (
+	"unsafe"
+
+	"github.com/cryptrunner49/zscript/internal/runtime"
+)
+
+// Name of the generator method that resumes a generator with a value.
+var generatorSend = runtime.NewObjString("send")
+
+// invokeGenerator calls a method of a generator. next() resumes it and returns the value it
+// yields next, or the value it returns when it ends instead; send(value) does the same, with the
+// paused 'yield' evaluating to value. done() reports whether the generator has ended, which it
+// can only know by running it up to its next 'yield': the value yielded there is kept for the
+// next call of next(), so 'iter' loops walk generators like any other iterator.
+func invokeGenerator(generator *runtime.ObjGenerator, name *runtime.ObjString, argCount int) bool {
+	expected := 0
+	switch name {
+	case iteratorDone, iteratorNext:
+	case generatorSend:
+		expected = 1
+	default:
+		runtimeError("Generators have no method '%s'; only 'done', 'next' and 'send'.", name.Chars)
+		return false
+	}
+	if argCount != expected {
+		runtimeError("Generator method '%s' expects %d arguments but got %d.", name.Chars, expected, argCount)
+		return false
+	}
+	if generator.State == runtime.GENERATOR_RUNNING {
+		runtimeError("Generator '%s' is already running.", generator.Closure.Function.Name.Chars)
+		return false
+	}
+
+	if name == iteratorDone {
+		if generator.State == runtime.GENERATOR_DONE || generator.Peeked != nil {
+			vm.stack[vm.stackTop-1] = runtime.Value{Type: runtime.VAL_BOOL, Bool: generator.Peeked == nil}
+			return true
+		}
+		return resumeGenerator(generator, runtime.Value{Type: runtime.VAL_NULL}, true)
+	}
+	sent := runtime.Value{Type: runtime.VAL_NULL}
+	if name == generatorSend {
+		sent = Pop()
+		if generator.Peeked != nil {
+			runtimeError("Cannot send to generator '%s'; done() already resumed it to its next value.", generator.Closure.Function.Name.Chars)
+			return false
+		}
+	}
+	if generator.Peeked != nil {
+		vm.stack[vm.stackTop-1] = *generator.Peeked
+		generator.Peeked = nil
+		return true
+	}
+	if generator.State == runtime.GENERATOR_DONE {
+		runtimeError("Generator '%s' has already finished.", generator.Closure.Function.Name.Chars)
+		return false
+	}
+	return resumeGenerator(generator, sent, false)
+}
+
+// resumeGenerator puts a paused generator's frame back on top of the call stack, above the
+// receiver slot that will take the result. Its slots are copied back onto the stack, the upvalues
+// closed when it paused are reopened on them and its exception handlers are registered again. A
+// generator resumed at a 'yield' finds the sent value on top of its stack as the value of the
+// yield expression.
+func resumeGenerator(generator *runtime.ObjGenerator, sent runtime.Value, lookahead bool) bool {
+	if vm.frameCount >= FRAMES_MAX {
+		runtimeError("Stack overflow; too many nested function calls (max %d).", FRAMES_MAX)
+		return false
+	}
+	base := vm.stackTop
+	vm.stackTop += copy(vm.stack[base:], generator.Slots)
+	// The reopened upvalues point above every other open upvalue, so they go first in the list.
+	for i := len(generator.Upvalues) - 1; i >= 0; i-- {
+		upvalue := generator.Upvalues[i]
+		slot := &vm.stack[base+generator.UpvalueSlots[i]]
+		*slot = upvalue.Closed
+		upvalue.Location = slot
+		upvalue.Next = vm.openUpvalues
+		vm.openUpvalues = upvalue
+	}
+
+	frame := &vm.frames[vm.frameCount]
+	vm.frameCount++
+	frame.closure = generator.Closure
+	frame.ip = generator.IP
+	frame.slots = base
+	frame.generator = generator
+	frame.lookahead = lookahead
+	for _, handler := range generator.Handlers {
+		vm.handlers = append(vm.handlers, ExceptionHandler{
+			frameCount: vm.frameCount,
+			stackTop:   base + handler.StackOffset,
+			address:    handler.Address,
+		})
+	}
+
+	if generator.State == runtime.GENERATOR_SUSPENDED {
+		Push(sent)
+	}
+	generator.State = runtime.GENERATOR_RUNNING
+	generator.Slots, generator.Upvalues, generator.UpvalueSlots, generator.Handlers = nil, nil, nil, nil
+	return true
+}
+
+// suspendGenerator takes the frame of a generator pausing at a 'yield' off the call stack and
+// saves it in the generator, leaving the caller's receiver slot on top of the stack.
+func suspendGenerator(frame *CallFrame) {
+	generator := frame.generator
+	generator.State = runtime.GENERATOR_SUSPENDED
+	generator.IP = frame.ip
+	generator.Slots = append([]runtime.Value(nil), vm.stack[frame.slots:vm.stackTop]...)
+
+	// Close the upvalues of the frame's slots, remembering their slots to reopen them on resume.
+	first := &vm.stack[frame.slots]
+	for vm.openUpvalues != nil && uintptr(unsafe.Pointer(vm.openUpvalues.Location)) >= uintptr(unsafe.Pointer(first)) {
+		upvalue := vm.openUpvalues
+		slot := int((uintptr(unsafe.Pointer(upvalue.Location)) - uintptr(unsafe.Pointer(first))) / unsafe.Sizeof(*first))
+		generator.Upvalues = append(generator.Upvalues, upvalue)
+		generator.UpvalueSlots = append(generator.UpvalueSlots, slot)
+		upvalue.Closed = *upvalue.Location
+		upvalue.Location = &upvalue.Closed
+		vm.openUpvalues = upvalue.Next
+	}
+
+	// Set aside the handlers of the 'try' blocks the generator is paused inside.
+	start := len(vm.handlers)
+	for start > 0 && vm.handlers[start-1].frameCount == vm.frameCount {
+		start--
+	}
+	for _, handler := range vm.handlers[start:] {
+		generator.Handlers = append(generator.Handlers, runtime.GeneratorHandler{
+			StackOffset: handler.stackTop - frame.slots,
+			Address:     handler.address,
+		})
+	}
+	vm.handlers = vm.handlers[:start]
+
+	vm.frameCount--
+	vm.stackTop = frame.slots - 1
+}
+
+// generatorResult is the result of the method call that resumed a generator, once the generator
+// yields value or ends returning it. For done() it is whether the generator ended; a yielded
+// value is kept for next().
+func generatorResult(frame *CallFrame, value runtime.Value, finished bool) runtime.Value {
+	if !frame.lookahead {
+		return value
+	}
+	if !finished {
+		frame.generator.Peeked = &value
+	}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: finished}
+}
+
+// stopGenerators ends the generators running in the frames above the given count, which an error
+// is unwinding or a reset is discarding.
+func stopGenerators(frameCount int) {
+	for i := frameCount; i < vm.frameCount; i++ {
+		if generator := vm.frames[i].generator; generator != nil {
+			generator.State = runtime.GENERATOR_DONE
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cryptrunner49/zscript/internal/runtime"
+)
+
+// Names of the methods of the iterator protocol.
+var (
+	iteratorDone = runtime.NewObjString("done")
+	iteratorNext = runtime.NewObjString("next")
+)
+
+// newIterator returns the iterator an 'iter' loop walks for a value. Arrays, strings, maps, enums
+// and ranges get a native iterator; native iterators, generators and instances of structs with
+// 'done' and 'next' methods are iterators already. With entries set, as for 'var k, v' loops, sequences
+// produce [index, value] pairs and maps [key, value] pairs instead of their values and keys.
+func newIterator(value runtime.Value, entries bool) (runtime.Value, error) {
+	switch obj := value.Obj.(type) {
+	case *runtime.ObjArray:
+		return sliceIterator(func() []runtime.Value { return obj.Elements }, entries), nil
+	case *runtime.ObjString:
+		runes := []rune(obj.Chars)
+		chars := make([]runtime.Value, len(runes))
+		for i, r := range runes {
+			chars[i] = runtime.ObjVal(runtime.NewObjString(string(r)))
+		}
+		return sliceIterator(func() []runtime.Value { return chars }, entries), nil
+	case *runtime.ObjEnum:
+		variants := make([]runtime.Value, len(obj.Variants))
+		for i, variant := range obj.Variants {
+			variants[i] = runtime.ObjVal(variant)
+		}
+		return sliceIterator(func() []runtime.Value { return variants }, entries), nil
+	case *runtime.ObjMap:
+		return mapIterator(obj, entries), nil
+	case *runtime.ObjRange:
+		length, _ := obj.Len()
+		return indexIterator(obj.ValueAt, func() int { return length }, entries), nil
+	case *runtime.ObjArrayIterator:
+		return runtime.ObjVal(runtime.NewIterator(
+			func() bool { return obj.Index >= len(obj.Array.Elements) },
+			func() runtime.Value {
+				element := obj.Array.Elements[obj.Index]
+				obj.Index++
+				return element
+			},
+		)), nil
+	case *runtime.ObjIterator, *runtime.ObjGenerator:
+		return value, nil
+	case *runtime.ObjInstance:
+		_, hasDone := obj.Structure.Methods[iteratorDone]
+		_, hasNext := obj.Structure.Methods[iteratorNext]
+		if !hasDone || !hasNext {
+			return value, fmt.Errorf("Cannot iterate over an instance of '%s'; it needs 'done' and 'next' methods.", obj.Structure.Name.Chars)
+		}
+		return value, nil
+	}
+	return value, fmt.Errorf("Cannot iterate over %s.", typeName(value))
+}
+
+// sliceIterator walks the values returned by elements by position. Elements is called on every
+// step so that an array growing or shrinking during the loop is followed.
+func sliceIterator(elements func() []runtime.Value, entries bool) runtime.Value {
+	return indexIterator(func(index int) runtime.Value {
+		return elements()[index]
+	}, func() int {
+		return len(elements())
+	}, entries)
+}
+
+// indexIterator walks the values at positions 0 up to length(), produced by at.
+func indexIterator(at func(int) runtime.Value, length func() int, entries bool) runtime.Value {
+	index := 0
+	return runtime.ObjVal(runtime.NewIterator(
+		func() bool { return index >= length() },
+		func() runtime.Value {
+			element := at(index)
+			index++
+			if entries {
+				return entryPair(runtime.Value{Type: runtime.VAL_INT, Int: int64(index - 1)}, element)
+			}
+			return element
+		},
+	))
+}
+
+// mapIterator walks the keys of a map, or its [key, value] pairs, in sorted key order. The keys
+// are taken when the loop starts; keys removed during the loop are skipped.
+func mapIterator(mapObj *runtime.ObjMap, entries bool) runtime.Value {
+	keys := make([]*runtime.ObjString, 0, len(mapObj.Entries))
+	for key := range mapObj.Entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Chars < keys[j].Chars })
+	index := 0
+	skipRemoved := func() {
+		for index < len(keys) {
+			if _, found := mapObj.Entries[keys[index]]; found {
+				return
+			}
+			index++
+		}
+	}
+	return runtime.ObjVal(runtime.NewIterator(
+		func() bool {
+			skipRemoved()
+			return index >= len(keys)
+		},
+		func() runtime.Value {
+			skipRemoved()
+			key := keys[index]
+			index++
+			if entries {
+				return entryPair(runtime.ObjVal(key), mapObj.Entries[key])
+			}
+			return runtime.ObjVal(key)
+		},
+	))
+}
+
+// entryPair builds the [key, value] array produced for 'var k, v' loops.
+func entryPair(key runtime.Value, value runtime.Value) runtime.Value {
+	return runtime.ObjVal(runtime.NewArray([]runtime.Value{key, value}))
+}
+
+// invokeIterator calls the done() or next() method of a native iterator, replacing the receiver
+// on the stack with the result.
+func invokeIterator(iterator *runtime.ObjIterator, name *runtime.ObjString, argCount int) bool {
+	if name != iteratorDone && name != iteratorNext {
+		runtimeError("Iterators have no method '%s'; only 'done' and 'next'.", name.Chars)
+		return false
+	}
+	if argCount != 0 {
+		runtimeError("Iterator method '%s' expects 0 arguments but got %d.", name.Chars, argCount)
+		return false
+	}
+	if name == iteratorDone {
+		vm.stack[vm.stackTop-1] = runtime.Value{Type: runtime.VAL_BOOL, Bool: iterator.Done()}
+		return true
+	}
+	if iterator.Done() {
+		runtimeError("Iterator has no more values.")
+		return false
+	}
+	vm.stack[vm.stackTop-1] = iterator.Next()
+	return true
+}
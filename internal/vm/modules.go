@@ -0,0 +1,204 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cryptrunner49/zscript/internal/compiler"
+	"github.com/cryptrunner49/zscript/internal/runtime"
+)
+
+// MODULE_DIR is the directory searched for modules next to the importing file and in each of its
+// parent directories, so a project can keep its libraries at its root.
+const MODULE_DIR = "zscript_modules"
+
+// MODULE_INDEX is the file loaded when an import names a directory.
+const MODULE_INDEX = "mod.z"
+
+// globalScope returns the globals the running function reads and defines, with the names among
+// them declared with 'const': the fields of its file's namespace for a function compiled from an
+// imported file, the globals of the main script otherwise.
+func globalScope(frame *CallFrame) (map[*runtime.ObjString]runtime.Value, map[*runtime.ObjString]bool) {
+	if module := frame.closure.Function.Module; module != nil {
+		return module.Fields, module.Constants
+	}
+	return vm.globals, vm.constGlobals
+}
+
+// AddLibPath adds a directory to the search path of imports, after the importing file's directory
+// and the zscript_modules directories and before the directories listed in ZSCRIPT_PATH.
+func AddLibPath(dir string) {
+	vm.libPaths = append(vm.libPaths, dir)
+}
+
+// searchPath returns the directories an import of specifier from the file at importer is looked
+// up in, in order. A specifier starting with './' or '../' is only looked up next to the importer.
+func searchPath(specifier string, importer string) []string {
+	base := filepath.Dir(importer)
+	if isRelativeSpecifier(specifier) {
+		return []string{base}
+	}
+	dirs := []string{base}
+	if dir, err := filepath.Abs(base); err == nil {
+		for {
+			dirs = append(dirs, filepath.Join(dir, MODULE_DIR))
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	dirs = append(dirs, vm.libPaths...)
+	for _, dir := range filepath.SplitList(os.Getenv("ZSCRIPT_PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// isRelativeSpecifier reports whether an import names a file relative to the importing one.
+func isRelativeSpecifier(specifier string) bool {
+	return specifier == "." || specifier == ".." ||
+		strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../")
+}
+
+// moduleFile returns the script path refers to: the file itself, the file with the '.z' suffix
+// added, or the mod.z index of the directory.
+func moduleFile(path string) (string, bool) {
+	if info, err := os.Stat(path); err == nil {
+		if !info.IsDir() {
+			return path, true
+		}
+		index := filepath.Join(path, MODULE_INDEX)
+		if info, err := os.Stat(index); err == nil && !info.IsDir() {
+			return index, true
+		}
+	}
+	if filepath.Ext(path) != ".z" {
+		if info, err := os.Stat(path + ".z"); err == nil && !info.IsDir() {
+			return path + ".z", true
+		}
+	}
+	return "", false
+}
+
+// resolveImport returns the absolute path of the script an import of specifier from the file at
+// importer loads, searching the directories of searchPath in order.
+func resolveImport(specifier string, importer string) (string, error) {
+	var searched []string
+	if filepath.IsAbs(specifier) {
+		searched = []string{specifier}
+	} else {
+		for _, dir := range searchPath(specifier, importer) {
+			searched = append(searched, filepath.Join(dir, specifier))
+		}
+	}
+	for _, path := range searched {
+		if file, found := moduleFile(path); found {
+			return filepath.Abs(file)
+		}
+	}
+	return "", fmt.Errorf("Cannot find module '%s'; looked for %s.", specifier, strings.Join(searched, ", "))
+}
+
+// moduleName returns the name of the namespace of the script at path: the file name without its
+// suffix, or the directory name for a mod.z index.
+func moduleName(path string) string {
+	if filepath.Base(path) == MODULE_INDEX {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// importModule pushes the namespace of the script an import of specifier from the running
+// function refers to. A file is only run on its first import: it is compiled into a new namespace
+// and called, and returns the namespace when it has finished. Later imports of the same file, by
+// any specifier, push the same namespace straight away. A file that fails to compile or raises an
+// error while it runs is not kept, and importing a file while it is still running is an error.
+func importModule(frame *CallFrame, specifier *runtime.ObjString) InterpretResult {
+	resolved, err := resolveImport(specifier.Chars, frame.closure.Function.File.Chars)
+	if err != nil {
+		return runtimeError("%s", err.Error())
+	}
+	path := runtime.NewObjString(resolved)
+	if module, found := vm.modules[path]; found {
+		Push(runtime.ObjVal(module))
+		return INTERPRET_OK
+	}
+	if chain, circular := importChain(path); circular {
+		return runtimeError("Circular import of '%s': %s.", specifier.Chars, chain)
+	}
+	content, err := os.ReadFile(path.Chars)
+	if err != nil {
+		return runtimeError("Failed to load module '%s': %v", path.Chars, err)
+	}
+	module := runtime.NewModule(runtime.NewObjString(moduleName(path.Chars)))
+	module.Exports = make(map[*runtime.ObjString]bool)
+	function := compiler.CompileModule(string(content), path.Chars, module)
+	if function == nil {
+		return runtimeError("Cannot import '%s'; '%s' has compile errors.", specifier.Chars, path.Chars)
+	}
+	closure := runtime.ObjVal(runtime.NewClosure(function))
+	Push(closure)
+	if !callValue(closure, 0) {
+		return INTERPRET_RUNTIME_ERROR
+	}
+	return INTERPRET_OK
+}
+
+// importChain describes the scripts the active imports are running, from the main script on, each
+// with the line it is running, followed by path. It reports whether path is one of those scripts,
+// which importing it again would run inside its own import.
+func importChain(path *runtime.ObjString) (string, bool) {
+	var files []string
+	var lines []int
+	imported := path.Chars
+	circular := false
+	for i := 0; i < vm.frameCount; i++ {
+		frame := &vm.frames[i]
+		function := frame.closure.Function
+		if function.Name == nil {
+			// The top-level script of a file: the main script or an imported one.
+			if file, err := filepath.Abs(function.File.Chars); err == nil && file == path.Chars {
+				imported = function.File.Chars
+				circular = true
+			}
+			files = append(files, function.File.Chars)
+			lines = append(lines, 0)
+		}
+		// The line of the last frame running before the next script is the import that runs it.
+		lines[len(lines)-1] = frameLine(frame)
+	}
+	links := make([]string, len(files), len(files)+1)
+	for i, file := range files {
+		links[i] = fmt.Sprintf("%s (line %d)", file, lines[i])
+	}
+	return strings.Join(append(links, imported), " -> "), circular
+}
+
+// importExports defines the names an imported file exports as globals of the running function.
+func importExports(frame *CallFrame, module *runtime.ObjModule) InterpretResult {
+	globals, constants := globalScope(frame)
+	for name := range module.Exports {
+		if constants[name] {
+			return runtimeError("Cannot redeclare constant '%s'.", name.Chars)
+		}
+		globals[name] = module.Fields[name]
+	}
+	return INTERPRET_OK
+}
+
+// checkExports reports an error when an imported file has finished running without defining a
+// name it exports.
+func checkExports(module *runtime.ObjModule) InterpretResult {
+	for name := range module.Exports {
+		if _, found := module.Fields[name]; !found {
+			return runtimeError("Module '%s' exports '%s' but does not define it.", module.Name.Chars, name.Chars)
+		}
+	}
+	return INTERPRET_OK
+}
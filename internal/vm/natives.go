@@ -20,15 +20,6 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// unescapeString replaces common escape sequences like \n with their actual characters.
-func unescapeString(s string) string {
-	s = strings.ReplaceAll(s, `\n`, "\n")
-	s = strings.ReplaceAll(s, `\t`, "\t")
-	s = strings.ReplaceAll(s, `\\`, "\\")
-	// Add more escape sequences as needed
-	return s
-}
-
 // defineAllNatives registers all native functions (built-in functions) to the VM.
 func defineAllNatives() {
 	// Debug
@@ -40,7 +31,7 @@ func defineAllNatives() {
 	defineNative("disable_trace", disableTraceExecution)
 
 	// String
-	defineNative("to_str", toStr)
+	defineNative("to_str", toStrNative)
 	defineNative("to_chars", toCharsNative)
 	defineNative("char_at", charAtNative)
 	defineNative("substring", substringNative)
@@ -79,6 +70,8 @@ func defineAllNatives() {
 	defineNative("iter_next", iterNextNative)
 	defineNative("iter_value", iterValueNative)
 	defineNative("iter_done", iterDoneNative)
+	defineNative("iterator", iteratorNative)
+	defineNative("range", rangeNative)
 
 	// Map
 	defineNative("map_remove", mapRemoveNative)
@@ -149,88 +142,113 @@ func defineAllNatives() {
 	// Utility Functions
 	defineNative("parse_int", parseIntNative)
 
+	// Numbers
+	defineNative("bigint", bigintNative)
+	defineNative("decimal", decimalNative)
+	defineNative("decimal_round", decimalRoundNative)
+	defineNative("decimal_precision", decimalPrecisionNative)
+	defineNative("decimal_rounding", decimalRoundingNative)
+	defineNative("to_int", toIntNative)
+	defineNative("to_float", toFloatNative)
+
 	// Types
 	defineNative("get_runtype", getRunTypeNative)
+	defineNative("is_int", isIntNative)
+	defineNative("is_instance", isInstanceNative)
 
 	// Others
 	defineNative("clock", clockNative)
 }
 
-// defineNative registers a single native function in the VM's global table.
+// defineNative registers a single native function in the VM's table of built-ins.
 // It creates a string object for the function name, wraps the native function in an ObjNative,
-// and then stores it in the globals map.
+// and then stores it in the built-ins map, which every file can read.
 func defineNative(name string, function runtime.NativeFn) {
 	nameObj := runtime.NewObjString(name)
 	Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: nameObj})
 	nativeObj := &runtime.ObjNative{Function: function}
 	Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: nativeObj})
-	vm.globals[nameObj] = vm.stack[vm.stackTop-1]
+	vm.builtins[nameObj] = vm.stack[vm.stackTop-1]
 	Pop()
 	Pop()
 }
 
+// nativeError returns the result of a native function that failed with the formatted message.
+// The VM raises the error as a runtime error instead of pushing a result.
+func nativeError(format string, args ...interface{}) (runtime.Value, error) {
+	return runtime.Value{Type: runtime.VAL_NULL}, fmt.Errorf(format, args...)
+}
+
 // ============================================================================
 // Native Functions: Debug
 // ============================================================================
 
 // enableDebugPrint turns on bytecode debug printing.
-func enableDebugPrint(argCount int, args []runtime.Value) runtime.Value {
+func enableDebugPrint(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
+		return nativeError("'enable_debug' expects no arguments.")
 	}
 	common.DebugPrintCode = true
-	return runtime.Value{}
+	return runtime.Value{}, nil
 }
 
 // enableDebugIndent turns on debug indentation.
-func enableDebugIndent(argCount int, args []runtime.Value) runtime.Value {
+func enableDebugIndent(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
+		return nativeError("'enable_debug_indent' expects no arguments.")
 	}
 	common.DebugIndent = true
-	return runtime.Value{}
+	return runtime.Value{}, nil
 }
 
 // enableTraceExecution turns on instruction-level execution tracing.
-func enableTraceExecution(argCount int, args []runtime.Value) runtime.Value {
+func enableTraceExecution(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
+		return nativeError("'enable_trace' expects no arguments.")
 	}
 	common.DebugTraceExecution = true
-	return runtime.Value{}
+	return runtime.Value{}, nil
 }
 
 // disableDebugPrint turns off bytecode debug printing.
-func disableDebugPrint(argCount int, args []runtime.Value) runtime.Value {
+func disableDebugPrint(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
+		return nativeError("'disable_debug' expects no arguments.")
 	}
 	common.DebugPrintCode = false
-	return runtime.Value{}
+	return runtime.Value{}, nil
 }
 
 // disableDebugIndent turns off debug indentation.
-func disableDebugIndent(argCount int, args []runtime.Value) runtime.Value {
+func disableDebugIndent(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
+		return nativeError("'disable_debug_indent' expects no arguments.")
 	}
 	common.DebugIndent = false
-	return runtime.Value{}
+	return runtime.Value{}, nil
 }
 
 // disableTraceExecution turns off instruction-level execution tracing.
-func disableTraceExecution(argCount int, args []runtime.Value) runtime.Value {
+func disableTraceExecution(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
+		return nativeError("'disable_trace' expects no arguments.")
 	}
 	common.DebugTraceExecution = false
-	return runtime.Value{}
+	return runtime.Value{}, nil
 }
 
 // ============================================================================
 // Native Functions: String Operations
 // ============================================================================
 
+func toStrNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("'to_str' expects 1 argument.")
+	}
+	return toStr(argCount, args), nil
+}
+
+// toStr converts a value to its string representation.
 func toStr(argCount int, args []runtime.Value) runtime.Value {
 	if argCount != 1 {
 		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: to_str expects 1 argument")}
@@ -248,6 +266,8 @@ func toStr(argCount int, args []runtime.Value) runtime.Value {
 		str = "null"
 	case runtime.VAL_NUMBER:
 		str = fmt.Sprintf("%g", value.Number)
+	case runtime.VAL_INT:
+		str = strconv.FormatInt(value.Int, 10)
 	case runtime.VAL_OBJ:
 		switch obj := value.Obj.(type) {
 		case *runtime.ObjString:
@@ -257,7 +277,11 @@ func toStr(argCount int, args []runtime.Value) runtime.Value {
 		case *runtime.ObjMap:
 			str = mapToString(obj)
 		case *runtime.ObjInstance:
-			str = instanceToString(obj)
+			if obj.IsInstanceOf(vm.errorStruct) {
+				str = errorToString(obj)
+			} else {
+				str = instanceToString(obj)
+			}
 		case *runtime.ObjDate:
 			str = dateToString(obj)
 		case *runtime.ObjTime:
@@ -270,6 +294,8 @@ func toStr(argCount int, args []runtime.Value) runtime.Value {
 			} else {
 				str = "<fn>"
 			}
+		case *runtime.ObjBoundMethod:
+			str = "<fn " + obj.Method.Function.Name.Chars + ">"
 		case *runtime.ObjClosure:
 			if obj.Function.Name != nil {
 				str = "<fn " + obj.Function.Name.Chars + ">"
@@ -280,8 +306,24 @@ func toStr(argCount int, args []runtime.Value) runtime.Value {
 			str = "<native fn>"
 		case *runtime.ObjStruct:
 			str = "<struct " + obj.Name.Chars + ">"
+		case *runtime.ObjEnum:
+			str = "<enum " + obj.Name.Chars + ">"
+		case *runtime.ObjModule:
+			str = "<mod " + obj.Name.Chars + ">"
+		case *runtime.ObjEnumValue:
+			str = enumValueToString(obj)
 		case *runtime.ObjUpvalue:
 			str = "<upvalue>"
+		case *runtime.ObjIterator:
+			str = "<iterator>"
+		case *runtime.ObjGenerator:
+			str = "<generator " + obj.Closure.Function.Name.Chars + ">"
+		case *runtime.ObjRange:
+			str = obj.String()
+		case *runtime.ObjBigInt:
+			str = obj.String()
+		case *runtime.ObjDecimal:
+			str = obj.String()
 		default:
 			str = "<object>"
 		}
@@ -358,6 +400,29 @@ func instanceToString(instance *runtime.ObjInstance) string {
 	return sb.String()
 }
 
+func enumValueToString(value *runtime.ObjEnumValue) string {
+	var sb strings.Builder
+	sb.WriteString(value.Enum.Name.Chars)
+	sb.WriteString("::")
+	sb.WriteString(value.Name.Chars)
+	if value.Payload != nil {
+		sb.WriteString("(")
+		for i, elem := range value.Payload {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			strVal := toStr(1, []runtime.Value{elem})
+			if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
+				sb.WriteString(strObj.Chars)
+			} else {
+				sb.WriteString("error")
+			}
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
 func dateToString(date *runtime.ObjDate) string {
 	return date.Time.Format("2006-01-02")
 }
@@ -370,65 +435,56 @@ func dateTimeToString(dateTime *runtime.ObjDateTime) string {
 	return dateTime.Time.Format("2006-01-02 15:04:05")
 }
 
-func toCharsNative(argCount int, args []runtime.Value) runtime.Value {
+func toCharsNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("to_chars() expects exactly 1 argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("to_chars() expects exactly 1 argument")
 	}
 	strVal := args[0]
 	if strVal.Type != runtime.VAL_OBJ {
-		runtimeError("to_chars() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("to_chars() expects a string argument")
 	}
 	strObj, ok := strVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("to_chars() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("to_chars() expects a string argument")
 	}
 	chars := make([]runtime.Value, len(strObj.Chars))
 	for i, r := range strObj.Chars {
 		chars[i] = runtime.ObjVal(runtime.NewObjString(string(r)))
 	}
-	return runtime.ObjVal(runtime.NewArray(chars))
+	return runtime.ObjVal(runtime.NewArray(chars)), nil
 }
 
-func charAtNative(argCount int, args []runtime.Value) runtime.Value {
+func charAtNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'char_at' expects 2 arguments: a string and an index.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'char_at' expects 2 arguments: a string and an index.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'char_at' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'char_at' requires a string as first argument.")
 	}
-	if args[1].Type != runtime.VAL_NUMBER {
-		runtimeError("'char_at' requires a number as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[1]) {
+		return nativeError("'char_at' requires a number as second argument.")
 	}
-	index := int(args[1].Number)
+	index := int(runtime.AsInt(args[1]))
 	if index < 0 || index >= len(strObj.Chars) {
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return runtime.Value{Type: runtime.VAL_NULL}, nil
 	}
-	return runtime.ObjVal(runtime.NewObjString(string(strObj.Chars[index])))
+	return runtime.ObjVal(runtime.NewObjString(string(strObj.Chars[index]))), nil
 }
 
-func substringNative(argCount int, args []runtime.Value) runtime.Value {
+func substringNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 3 {
-		runtimeError("'substring' expects 3 arguments: a string, start index, and end index.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'substring' expects 3 arguments: a string, start index, and end index.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'substring' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'substring' requires a string as first argument.")
 	}
-	if args[1].Type != runtime.VAL_NUMBER || args[2].Type != runtime.VAL_NUMBER {
-		runtimeError("'substring' requires numbers as second and third arguments.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[1]) || !runtime.IsNumber(args[2]) {
+		return nativeError("'substring' requires numbers as second and third arguments.")
 	}
-	start := int(args[1].Number)
-	end := int(args[2].Number)
+	start := int(runtime.AsInt(args[1]))
+	end := int(runtime.AsInt(args[2]))
 	if start < 0 {
 		start = 0
 	}
@@ -436,361 +492,358 @@ func substringNative(argCount int, args []runtime.Value) runtime.Value {
 		end = len(strObj.Chars)
 	}
 	if start >= end || start >= len(strObj.Chars) {
-		return runtime.ObjVal(runtime.NewObjString(""))
+		return runtime.ObjVal(runtime.NewObjString("")), nil
 	}
-	return runtime.ObjVal(runtime.NewObjString(strObj.Chars[start:end]))
+	return runtime.ObjVal(runtime.NewObjString(strObj.Chars[start:end])), nil
 }
 
-func strIndexOfNative(argCount int, args []runtime.Value) runtime.Value {
+func strIndexOfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'str_index_of' expects 2 arguments: a string and a substring.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_index_of' expects 2 arguments: a string and a substring.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'str_index_of' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_index_of' requires a string as first argument.")
 	}
 	subStrObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'str_index_of' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_index_of' requires a string as second argument.")
 	}
 	index := strings.Index(strObj.Chars, subStrObj.Chars)
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(index)}
+	return runtime.Value{Type: runtime.VAL_INT, Int: int64(index)}, nil
 }
 
-func strLastIndexOfNative(argCount int, args []runtime.Value) runtime.Value {
+func strLastIndexOfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'str_last_index_of' expects 2 arguments: a string and a substring.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_last_index_of' expects 2 arguments: a string and a substring.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'str_last_index_of' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_last_index_of' requires a string as first argument.")
 	}
 	subStrObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'str_last_index_of' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_last_index_of' requires a string as second argument.")
 	}
 	index := strings.LastIndex(strObj.Chars, subStrObj.Chars)
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(index)}
+	return runtime.Value{Type: runtime.VAL_INT, Int: int64(index)}, nil
 }
 
-func strContainsNative(argCount int, args []runtime.Value) runtime.Value {
+func strContainsNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'str_contains' expects 2 arguments: a string and a substring.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_contains' expects 2 arguments: a string and a substring.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'str_contains' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_contains' requires a string as first argument.")
 	}
 	subStrObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'str_contains' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_contains' requires a string as second argument.")
 	}
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: strings.Contains(strObj.Chars, subStrObj.Chars)}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: strings.Contains(strObj.Chars, subStrObj.Chars)}, nil
 }
 
-func startsWithNative(argCount int, args []runtime.Value) runtime.Value {
+func startsWithNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'starts_with' expects 2 arguments: a string and a prefix.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'starts_with' expects 2 arguments: a string and a prefix.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'starts_with' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'starts_with' requires a string as first argument.")
 	}
 	prefixObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'starts_with' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'starts_with' requires a string as second argument.")
 	}
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: strings.HasPrefix(strObj.Chars, prefixObj.Chars)}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: strings.HasPrefix(strObj.Chars, prefixObj.Chars)}, nil
 }
 
-func endsWithNative(argCount int, args []runtime.Value) runtime.Value {
+func endsWithNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'ends_with' expects 2 arguments: a string and a suffix.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'ends_with' expects 2 arguments: a string and a suffix.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'ends_with' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'ends_with' requires a string as first argument.")
 	}
 	suffixObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'ends_with' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'ends_with' requires a string as second argument.")
 	}
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: strings.HasSuffix(strObj.Chars, suffixObj.Chars)}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: strings.HasSuffix(strObj.Chars, suffixObj.Chars)}, nil
 }
 
-func toUpperNative(argCount int, args []runtime.Value) runtime.Value {
+func toUpperNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'to_upper' expects 1 argument: a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'to_upper' expects 1 argument: a string.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'to_upper' requires a string argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'to_upper' requires a string argument.")
 	}
-	return runtime.ObjVal(runtime.NewObjString(strings.ToUpper(strObj.Chars)))
+	return runtime.ObjVal(runtime.NewObjString(strings.ToUpper(strObj.Chars))), nil
 }
 
-func toLowerNative(argCount int, args []runtime.Value) runtime.Value {
+func toLowerNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'to_lower' expects 1 argument: a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'to_lower' expects 1 argument: a string.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'to_lower' requires a string argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'to_lower' requires a string argument.")
 	}
-	return runtime.ObjVal(runtime.NewObjString(strings.ToLower(strObj.Chars)))
+	return runtime.ObjVal(runtime.NewObjString(strings.ToLower(strObj.Chars))), nil
 }
 
-func trimNative(argCount int, args []runtime.Value) runtime.Value {
+func trimNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'trim' expects 1 argument: a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'trim' expects 1 argument: a string.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'trim' requires a string argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'trim' requires a string argument.")
 	}
-	return runtime.ObjVal(runtime.NewObjString(strings.TrimSpace(strObj.Chars)))
+	return runtime.ObjVal(runtime.NewObjString(strings.TrimSpace(strObj.Chars))), nil
 }
 
-func splitNative(argCount int, args []runtime.Value) runtime.Value {
+func splitNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'split' expects 2 arguments: a string and a delimiter.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'split' expects 2 arguments: a string and a delimiter.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'split' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'split' requires a string as first argument.")
 	}
 	delimiterObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'split' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'split' requires a string as second argument.")
 	}
 	split := strings.Split(strObj.Chars, delimiterObj.Chars)
 	result := make([]runtime.Value, len(split))
 	for i, s := range split {
 		result[i] = runtime.ObjVal(runtime.NewObjString(s))
 	}
-	return runtime.ObjVal(runtime.NewArray(result))
+	return runtime.ObjVal(runtime.NewArray(result)), nil
 }
 
-func replaceNative(argCount int, args []runtime.Value) runtime.Value {
+func replaceNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 3 {
-		runtimeError("'replace' expects 3 arguments: a string, old substring, and new substring.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'replace' expects 3 arguments: a string, old substring, and new substring.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'replace' requires a string as first argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'replace' requires a string as first argument.")
 	}
 	oldObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("'replace' requires a string as second argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'replace' requires a string as second argument.")
 	}
 	newObj, ok := args[2].Obj.(*runtime.ObjString)
 	if !ok || args[2].Type != runtime.VAL_OBJ {
-		runtimeError("'replace' requires a string as third argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'replace' requires a string as third argument.")
 	}
-	return runtime.ObjVal(runtime.NewObjString(strings.ReplaceAll(strObj.Chars, oldObj.Chars, newObj.Chars)))
+	return runtime.ObjVal(runtime.NewObjString(strings.ReplaceAll(strObj.Chars, oldObj.Chars, newObj.Chars))), nil
 }
 
-func strLengthNative(argCount int, args []runtime.Value) runtime.Value {
+func strLengthNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'str_length' expects 1 argument: a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_length' expects 1 argument: a string.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'str_length' requires a string argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'str_length' requires a string argument.")
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(len(strObj.Chars))}
+	return runtime.Value{Type: runtime.VAL_INT, Int: int64(len(strObj.Chars))}, nil
 }
 
-func arrayLenNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayLenNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'len' expects 1 argument (the array).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'len' expects 1 argument (the array).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'len' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'len' can only be used on arrays and ranges.")
+	}
+	if rng, ok := args[0].Obj.(*runtime.ObjRange); ok {
+		length, fits := rng.Len()
+		if !fits {
+			return nativeError("'len' cannot count range %s; it has more values than an int holds.", rng.String())
+		}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(length)}, nil
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'len' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'len' can only be used on arrays and ranges.")
 	}
 	return runtime.Value{
-		Type:   runtime.VAL_NUMBER,
-		Number: float64(len(array.Elements)),
-	}
+		Type: runtime.VAL_INT,
+		Int:  int64(len(array.Elements)),
+	}, nil
 }
 
-func arrayPushNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayPushNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount < 1 {
-		runtimeError("'push' expects at least 1 argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'push' expects at least 1 argument.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'push' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'push' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'push' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'push' can only be used on arrays.")
 	}
 	for i := 1; i < argCount; i++ {
 		array.Elements = append(array.Elements, args[i])
 	}
 	return runtime.Value{
-		Type:   runtime.VAL_NUMBER,
-		Number: float64(len(array.Elements)),
-	}
+		Type: runtime.VAL_INT,
+		Int:  int64(len(array.Elements)),
+	}, nil
 }
 
-func arrayPopNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayPopNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'pop' expects 1 argument.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'pop' expects 1 argument.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'pop' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'pop' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'pop' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'pop' can only be used on arrays.")
 	}
 	if len(array.Elements) == 0 {
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return runtime.Value{Type: runtime.VAL_NULL}, nil
 	}
 	last := array.Elements[len(array.Elements)-1]
 	array.Elements = array.Elements[:len(array.Elements)-1]
-	return last
+	return last, nil
 }
 
-func arrayIterNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayIterNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'array_iter' expects 1 argument (the array).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_iter' expects 1 argument (the array).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_iter' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_iter' can only be used on arrays and enums.")
+	}
+	// Iterating over an enum visits its variants in declaration order.
+	if enum, ok := args[0].Obj.(*runtime.ObjEnum); ok {
+		variants := make([]runtime.Value, len(enum.Variants))
+		for i, variant := range enum.Variants {
+			variants[i] = runtime.ObjVal(variant)
+		}
+		return runtime.Value{
+			Type: runtime.VAL_OBJ,
+			Obj:  runtime.NewArrayIterator(runtime.NewArray(variants)),
+		}, nil
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_iter' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_iter' can only be used on arrays and enums.")
 	}
 	return runtime.Value{
 		Type: runtime.VAL_OBJ,
 		Obj:  runtime.NewArrayIterator(array),
-	}
+	}, nil
 }
 
-func iterNextNative(argCount int, args []runtime.Value) runtime.Value {
+func iterNextNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'iter_next' expects 1 argument (the iterator).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'iter_next' expects 1 argument (the iterator).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'iter_next' can only be used on iterators.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'iter_next' can only be used on iterators.")
 	}
 	iter, ok := args[0].Obj.(*runtime.ObjArrayIterator)
 	if !ok {
-		runtimeError("'iter_next' can only be used on iterators.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'iter_next' can only be used on iterators.")
 	}
 	iter.Index++
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
-func iterValueNative(argCount int, args []runtime.Value) runtime.Value {
+func iterValueNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'iter_value' expects 1 argument (the iterator).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'iter_value' expects 1 argument (the iterator).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'iter_value' can only be used on iterators.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'iter_value' can only be used on iterators.")
 	}
 	iter, ok := args[0].Obj.(*runtime.ObjArrayIterator)
 	if !ok {
-		runtimeError("'iter_value' can only be used on iterators.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'iter_value' can only be used on iterators.")
 	}
 	if iter.Index >= len(iter.Array.Elements) {
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return runtime.Value{Type: runtime.VAL_NULL}, nil
 	}
-	return iter.Array.Elements[iter.Index]
+	return iter.Array.Elements[iter.Index], nil
 }
 
-func iterDoneNative(argCount int, args []runtime.Value) runtime.Value {
+func iterDoneNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'iter_done' expects 1 argument (the iterator).")
-		return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
+		return nativeError("'iter_done' expects 1 argument (the iterator).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'iter_done' can only be used on iterators.")
-		return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
+		return nativeError("'iter_done' can only be used on iterators.")
 	}
 	iter, ok := args[0].Obj.(*runtime.ObjArrayIterator)
 	if !ok {
-		runtimeError("'iter_done' can only be used on iterators.")
-		return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
+		return nativeError("'iter_done' can only be used on iterators.")
 	}
 	return runtime.Value{
 		Type: runtime.VAL_BOOL,
 		Bool: iter.Index >= len(iter.Array.Elements),
+	}, nil
+}
+
+// iteratorNative returns the iterator an 'iter' loop would walk for a value, which scripts drive
+// with its done() and next() methods.
+func iteratorNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("'iterator' expects 1 argument (the value to iterate over).")
+	}
+	return newIterator(args[0], false)
+}
+
+// rangeNative returns the lazy range of numbers from start up to, but not including, end,
+// counting by step: range(end), range(start, end) or range(start, end, step).
+func rangeNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount < 1 || argCount > 3 {
+		return nativeError("'range' expects 1 to 3 arguments (start, end and step).")
+	}
+	for _, arg := range args[:argCount] {
+		if !runtime.IsNumber(arg) {
+			return nativeError("'range' arguments must be numbers (got %s).", typeName(arg))
+		}
 	}
+	bounds := []runtime.Value{{Type: runtime.VAL_INT, Int: 0}, args[0], {Type: runtime.VAL_INT, Int: 1}}
+	if argCount > 1 {
+		bounds[0], bounds[1] = args[0], args[1]
+	}
+	if argCount > 2 {
+		bounds[2] = args[2]
+	}
+	if runtime.AsFloat(bounds[2]) == 0 {
+		return nativeError("'range' step cannot be zero.")
+	}
+	return runtime.ObjVal(newRange(bounds[0], bounds[1], bounds[2], false)), nil
 }
 
 // ============================================================================
 // Native Functions: Array Operations
 // ============================================================================
 
-func arraySortNative(argCount int, args []runtime.Value) runtime.Value {
+func arraySortNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'array_sort' expects 1 argument (the array).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_sort' expects 1 argument (the array).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_sort' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_sort' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_sort' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_sort' can only be used on arrays.")
 	}
 
 	valueToString := func(v runtime.Value) string {
@@ -804,6 +857,8 @@ func arraySortNative(argCount int, args []runtime.Value) runtime.Value {
 			return "null"
 		case runtime.VAL_NUMBER:
 			return fmt.Sprintf("%g", v.Number)
+		case runtime.VAL_INT:
+			return strconv.FormatInt(v.Int, 10)
 		case runtime.VAL_OBJ:
 			if strObj, ok := v.Obj.(*runtime.ObjString); ok {
 				return strObj.Chars
@@ -833,22 +888,19 @@ func arraySortNative(argCount int, args []runtime.Value) runtime.Value {
 		return strI < strJ
 	})
 
-	return runtime.ObjVal(array)
+	return runtime.ObjVal(array), nil
 }
 
-func arraySplitNative(argCount int, args []runtime.Value) runtime.Value {
+func arraySplitNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'array_split' expects 2 arguments: an array and a separator.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_split' expects 2 arguments: an array and a separator.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_split' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_split' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_split' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_split' can only be used on arrays.")
 	}
 	separator := args[1]
 	var resultElements []runtime.Value
@@ -864,43 +916,37 @@ func arraySplitNative(argCount int, args []runtime.Value) runtime.Value {
 	}
 	currentSubArray := runtime.ObjVal(runtime.NewArray(currentSplit))
 	resultElements = append(resultElements, currentSubArray)
-	return runtime.ObjVal(runtime.NewArray(resultElements))
+	return runtime.ObjVal(runtime.NewArray(resultElements)), nil
 }
 
-func arrayJoinNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayJoinNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount < 2 {
-		runtimeError("'array_join' expects at least 2 arguments (arrays).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_join' expects at least 2 arguments (arrays).")
 	}
 	var joinedElements []runtime.Value
 	for i := 0; i < argCount; i++ {
 		if args[i].Type != runtime.VAL_OBJ {
-			runtimeError("'array_join' can only join arrays.")
-			return runtime.Value{Type: runtime.VAL_NULL}
+			return nativeError("'array_join' can only join arrays.")
 		}
 		arr, ok := args[i].Obj.(*runtime.ObjArray)
 		if !ok {
-			runtimeError("'array_join' can only join arrays.")
-			return runtime.Value{Type: runtime.VAL_NULL}
+			return nativeError("'array_join' can only join arrays.")
 		}
 		joinedElements = append(joinedElements, arr.Elements...)
 	}
-	return runtime.ObjVal(runtime.NewArray(joinedElements))
+	return runtime.ObjVal(runtime.NewArray(joinedElements)), nil
 }
 
-func arraySortedPushNative(argCount int, args []runtime.Value) runtime.Value {
+func arraySortedPushNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'array_sorted_push' expects 2 arguments: an array and a value.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_sorted_push' expects 2 arguments: an array and a value.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_sorted_push' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_sorted_push' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_sorted_push' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_sorted_push' can only be used on arrays.")
 	}
 	newVal := args[1]
 	valueToString := func(v runtime.Value) string {
@@ -914,6 +960,8 @@ func arraySortedPushNative(argCount int, args []runtime.Value) runtime.Value {
 			return "null"
 		case runtime.VAL_NUMBER:
 			return fmt.Sprintf("%g", v.Number)
+		case runtime.VAL_INT:
+			return strconv.FormatInt(v.Int, 10)
 		case runtime.VAL_OBJ:
 			if strObj, ok := v.Obj.(*runtime.ObjString); ok {
 				return strObj.Chars
@@ -936,47 +984,41 @@ func arraySortedPushNative(argCount int, args []runtime.Value) runtime.Value {
 		array.Elements = append(array.Elements, newVal)
 	}
 	return runtime.Value{
-		Type:   runtime.VAL_NUMBER,
-		Number: float64(len(array.Elements)),
-	}
+		Type: runtime.VAL_INT,
+		Int:  int64(len(array.Elements)),
+	}, nil
 }
 
-func arrayLinearSearchNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayLinearSearchNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'array_linear_search' expects 2 arguments: an array and a search value.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_linear_search' expects 2 arguments: an array and a search value.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_linear_search' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_linear_search' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_linear_search' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_linear_search' can only be used on arrays.")
 	}
 	searchVal := args[1]
 	for i, elem := range array.Elements {
 		if runtime.Equal(elem, searchVal) {
-			return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(i)}
+			return runtime.Value{Type: runtime.VAL_INT, Int: int64(i)}, nil
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: -1}
+	return runtime.Value{Type: runtime.VAL_INT, Int: -1}, nil
 }
 
-func arrayBinarySearchNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayBinarySearchNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'array_binary_search' expects 2 arguments: a sorted array and a search value.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_binary_search' expects 2 arguments: a sorted array and a search value.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_binary_search' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_binary_search' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_binary_search' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_binary_search' can only be used on arrays.")
 	}
 	searchVal := args[1]
 	valueToString := func(v runtime.Value) string {
@@ -990,6 +1032,8 @@ func arrayBinarySearchNative(argCount int, args []runtime.Value) runtime.Value {
 			return "null"
 		case runtime.VAL_NUMBER:
 			return fmt.Sprintf("%g", v.Number)
+		case runtime.VAL_INT:
+			return strconv.FormatInt(v.Int, 10)
 		case runtime.VAL_OBJ:
 			if strObj, ok := v.Obj.(*runtime.ObjString); ok {
 				return strObj.Chars
@@ -1006,136 +1050,118 @@ func arrayBinarySearchNative(argCount int, args []runtime.Value) runtime.Value {
 		mid := (low + high) / 2
 		midStr := valueToString(array.Elements[mid])
 		if midStr == searchStr {
-			return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(mid)}
+			return runtime.Value{Type: runtime.VAL_INT, Int: int64(mid)}, nil
 		} else if midStr < searchStr {
 			low = mid + 1
 		} else {
 			high = mid - 1
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: -1}
+	return runtime.Value{Type: runtime.VAL_INT, Int: -1}, nil
 }
 
-func arrayIndexOfNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayIndexOfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'index_of' expects 2 arguments: an array and an element.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'index_of' expects 2 arguments: an array and an element.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'index_of' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'index_of' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'index_of' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'index_of' can only be used on arrays.")
 	}
 	element := args[1]
 	for i, elem := range array.Elements {
 		if runtime.Equal(elem, element) {
-			return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(i)}
+			return runtime.Value{Type: runtime.VAL_INT, Int: int64(i)}, nil
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: -1}
+	return runtime.Value{Type: runtime.VAL_INT, Int: -1}, nil
 }
 
-func arrayLastIndexOfNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayLastIndexOfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'last_index_of' expects 2 arguments: an array and an element.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'last_index_of' expects 2 arguments: an array and an element.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'last_index_of' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'last_index_of' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'last_index_of' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'last_index_of' can only be used on arrays.")
 	}
 	element := args[1]
 	for i := len(array.Elements) - 1; i >= 0; i-- {
 		if runtime.Equal(array.Elements[i], element) {
-			return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(i)}
+			return runtime.Value{Type: runtime.VAL_INT, Int: int64(i)}, nil
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: -1}
+	return runtime.Value{Type: runtime.VAL_INT, Int: -1}, nil
 }
 
-func arrayContainsNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayContainsNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'array_contains' expects 2 arguments: an array and an element.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_contains' expects 2 arguments: an array and an element.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_contains' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_contains' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_contains' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_contains' can only be used on arrays.")
 	}
 	element := args[1]
 	for _, elem := range array.Elements {
 		if runtime.Equal(elem, element) {
-			return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
+			return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}, nil
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: false}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: false}, nil
 }
 
-func arrayClearNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayClearNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'array_clear' expects 1 argument: an array.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_clear' expects 1 argument: an array.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_clear' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_clear' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_clear' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_clear' can only be used on arrays.")
 	}
 	array.Elements = []runtime.Value{}
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
-func arrayReverseNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayReverseNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'array_reverse' expects 1 argument: an array.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_reverse' expects 1 argument: an array.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_reverse' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_reverse' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_reverse' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_reverse' can only be used on arrays.")
 	}
 	for i, j := 0, len(array.Elements)-1; i < j; i, j = i+1, j-1 {
 		array.Elements[i], array.Elements[j] = array.Elements[j], array.Elements[i]
 	}
-	return runtime.ObjVal(array)
+	return runtime.ObjVal(array), nil
 }
 
-func arrayToStringNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayToStringNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'array_to_string' expects 1 argument: an array.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_to_string' expects 1 argument: an array.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_to_string' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_to_string' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_to_string' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_to_string' can only be used on arrays.")
 	}
 	var sb strings.Builder
 	sb.WriteString("[")
@@ -1147,376 +1173,323 @@ func arrayToStringNative(argCount int, args []runtime.Value) runtime.Value {
 		sb.WriteString(str)
 	}
 	sb.WriteString("]")
-	return runtime.ObjVal(runtime.NewObjString(sb.String()))
+	return runtime.ObjVal(runtime.NewObjString(sb.String())), nil
 }
 
-func arrayRemoveNative(argCount int, args []runtime.Value) runtime.Value {
+func arrayRemoveNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'array_remove' expects 2 arguments: an array and an element.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_remove' expects 2 arguments: an array and an element.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'array_remove' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_remove' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'array_remove' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'array_remove' can only be used on arrays.")
 	}
 	element := args[1]
 	for i, elem := range array.Elements {
 		if runtime.Equal(elem, element) {
 			array.Elements = append(array.Elements[:i], array.Elements[i+1:]...)
-			return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
+			return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}, nil
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: false}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: false}, nil
 }
 
 // ============================================================================
 // Native Functions: Map Operations
 // ============================================================================
 
-func mapRemoveNative(argCount int, args []runtime.Value) runtime.Value {
+func mapRemoveNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'map_remove' expects 2 arguments: a map and a key.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_remove' expects 2 arguments: a map and a key.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_remove' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_remove' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_remove' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_remove' can only be used on maps.")
 	}
 	if args[1].Type != runtime.VAL_OBJ {
-		runtimeError("Map key must be a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Map key must be a string.")
 	}
 	key, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("Map key must be a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Map key must be a string.")
 	}
 	delete(mapObj.Entries, key)
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
-func mapContainsKeyNative(argCount int, args []runtime.Value) runtime.Value {
+func mapContainsKeyNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'map_contains_key' expects 2 arguments: a map and a key.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_contains_key' expects 2 arguments: a map and a key.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_contains_key' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_contains_key' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_contains_key' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_contains_key' can only be used on maps.")
 	}
 	if args[1].Type != runtime.VAL_OBJ {
-		runtimeError("Map key must be a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Map key must be a string.")
 	}
 	key, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("Map key must be a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Map key must be a string.")
 	}
 	_, exists := mapObj.Entries[key]
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: exists}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: exists}, nil
 }
 
-func mapContainsValueNative(argCount int, args []runtime.Value) runtime.Value {
+func mapContainsValueNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'map_contains_value' expects 2 arguments: a map and a value.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_contains_value' expects 2 arguments: a map and a value.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_contains_value' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_contains_value' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_contains_value' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_contains_value' can only be used on maps.")
 	}
 	searchVal := args[1]
 	for _, val := range mapObj.Entries {
 		if runtime.Equal(val, searchVal) {
-			return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}
+			return runtime.Value{Type: runtime.VAL_BOOL, Bool: true}, nil
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_BOOL, Bool: false}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: false}, nil
 }
 
-func mapSizeNative(argCount int, args []runtime.Value) runtime.Value {
+func mapSizeNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'map_size' expects 1 argument: a map.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_size' expects 1 argument: a map.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_size' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_size' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_size' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_size' can only be used on maps.")
 	}
 	return runtime.Value{
-		Type:   runtime.VAL_NUMBER,
-		Number: float64(len(mapObj.Entries)),
-	}
+		Type: runtime.VAL_INT,
+		Int:  int64(len(mapObj.Entries)),
+	}, nil
 }
 
-func mapClearNative(argCount int, args []runtime.Value) runtime.Value {
+func mapClearNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'map_clear' expects 1 argument: a map.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_clear' expects 1 argument: a map.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_clear' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_clear' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_clear' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_clear' can only be used on maps.")
 	}
 	mapObj.Entries = make(map[*runtime.ObjString]runtime.Value)
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
-func mapKeysNative(argCount int, args []runtime.Value) runtime.Value {
+func mapKeysNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'map_keys' expects 1 argument: a map.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_keys' expects 1 argument: a map.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_keys' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_keys' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_keys' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_keys' can only be used on maps.")
 	}
 	keys := make([]runtime.Value, 0, len(mapObj.Entries))
 	for key := range mapObj.Entries {
 		keys = append(keys, runtime.ObjVal(key))
 	}
-	return runtime.ObjVal(runtime.NewArray(keys))
+	return runtime.ObjVal(runtime.NewArray(keys)), nil
 }
 
-func mapValuesNative(argCount int, args []runtime.Value) runtime.Value {
+func mapValuesNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'map_values' expects 1 argument: a map.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_values' expects 1 argument: a map.")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'map_values' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_values' can only be used on maps.")
 	}
 	mapObj, ok := args[0].Obj.(*runtime.ObjMap)
 	if !ok {
-		runtimeError("'map_values' can only be used on maps.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'map_values' can only be used on maps.")
 	}
 	values := make([]runtime.Value, 0, len(mapObj.Entries))
 	for _, value := range mapObj.Entries {
 		values = append(values, value)
 	}
-	return runtime.ObjVal(runtime.NewArray(values))
+	return runtime.ObjVal(runtime.NewArray(values)), nil
 }
 
 // ============================================================================
 // Native Functions: Date
 // ============================================================================
 
-func dateNew(argCount int, args []runtime.Value) runtime.Value {
+func dateNew(argCount int, args []runtime.Value) (runtime.Value, error) {
 	switch argCount {
 	case 0:
 		// Return current date
 		now := time.Now()
 		year, month, day := now.Date()
-		return runtime.ObjVal(runtime.NewDate(year, month, day))
+		return runtime.ObjVal(runtime.NewDate(year, month, day)), nil
 	case 1:
 		// Set year, default month to January (1), day to 1
-		if args[0].Type != runtime.VAL_NUMBER {
-			runtimeError("Argument must be a number")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[0]) {
+			return nativeError("Argument must be a number")
 		}
-		year := int(args[0].Number)
-		return runtime.ObjVal(runtime.NewDate(year, time.January, 1))
+		year := int(runtime.AsInt(args[0]))
+		return runtime.ObjVal(runtime.NewDate(year, time.January, 1)), nil
 	case 3:
 		// Set year, month, day
 		for i := 0; i < 3; i++ {
-			if args[i].Type != runtime.VAL_NUMBER {
-				runtimeError("Arguments must be numbers")
-				return runtime.Value{Type: runtime.VAL_NULL}
+			if !runtime.IsNumber(args[i]) {
+				return nativeError("Arguments must be numbers")
 			}
 		}
-		year := int(args[0].Number)
-		month := time.Month(args[1].Number) // Assumes month is 1-12
-		day := int(args[2].Number)
-		return runtime.ObjVal(runtime.NewDate(year, month, day))
+		year := int(runtime.AsInt(args[0]))
+		month := time.Month(runtime.AsInt(args[1])) // Assumes month is 1-12
+		day := int(runtime.AsInt(args[2]))
+		return runtime.ObjVal(runtime.NewDate(year, month, day)), nil
 	default:
-		runtimeError("Date requires 0, 1, or 3 arguments")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Date requires 0, 1, or 3 arguments")
 	}
 }
 
-func dateNow(argCount int, args []runtime.Value) runtime.Value {
+func dateNow(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		runtimeError("date_now() expects 0 arguments")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_now() expects 0 arguments")
 	}
 	now := time.Now()
 	year, month, day := now.Date()
-	return runtime.ObjVal(runtime.NewDate(year, month, day))
+	return runtime.ObjVal(runtime.NewDate(year, month, day)), nil
 }
 
-func dateParseDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateParseDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("date_parse_datetime() expects 1 argument (string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_parse_datetime() expects 1 argument (string)")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_parse_datetime() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_parse_datetime() expects a string argument")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("date_parse_datetime() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_parse_datetime() expects a string argument")
 	}
 	t, err := time.Parse("2006-01-02", strObj.Chars) // Standard date format
 	if err != nil {
-		runtimeError("Invalid date format: %s (use 'YYYY-MM-DD')", strObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid date format: %s (use 'YYYY-MM-DD')", strObj.Chars)
 	}
-	return runtime.ObjVal(runtime.NewDate(t.Year(), t.Month(), t.Day()))
+	return runtime.ObjVal(runtime.NewDate(t.Year(), t.Month(), t.Day())), nil
 }
 
-func dateFormatDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateFormatDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("date_format_datetime() expects 2 arguments (Date, format string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_format_datetime() expects 2 arguments (Date, format string)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_format_datetime() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_format_datetime() first argument must be a Date")
 	}
 	formatObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("date_format_datetime() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_format_datetime() second argument must be a string")
 	}
 	formatted := dateObj.Time.Format(formatObj.Chars)
-	return runtime.ObjVal(runtime.NewObjString(formatted))
+	return runtime.ObjVal(runtime.NewObjString(formatted)), nil
 }
 
-func dateAddDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateAddDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 4 {
-		runtimeError("date_add_datetime() expects 4 arguments (Date, years, months, days)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_add_datetime() expects 4 arguments (Date, years, months, days)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_add_datetime() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_add_datetime() first argument must be a Date")
 	}
 	for i := 1; i < 4; i++ {
-		if args[i].Type != runtime.VAL_NUMBER {
-			runtimeError("date_add_datetime() arguments 2-4 must be numbers")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[i]) {
+			return nativeError("date_add_datetime() arguments 2-4 must be numbers")
 		}
 	}
-	years := int(args[1].Number)
-	months := int(args[2].Number)
-	days := int(args[3].Number)
+	years := int(runtime.AsInt(args[1]))
+	months := int(runtime.AsInt(args[2]))
+	days := int(runtime.AsInt(args[3]))
 	newTime := dateObj.Time.AddDate(years, months, days)
-	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day()))
+	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())), nil
 }
 
-func dateSubtractDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateSubtractDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 4 {
-		runtimeError("date_subtract_datetime() expects 4 arguments (Date, years, months, days)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_subtract_datetime() expects 4 arguments (Date, years, months, days)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_subtract_datetime() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_subtract_datetime() first argument must be a Date")
 	}
 	for i := 1; i < 4; i++ {
-		if args[i].Type != runtime.VAL_NUMBER {
-			runtimeError("date_subtract_datetime() arguments 2-4 must be numbers")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[i]) {
+			return nativeError("date_subtract_datetime() arguments 2-4 must be numbers")
 		}
 	}
-	years := int(args[1].Number)
-	months := int(args[2].Number)
-	days := int(args[3].Number)
+	years := int(runtime.AsInt(args[1]))
+	months := int(runtime.AsInt(args[2]))
+	days := int(runtime.AsInt(args[3]))
 	newTime := dateObj.Time.AddDate(-years, -months, -days)
-	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day()))
+	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())), nil
 }
 
-func dateGetDateTimeComponent(argCount int, args []runtime.Value) runtime.Value {
+func dateGetDateTimeComponent(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("date_get_component() expects 2 arguments (Date, component string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_get_component() expects 2 arguments (Date, component string)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_get_component() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_get_component() first argument must be a Date")
 	}
 	compObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("date_get_component() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_get_component() second argument must be a string")
 	}
 	switch compObj.Chars {
 	case "year":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dateObj.Time.Year())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dateObj.Time.Year())}, nil
 	case "month":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dateObj.Time.Month())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dateObj.Time.Month())}, nil
 	case "day":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dateObj.Time.Day())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dateObj.Time.Day())}, nil
 	default:
-		runtimeError("Invalid component '%s' for Date (use 'year', 'month', 'day')", compObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid component '%s' for Date (use 'year', 'month', 'day')", compObj.Chars)
 	}
 }
 
-func dateSetDateTimeComponent(argCount int, args []runtime.Value) runtime.Value {
+func dateSetDateTimeComponent(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 3 {
-		runtimeError("date_set_component() expects 3 arguments (Date, component string, value)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_set_component() expects 3 arguments (Date, component string, value)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_set_component() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_set_component() first argument must be a Date")
 	}
 	compObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("date_set_component() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_set_component() second argument must be a string")
 	}
-	if args[2].Type != runtime.VAL_NUMBER {
-		runtimeError("date_set_component() third argument must be a number")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[2]) {
+		return nativeError("date_set_component() third argument must be a number")
 	}
-	value := int(args[2].Number)
+	value := int(runtime.AsInt(args[2]))
 	switch compObj.Chars {
 	case "year":
 		dateObj.Time = time.Date(value, dateObj.Time.Month(), dateObj.Time.Day(), 0, 0, 0, 0, dateObj.Time.Location())
@@ -1525,420 +1498,365 @@ func dateSetDateTimeComponent(argCount int, args []runtime.Value) runtime.Value
 	case "day":
 		dateObj.Time = time.Date(dateObj.Time.Year(), dateObj.Time.Month(), value, 0, 0, 0, 0, dateObj.Time.Location())
 	default:
-		runtimeError("Invalid component '%s' for Date (use 'year', 'month', 'day')", compObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid component '%s' for Date (use 'year', 'month', 'day')", compObj.Chars)
 	}
-	return runtime.ObjVal(dateObj)
+	return runtime.ObjVal(dateObj), nil
 }
 
-func dateAddDays(argCount int, args []runtime.Value) runtime.Value {
+func dateAddDays(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("date_add_days() expects 2 arguments (Date, days)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_add_days() expects 2 arguments (Date, days)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_add_days() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_add_days() first argument must be a Date")
 	}
-	if args[1].Type != runtime.VAL_NUMBER {
-		runtimeError("date_add_days() second argument must be a number")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[1]) {
+		return nativeError("date_add_days() second argument must be a number")
 	}
-	days := int(args[1].Number)
+	days := int(runtime.AsInt(args[1]))
 	newTime := dateObj.Time.AddDate(0, 0, days)
-	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day()))
+	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())), nil
 }
 
-func dateSubtractDays(argCount int, args []runtime.Value) runtime.Value {
+func dateSubtractDays(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("date_subtract_days() expects 2 arguments (Date, days)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_subtract_days() expects 2 arguments (Date, days)")
 	}
 	dateObj, ok := args[0].Obj.(*runtime.ObjDate)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("date_subtract_days() first argument must be a Date")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("date_subtract_days() first argument must be a Date")
 	}
-	if args[1].Type != runtime.VAL_NUMBER {
-		runtimeError("date_subtract_days() second argument must be a number")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[1]) {
+		return nativeError("date_subtract_days() second argument must be a number")
 	}
-	days := int(args[1].Number)
+	days := int(runtime.AsInt(args[1]))
 	newTime := dateObj.Time.AddDate(0, 0, -days)
-	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day()))
+	return runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())), nil
 }
 
 // ============================================================================
 // Native Functions: Time
 // ============================================================================
 
-func timeNew(argCount int, args []runtime.Value) runtime.Value {
+func timeNew(argCount int, args []runtime.Value) (runtime.Value, error) {
 	switch argCount {
 	case 0:
 		// Return current time
 		now := time.Now()
 		hour, minute, second := now.Hour(), now.Minute(), now.Second()
-		return runtime.ObjVal(runtime.NewTime(hour, minute, second))
+		return runtime.ObjVal(runtime.NewTime(hour, minute, second)), nil
 	case 1:
 		// Set hour, default minute and second to 0
-		if args[0].Type != runtime.VAL_NUMBER {
-			runtimeError("Argument must be a number")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[0]) {
+			return nativeError("Argument must be a number")
 		}
-		hour := int(args[0].Number)
-		return runtime.ObjVal(runtime.NewTime(hour, 0, 0))
+		hour := int(runtime.AsInt(args[0]))
+		return runtime.ObjVal(runtime.NewTime(hour, 0, 0)), nil
 	case 3:
 		// Set hour, minute, second
 		for i := 0; i < 3; i++ {
-			if args[i].Type != runtime.VAL_NUMBER {
-				runtimeError("Arguments must be numbers")
-				return runtime.Value{Type: runtime.VAL_NULL}
+			if !runtime.IsNumber(args[i]) {
+				return nativeError("Arguments must be numbers")
 			}
 		}
-		hour := int(args[0].Number)
-		minute := int(args[1].Number)
-		second := int(args[2].Number)
-		return runtime.ObjVal(runtime.NewTime(hour, minute, second))
+		hour := int(runtime.AsInt(args[0]))
+		minute := int(runtime.AsInt(args[1]))
+		second := int(runtime.AsInt(args[2]))
+		return runtime.ObjVal(runtime.NewTime(hour, minute, second)), nil
 	default:
-		runtimeError("Time requires 0, 1, or 3 arguments")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Time requires 0, 1, or 3 arguments")
 	}
 }
 
-func timeNow(argCount int, args []runtime.Value) runtime.Value {
+func timeNow(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		runtimeError("time_now() expects 0 arguments")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_now() expects 0 arguments")
 	}
 	now := time.Now()
-	return runtime.ObjVal(runtime.NewTime(now.Hour(), now.Minute(), now.Second()))
+	return runtime.ObjVal(runtime.NewTime(now.Hour(), now.Minute(), now.Second())), nil
 }
 
-func timeParseTime(argCount int, args []runtime.Value) runtime.Value {
+func timeParseTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("time_parse() expects 1 argument (string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_parse() expects 1 argument (string)")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("time_parse() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_parse() expects a string argument")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("time_parse() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_parse() expects a string argument")
 	}
 	t, err := time.Parse("15:04:05", strObj.Chars) // Standard time format
 	if err != nil {
-		runtimeError("Invalid time format: %s (use 'HH:MM:SS')", strObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid time format: %s (use 'HH:MM:SS')", strObj.Chars)
 	}
-	return runtime.ObjVal(runtime.NewTime(t.Hour(), t.Minute(), t.Second()))
+	return runtime.ObjVal(runtime.NewTime(t.Hour(), t.Minute(), t.Second())), nil
 }
 
-func timeFormatTime(argCount int, args []runtime.Value) runtime.Value {
+func timeFormatTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("time_format() expects 2 arguments (Time, format string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_format() expects 2 arguments (Time, format string)")
 	}
 	timeObj, ok := args[0].Obj.(*runtime.ObjTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("time_format() first argument must be a Time")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_format() first argument must be a Time")
 	}
 	formatObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("time_format() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_format() second argument must be a string")
 	}
 	formatted := timeObj.Time.Format(formatObj.Chars)
-	return runtime.ObjVal(runtime.NewObjString(formatted))
+	return runtime.ObjVal(runtime.NewObjString(formatted)), nil
 }
 
-func timeAddTime(argCount int, args []runtime.Value) runtime.Value {
+func timeAddTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 4 {
-		runtimeError("time_add() expects 4 arguments (Time, hours, minutes, seconds)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_add() expects 4 arguments (Time, hours, minutes, seconds)")
 	}
 	timeObj, ok := args[0].Obj.(*runtime.ObjTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("time_add() first argument must be a Time")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_add() first argument must be a Time")
 	}
 	for i := 1; i < 4; i++ {
-		if args[i].Type != runtime.VAL_NUMBER {
-			runtimeError("time_add() arguments 2-4 must be numbers")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[i]) {
+			return nativeError("time_add() arguments 2-4 must be numbers")
 		}
 	}
-	hours := int(args[1].Number)
-	minutes := int(args[2].Number)
-	seconds := int(args[3].Number)
+	hours := int(runtime.AsInt(args[1]))
+	minutes := int(runtime.AsInt(args[2]))
+	seconds := int(runtime.AsInt(args[3]))
 	duration := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
 	newTime := timeObj.Time.Add(duration)
-	return runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
-func timeSubtractTime(argCount int, args []runtime.Value) runtime.Value {
+func timeSubtractTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 4 {
-		runtimeError("time_subtract() expects 4 arguments (Time, hours, minutes, seconds)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_subtract() expects 4 arguments (Time, hours, minutes, seconds)")
 	}
 	timeObj, ok := args[0].Obj.(*runtime.ObjTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("time_subtract() first argument must be a Time")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_subtract() first argument must be a Time")
 	}
 	for i := 1; i < 4; i++ {
-		if args[i].Type != runtime.VAL_NUMBER {
-			runtimeError("time_subtract() arguments 2-4 must be numbers")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[i]) {
+			return nativeError("time_subtract() arguments 2-4 must be numbers")
 		}
 	}
-	hours := int(args[1].Number)
-	minutes := int(args[2].Number)
-	seconds := int(args[3].Number)
+	hours := int(runtime.AsInt(args[1]))
+	minutes := int(runtime.AsInt(args[2]))
+	seconds := int(runtime.AsInt(args[3]))
 	duration := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
 	newTime := timeObj.Time.Add(-duration)
-	return runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
-func timeGetTimeZone(argCount int, args []runtime.Value) runtime.Value {
+func timeGetTimeZone(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("time_get_timezone() expects 1 argument (Time)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_get_timezone() expects 1 argument (Time)")
 	}
 	timeObj, ok := args[0].Obj.(*runtime.ObjTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("time_get_timezone() argument must be a Time")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_get_timezone() argument must be a Time")
 	}
 	zone, _ := timeObj.Time.Zone()
-	return runtime.ObjVal(runtime.NewObjString(zone))
+	return runtime.ObjVal(runtime.NewObjString(zone)), nil
 }
 
-func timeConvertTimeZone(argCount int, args []runtime.Value) runtime.Value {
+func timeConvertTimeZone(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("time_convert_timezone() expects 2 arguments (Time, timezone string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_convert_timezone() expects 2 arguments (Time, timezone string)")
 	}
 	timeObj, ok := args[0].Obj.(*runtime.ObjTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("time_convert_timezone() first argument must be a Time")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_convert_timezone() first argument must be a Time")
 	}
 	tzObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("time_convert_timezone() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("time_convert_timezone() second argument must be a string")
 	}
 	loc, err := time.LoadLocation(tzObj.Chars)
 	if err != nil {
-		runtimeError("Invalid timezone: %s", tzObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid timezone: %s", tzObj.Chars)
 	}
 	newTime := timeObj.Time.In(loc)
-	return runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
 // ============================================================================
 // Native Functions: DateTime
 // ============================================================================
 
-func dateTimeNew(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeNew(argCount int, args []runtime.Value) (runtime.Value, error) {
 	switch argCount {
 	case 0:
 		// Return current datetime
 		now := time.Now()
 		year, month, day := now.Date()
 		hour, minute, second := now.Hour(), now.Minute(), now.Second()
-		return runtime.ObjVal(runtime.NewDateTime(year, month, day, hour, minute, second))
+		return runtime.ObjVal(runtime.NewDateTime(year, month, day, hour, minute, second)), nil
 	case 1:
 		// Set year, default rest to minimal values
-		if args[0].Type != runtime.VAL_NUMBER {
-			runtimeError("Argument must be a number")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[0]) {
+			return nativeError("Argument must be a number")
 		}
-		year := int(args[0].Number)
-		return runtime.ObjVal(runtime.NewDateTime(year, time.January, 1, 0, 0, 0))
+		year := int(runtime.AsInt(args[0]))
+		return runtime.ObjVal(runtime.NewDateTime(year, time.January, 1, 0, 0, 0)), nil
 	case 6:
 		// Set year, month, day, hour, minute, second
 		for i := 0; i < 6; i++ {
-			if args[i].Type != runtime.VAL_NUMBER {
-				runtimeError("Arguments must be numbers")
-				return runtime.Value{Type: runtime.VAL_NULL}
+			if !runtime.IsNumber(args[i]) {
+				return nativeError("Arguments must be numbers")
 			}
 		}
-		year := int(args[0].Number)
-		month := time.Month(args[1].Number) // Assumes month is 1-12
-		day := int(args[2].Number)
-		hour := int(args[3].Number)
-		minute := int(args[4].Number)
-		second := int(args[5].Number)
-		return runtime.ObjVal(runtime.NewDateTime(year, month, day, hour, minute, second))
+		year := int(runtime.AsInt(args[0]))
+		month := time.Month(runtime.AsInt(args[1])) // Assumes month is 1-12
+		day := int(runtime.AsInt(args[2]))
+		hour := int(runtime.AsInt(args[3]))
+		minute := int(runtime.AsInt(args[4]))
+		second := int(runtime.AsInt(args[5]))
+		return runtime.ObjVal(runtime.NewDateTime(year, month, day, hour, minute, second)), nil
 	default:
-		runtimeError("DateTime requires 0, 1, or 6 arguments")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("DateTime requires 0, 1, or 6 arguments")
 	}
 }
 
-func dateTimeNow(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeNow(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		runtimeError("datetime_now() expects 0 arguments")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_now() expects 0 arguments")
 	}
 	now := time.Now()
-	return runtime.ObjVal(runtime.NewDateTime(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second()))
+	return runtime.ObjVal(runtime.NewDateTime(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())), nil
 }
 
-func dateTimeParseDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeParseDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("datetime_parse() expects 1 argument (string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_parse() expects 1 argument (string)")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_parse() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_parse() expects a string argument")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("datetime_parse() expects a string argument")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_parse() expects a string argument")
 	}
 	t, err := time.Parse("2006-01-02 15:04:05", strObj.Chars) // Standard datetime format
 	if err != nil {
-		runtimeError("Invalid datetime format: %s (use 'YYYY-MM-DD HH:MM:SS')", strObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid datetime format: %s (use 'YYYY-MM-DD HH:MM:SS')", strObj.Chars)
 	}
-	return runtime.ObjVal(runtime.NewDateTime(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()))
+	return runtime.ObjVal(runtime.NewDateTime(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())), nil
 }
 
-func dateTimeFormatDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeFormatDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("datetime_format() expects 2 arguments (DateTime, format string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_format() expects 2 arguments (DateTime, format string)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_format() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_format() first argument must be a DateTime")
 	}
 	formatObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_format() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_format() second argument must be a string")
 	}
 	formatted := dtObj.Time.Format(formatObj.Chars)
-	return runtime.ObjVal(runtime.NewObjString(formatted))
+	return runtime.ObjVal(runtime.NewObjString(formatted)), nil
 }
 
-func dateTimeAddDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeAddDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 7 {
-		runtimeError("datetime_add() expects 7 arguments (DateTime, years, months, days, hours, minutes, seconds)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_add() expects 7 arguments (DateTime, years, months, days, hours, minutes, seconds)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_add() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_add() first argument must be a DateTime")
 	}
 	for i := 1; i < 7; i++ {
-		if args[i].Type != runtime.VAL_NUMBER {
-			runtimeError("datetime_add() arguments 2-7 must be numbers")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[i]) {
+			return nativeError("datetime_add() arguments 2-7 must be numbers")
 		}
 	}
-	years := int(args[1].Number)
-	months := int(args[2].Number)
-	days := int(args[3].Number)
-	hours := int(args[4].Number)
-	minutes := int(args[5].Number)
-	seconds := int(args[6].Number)
+	years := int(runtime.AsInt(args[1]))
+	months := int(runtime.AsInt(args[2]))
+	days := int(runtime.AsInt(args[3]))
+	hours := int(runtime.AsInt(args[4]))
+	minutes := int(runtime.AsInt(args[5]))
+	seconds := int(runtime.AsInt(args[6]))
 	newTime := dtObj.Time.AddDate(years, months, days).Add(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second)
-	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
-func dateTimeSubtractDateTime(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeSubtractDateTime(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 7 {
-		runtimeError("datetime_subtract() expects 7 arguments (DateTime, years, months, days, hours, minutes, seconds)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_subtract() expects 7 arguments (DateTime, years, months, days, hours, minutes, seconds)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_subtract() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_subtract() first argument must be a DateTime")
 	}
 	for i := 1; i < 7; i++ {
-		if args[i].Type != runtime.VAL_NUMBER {
-			runtimeError("datetime_subtract() arguments 2-7 must be numbers")
-			return runtime.Value{Type: runtime.VAL_NULL}
+		if !runtime.IsNumber(args[i]) {
+			return nativeError("datetime_subtract() arguments 2-7 must be numbers")
 		}
 	}
-	years := int(args[1].Number)
-	months := int(args[2].Number)
-	days := int(args[3].Number)
-	hours := int(args[4].Number)
-	minutes := int(args[5].Number)
-	seconds := int(args[6].Number)
+	years := int(runtime.AsInt(args[1]))
+	months := int(runtime.AsInt(args[2]))
+	days := int(runtime.AsInt(args[3]))
+	hours := int(runtime.AsInt(args[4]))
+	minutes := int(runtime.AsInt(args[5]))
+	seconds := int(runtime.AsInt(args[6]))
 	newTime := dtObj.Time.AddDate(-years, -months, -days).Add(-time.Duration(hours)*time.Hour - time.Duration(minutes)*time.Minute - time.Duration(seconds)*time.Second)
-	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
-func dateTimeGetDateTimeComponent(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeGetDateTimeComponent(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("datetime_get_component() expects 2 arguments (DateTime, component string)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_get_component() expects 2 arguments (DateTime, component string)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_get_component() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_get_component() first argument must be a DateTime")
 	}
 	compObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_get_component() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_get_component() second argument must be a string")
 	}
 	switch compObj.Chars {
 	case "year":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dtObj.Time.Year())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dtObj.Time.Year())}, nil
 	case "month":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dtObj.Time.Month())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dtObj.Time.Month())}, nil
 	case "day":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dtObj.Time.Day())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dtObj.Time.Day())}, nil
 	case "hour":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dtObj.Time.Hour())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dtObj.Time.Hour())}, nil
 	case "minute":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dtObj.Time.Minute())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dtObj.Time.Minute())}, nil
 	case "second":
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(dtObj.Time.Second())}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(dtObj.Time.Second())}, nil
 	default:
-		runtimeError("Invalid component '%s' for DateTime (use 'year', 'month', 'day', 'hour', 'minute', 'second')", compObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid component '%s' for DateTime (use 'year', 'month', 'day', 'hour', 'minute', 'second')", compObj.Chars)
 	}
 }
 
-func dateTimeSetDateTimeComponent(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeSetDateTimeComponent(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 3 {
-		runtimeError("datetime_set_component() expects 3 arguments (DateTime, component string, value)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_set_component() expects 3 arguments (DateTime, component string, value)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_set_component() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_set_component() first argument must be a DateTime")
 	}
 	compObj, ok := args[1].Obj.(*runtime.ObjString)
 	if !ok || args[1].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_set_component() second argument must be a string")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_set_component() second argument must be a string")
 	}
-	if args[2].Type != runtime.VAL_NUMBER {
-		runtimeError("datetime_set_component() third argument must be a number")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[2]) {
+		return nativeError("datetime_set_component() third argument must be a number")
 	}
-	value := int(args[2].Number)
+	value := int(runtime.AsInt(args[2]))
 	switch compObj.Chars {
 	case "year":
 		dtObj.Time = time.Date(value, dtObj.Time.Month(), dtObj.Time.Day(), dtObj.Time.Hour(), dtObj.Time.Minute(), dtObj.Time.Second(), dtObj.Time.Nanosecond(), dtObj.Time.Location())
@@ -1953,112 +1871,96 @@ func dateTimeSetDateTimeComponent(argCount int, args []runtime.Value) runtime.Va
 	case "second":
 		dtObj.Time = time.Date(dtObj.Time.Year(), dtObj.Time.Month(), dtObj.Time.Day(), dtObj.Time.Hour(), dtObj.Time.Minute(), value, dtObj.Time.Nanosecond(), dtObj.Time.Location())
 	default:
-		runtimeError("Invalid component '%s' for DateTime (use 'year', 'month', 'day', 'hour', 'minute', 'second')", compObj.Chars)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Invalid component '%s' for DateTime (use 'year', 'month', 'day', 'hour', 'minute', 'second')", compObj.Chars)
 	}
-	return runtime.ObjVal(dtObj)
+	return runtime.ObjVal(dtObj), nil
 }
 
-func dateTimeAddDays(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeAddDays(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("datetime_add_days() expects 2 arguments (DateTime, days)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_add_days() expects 2 arguments (DateTime, days)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_add_days() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_add_days() first argument must be a DateTime")
 	}
-	if args[1].Type != runtime.VAL_NUMBER {
-		runtimeError("datetime_add_days() second argument must be a number")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[1]) {
+		return nativeError("datetime_add_days() second argument must be a number")
 	}
-	days := int(args[1].Number)
+	days := int(runtime.AsInt(args[1]))
 	newTime := dtObj.Time.AddDate(0, 0, days)
-	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
-func dateTimeSubtractDays(argCount int, args []runtime.Value) runtime.Value {
+func dateTimeSubtractDays(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("datetime_subtract_days() expects 2 arguments (DateTime, days)")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_subtract_days() expects 2 arguments (DateTime, days)")
 	}
 	dtObj, ok := args[0].Obj.(*runtime.ObjDateTime)
 	if !ok || args[0].Type != runtime.VAL_OBJ {
-		runtimeError("datetime_subtract_days() first argument must be a DateTime")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("datetime_subtract_days() first argument must be a DateTime")
 	}
-	if args[1].Type != runtime.VAL_NUMBER {
-		runtimeError("datetime_subtract_days() second argument must be a number")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[1]) {
+		return nativeError("datetime_subtract_days() second argument must be a number")
 	}
-	days := int(args[1].Number)
+	days := int(runtime.AsInt(args[1]))
 	newTime := dtObj.Time.AddDate(0, 0, -days)
-	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second()))
+	return runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())), nil
 }
 
 // ============================================================================
 // Native Functions: Random Operations
 // ============================================================================
 
-func shuffleNative(argCount int, args []runtime.Value) runtime.Value {
+func shuffleNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'shuffle' expects 1 argument (the array).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'shuffle' expects 1 argument (the array).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'shuffle' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'shuffle' can only be used on arrays.")
 	}
 	array, ok := args[0].Obj.(*runtime.ObjArray)
 	if !ok {
-		runtimeError("'shuffle' can only be used on arrays.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'shuffle' can only be used on arrays.")
 	}
 	rand.Shuffle(len(array.Elements), func(i, j int) {
 		array.Elements[i], array.Elements[j] = array.Elements[j], array.Elements[i]
 	})
-	return args[0]
+	return args[0], nil
 }
 
-func randomBetweenNative(argCount int, args []runtime.Value) runtime.Value {
+func randomBetweenNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'random_between' expects 2 arguments (min, max).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'random_between' expects 2 arguments (min, max).")
 	}
-	if args[0].Type != runtime.VAL_NUMBER || args[1].Type != runtime.VAL_NUMBER {
-		runtimeError("'random_between' expects two numbers.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[0]) || !runtime.IsNumber(args[1]) {
+		return nativeError("'random_between' expects two numbers.")
 	}
-	min := args[0].Number
-	max := args[1].Number
+	min := runtime.AsFloat(args[0])
+	max := runtime.AsFloat(args[1])
 	if min > max {
-		runtimeError("min must be less than or equal to max.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("min must be less than or equal to max.")
 	}
 	if math.Floor(min) == min && math.Floor(max) == max {
 		minInt := int(min)
 		maxInt := int(max)
 		randomInt := rand.Intn(maxInt-minInt+1) + minInt
-		return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(randomInt)}
+		return runtime.Value{Type: runtime.VAL_INT, Int: int64(randomInt)}, nil
 	}
 	randomFloat := min + rand.Float64()*(max-min)
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: randomFloat}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: randomFloat}, nil
 }
 
-func randomStringNative(argCount int, args []runtime.Value) runtime.Value {
+func randomStringNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'random_string' expects 1 argument (size).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'random_string' expects 1 argument (size).")
 	}
-	if args[0].Type != runtime.VAL_NUMBER {
-		runtimeError("'random_string' expects a number (size).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+	if !runtime.IsNumber(args[0]) {
+		return nativeError("'random_string' expects a number (size).")
 	}
-	size := int(args[0].Number)
+	size := int(runtime.AsInt(args[0]))
 	if size < 0 {
-		runtimeError("Size must be non-negative.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Size must be non-negative.")
 	}
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()_+-=[]{}|;:,.<>?"
 	var sb strings.Builder
@@ -2066,19 +1968,19 @@ func randomStringNative(argCount int, args []runtime.Value) runtime.Value {
 		index := rand.Intn(len(charset))
 		sb.WriteByte(charset[index])
 	}
-	return runtime.ObjVal(runtime.NewObjString(sb.String()))
+	return runtime.ObjVal(runtime.NewObjString(sb.String())), nil
 }
 
 // ============================================================================
 // Native Functions: Output Operations
 // ============================================================================
 
-func printNative(argCount int, args []runtime.Value) runtime.Value {
+func printNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	for i := 0; i < argCount; i++ {
 		strVal := toStr(1, args[i:i+1])
 		if strVal.Type == runtime.VAL_OBJ {
 			if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-				fmt.Print(unescapeString(strObj.Chars))
+				fmt.Print(strObj.Chars)
 			} else {
 				fmt.Print("error")
 			}
@@ -2089,15 +1991,15 @@ func printNative(argCount int, args []runtime.Value) runtime.Value {
 			fmt.Print(" ")
 		}
 	}
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
-func printlnNative(argCount int, args []runtime.Value) runtime.Value {
+func printlnNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	for i := 0; i < argCount; i++ {
 		strVal := toStr(1, args[i:i+1])
 		if strVal.Type == runtime.VAL_OBJ {
 			if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-				fmt.Print(unescapeString(strObj.Chars))
+				fmt.Print(strObj.Chars)
 			} else {
 				fmt.Print("error")
 			}
@@ -2109,30 +2011,29 @@ func printlnNative(argCount int, args []runtime.Value) runtime.Value {
 		}
 	}
 	fmt.Println()
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
-func printfNative(argCount int, args []runtime.Value) runtime.Value {
+func printfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount < 1 {
-		runtimeError("'printf' expects at least 1 argument (format string).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'printf' expects at least 1 argument (format string).")
 	}
 	formatVal := args[0]
 	if formatVal.Type != runtime.VAL_OBJ {
-		runtimeError("'printf' first argument must be a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'printf' first argument must be a string (format).")
 	}
 	formatObj, ok := formatVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'printf' first argument must be a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'printf' first argument must be a string (format).")
 	}
-	format := unescapeString(formatObj.Chars) // Unescape the format string
+	format := formatObj.Chars
 	var printArgs []interface{}
 	for _, arg := range args[1:] {
 		switch arg.Type {
 		case runtime.VAL_BOOL:
 			printArgs = append(printArgs, arg.Bool)
+		case runtime.VAL_INT:
+			printArgs = append(printArgs, arg.Int)
 		case runtime.VAL_NUMBER:
 			if math.Mod(arg.Number, 1) == 0 {
 				printArgs = append(printArgs, int(arg.Number))
@@ -2140,20 +2041,24 @@ func printfNative(argCount int, args []runtime.Value) runtime.Value {
 				printArgs = append(printArgs, arg.Number)
 			}
 		case runtime.VAL_OBJ:
+			if formatted, ok := formatArg(arg.Obj); ok {
+				printArgs = append(printArgs, formatted)
+				continue
+			}
 			switch obj := arg.Obj.(type) {
 			case *runtime.ObjString:
-				printArgs = append(printArgs, unescapeString(obj.Chars)) // Unescape string arguments
+				printArgs = append(printArgs, obj.Chars)
 			case *runtime.ObjArray:
-				strVal := arrayToStringNative(1, []runtime.Value{arg})
+				strVal, _ := arrayToStringNative(1, []runtime.Value{arg})
 				if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-					printArgs = append(printArgs, unescapeString(strObj.Chars))
+					printArgs = append(printArgs, strObj.Chars)
 				} else {
 					printArgs = append(printArgs, "unknown array")
 				}
 			default:
 				strVal := toStr(1, []runtime.Value{arg})
 				if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-					printArgs = append(printArgs, unescapeString(strObj.Chars))
+					printArgs = append(printArgs, strObj.Chars)
 				} else {
 					printArgs = append(printArgs, "unknown")
 				}
@@ -2171,23 +2076,21 @@ func printfNative(argCount int, args []runtime.Value) runtime.Value {
 	adjustedFormat = strings.ReplaceAll(adjustedFormat, "%f", "%v")
 	adjustedFormat = strings.ReplaceAll(adjustedFormat, "%g", "%v")
 	fmt.Printf(adjustedFormat, printArgs...)
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
 // ============================================================================
 // Native Functions: Input Operations
 // ============================================================================
 
-func scanNative(argCount int, args []runtime.Value) runtime.Value {
+func scanNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		runtimeError("'scan' expects 0 arguments.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'scan' expects 0 arguments.")
 	}
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
 	if err != nil {
-		runtimeError("Error reading input: %v", err)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Error reading input: %v", err)
 	}
 	line = strings.TrimSuffix(line, "\n")
 	parts := strings.Fields(line)
@@ -2195,73 +2098,66 @@ func scanNative(argCount int, args []runtime.Value) runtime.Value {
 	for i, part := range parts {
 		values[i] = runtime.ObjVal(runtime.NewObjString(part))
 	}
-	return runtime.ObjVal(runtime.NewArray(values))
+	return runtime.ObjVal(runtime.NewArray(values)), nil
 }
 
-func scanlnNative(argCount int, args []runtime.Value) runtime.Value {
+func scanlnNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 0 {
-		runtimeError("'scanln' expects 0 arguments.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'scanln' expects 0 arguments.")
 	}
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
 	if err != nil {
-		runtimeError("Error reading input: %v", err)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Error reading input: %v", err)
 	}
 	line = strings.TrimSuffix(line, "\n")
-	return runtime.ObjVal(runtime.NewObjString(line))
+	return runtime.ObjVal(runtime.NewObjString(line)), nil
 }
 
-func scanfNative(argCount int, args []runtime.Value) runtime.Value {
+func scanfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'scanf' expects 1 argument (format string).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'scanf' expects 1 argument (format string).")
 	}
 	formatVal := args[0]
 	if formatVal.Type != runtime.VAL_OBJ {
-		runtimeError("'scanf' expects a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'scanf' expects a string (format).")
 	}
 	if _, ok := formatVal.Obj.(*runtime.ObjString); !ok {
-		runtimeError("'scanf' expects a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'scanf' expects a string (format).")
 	}
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
 	if err != nil {
-		runtimeError("Error reading input: %v", err)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Error reading input: %v", err)
 	}
 	line = strings.TrimSuffix(line, "\n")
-	return runtime.ObjVal(runtime.NewObjString(line))
+	return runtime.ObjVal(runtime.NewObjString(line)), nil
 }
 
 // ============================================================================
 // Native Functions: Format Operations
 // ============================================================================
 
-func sprintfNative(argCount int, args []runtime.Value) runtime.Value {
+func sprintfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount < 1 {
-		runtimeError("'sprintf' expects at least 1 argument (format string).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'sprintf' expects at least 1 argument (format string).")
 	}
 	formatVal := args[0]
 	if formatVal.Type != runtime.VAL_OBJ {
-		runtimeError("'sprintf' first argument must be a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'sprintf' first argument must be a string (format).")
 	}
 	formatObj, ok := formatVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'sprintf' first argument must be a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'sprintf' first argument must be a string (format).")
 	}
-	format := unescapeString(formatObj.Chars) // Unescape the format string
+	format := formatObj.Chars
 	var printArgs []interface{}
 	for _, arg := range args[1:] {
 		switch arg.Type {
 		case runtime.VAL_BOOL:
 			printArgs = append(printArgs, arg.Bool)
+		case runtime.VAL_INT:
+			printArgs = append(printArgs, arg.Int)
 		case runtime.VAL_NUMBER:
 			if math.Mod(arg.Number, 1) == 0 {
 				printArgs = append(printArgs, int(arg.Number))
@@ -2269,20 +2165,24 @@ func sprintfNative(argCount int, args []runtime.Value) runtime.Value {
 				printArgs = append(printArgs, arg.Number)
 			}
 		case runtime.VAL_OBJ:
+			if formatted, ok := formatArg(arg.Obj); ok {
+				printArgs = append(printArgs, formatted)
+				continue
+			}
 			switch obj := arg.Obj.(type) {
 			case *runtime.ObjString:
-				printArgs = append(printArgs, unescapeString(obj.Chars)) // Unescape string arguments
+				printArgs = append(printArgs, obj.Chars)
 			case *runtime.ObjArray:
-				strVal := arrayToStringNative(1, []runtime.Value{arg})
+				strVal, _ := arrayToStringNative(1, []runtime.Value{arg})
 				if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-					printArgs = append(printArgs, unescapeString(strObj.Chars))
+					printArgs = append(printArgs, strObj.Chars)
 				} else {
 					printArgs = append(printArgs, "unknown array")
 				}
 			default:
 				strVal := toStr(1, []runtime.Value{arg})
 				if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-					printArgs = append(printArgs, unescapeString(strObj.Chars))
+					printArgs = append(printArgs, strObj.Chars)
 				} else {
 					printArgs = append(printArgs, "unknown")
 				}
@@ -2300,30 +2200,29 @@ func sprintfNative(argCount int, args []runtime.Value) runtime.Value {
 	adjustedFormat = strings.ReplaceAll(adjustedFormat, "%f", "%v")
 	adjustedFormat = strings.ReplaceAll(adjustedFormat, "%g", "%v")
 	formatted := fmt.Sprintf(adjustedFormat, printArgs...)
-	return runtime.ObjVal(runtime.NewObjString(formatted))
+	return runtime.ObjVal(runtime.NewObjString(formatted)), nil
 }
 
-func errorfNative(argCount int, args []runtime.Value) runtime.Value {
+func errorfNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount < 1 {
-		runtimeError("'errorf' expects at least 1 argument (format string).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'errorf' expects at least 1 argument (format string).")
 	}
 	formatVal := args[0]
 	if formatVal.Type != runtime.VAL_OBJ {
-		runtimeError("'errorf' first argument must be a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'errorf' first argument must be a string (format).")
 	}
 	formatObj, ok := formatVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'errorf' first argument must be a string (format).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'errorf' first argument must be a string (format).")
 	}
-	format := unescapeString(formatObj.Chars) // Unescape the format string
+	format := formatObj.Chars
 	var printArgs []interface{}
 	for _, arg := range args[1:] {
 		switch arg.Type {
 		case runtime.VAL_BOOL:
 			printArgs = append(printArgs, arg.Bool)
+		case runtime.VAL_INT:
+			printArgs = append(printArgs, arg.Int)
 		case runtime.VAL_NUMBER:
 			if math.Mod(arg.Number, 1) == 0 {
 				printArgs = append(printArgs, int(arg.Number))
@@ -2331,20 +2230,24 @@ func errorfNative(argCount int, args []runtime.Value) runtime.Value {
 				printArgs = append(printArgs, arg.Number)
 			}
 		case runtime.VAL_OBJ:
+			if formatted, ok := formatArg(arg.Obj); ok {
+				printArgs = append(printArgs, formatted)
+				continue
+			}
 			switch obj := arg.Obj.(type) {
 			case *runtime.ObjString:
-				printArgs = append(printArgs, unescapeString(obj.Chars)) // Unescape string arguments
+				printArgs = append(printArgs, obj.Chars)
 			case *runtime.ObjArray:
-				strVal := arrayToStringNative(1, []runtime.Value{arg})
+				strVal, _ := arrayToStringNative(1, []runtime.Value{arg})
 				if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-					printArgs = append(printArgs, unescapeString(strObj.Chars))
+					printArgs = append(printArgs, strObj.Chars)
 				} else {
 					printArgs = append(printArgs, "unknown array")
 				}
 			default:
 				strVal := toStr(1, []runtime.Value{arg})
 				if strObj, ok := strVal.Obj.(*runtime.ObjString); ok {
-					printArgs = append(printArgs, unescapeString(strObj.Chars))
+					printArgs = append(printArgs, strObj.Chars)
 				} else {
 					printArgs = append(printArgs, "unknown")
 				}
@@ -2362,117 +2265,123 @@ func errorfNative(argCount int, args []runtime.Value) runtime.Value {
 	adjustedFormat = strings.ReplaceAll(adjustedFormat, "%f", "%v")
 	adjustedFormat = strings.ReplaceAll(adjustedFormat, "%g", "%v")
 	errMsg := fmt.Sprintf(adjustedFormat, printArgs...)
-	return runtime.ObjVal(runtime.NewObjString(errMsg))
+	return runtime.ObjVal(runtime.NewObjString(errMsg)), nil
 }
 
 // ============================================================================
 // Native Functions: File Operations
 // ============================================================================
 
-func readFileNative(argCount int, args []runtime.Value) runtime.Value {
+func readFileNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'read_file' expects 1 argument (file path).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'read_file' expects 1 argument (file path).")
 	}
 	pathVal := args[0]
 	if pathVal.Type != runtime.VAL_OBJ {
-		runtimeError("'read_file' expects a string (file path).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'read_file' expects a string (file path).")
 	}
 	pathObj, ok := pathVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'read_file' expects a string (file path).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'read_file' expects a string (file path).")
 	}
 	path := pathObj.Chars
 	content, err := os.ReadFile(path)
 	if err != nil {
-		runtimeError("Error reading file: %v", err)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Error reading file: %v", err)
 	}
-	return runtime.ObjVal(runtime.NewObjString(string(content)))
+	return runtime.ObjVal(runtime.NewObjString(string(content))), nil
 }
 
-func writeFileNative(argCount int, args []runtime.Value) runtime.Value {
+func writeFileNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 2 {
-		runtimeError("'write_file' expects 2 arguments (file path, content).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'write_file' expects 2 arguments (file path, content).")
 	}
 	pathVal := args[0]
 	contentVal := args[1]
 	if pathVal.Type != runtime.VAL_OBJ {
-		runtimeError("'write_file' first argument must be a string (file path).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'write_file' first argument must be a string (file path).")
 	}
 	pathObj, ok := pathVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'write_file' first argument must be a string (file path).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'write_file' first argument must be a string (file path).")
 	}
 	if contentVal.Type != runtime.VAL_OBJ {
-		runtimeError("'write_file' second argument must be a string (content).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'write_file' second argument must be a string (content).")
 	}
 	contentObj, ok := contentVal.Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'write_file' second argument must be a string (content).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'write_file' second argument must be a string (content).")
 	}
 	path := pathObj.Chars
 	content := contentObj.Chars
 	err := os.WriteFile(path, []byte(content), 0644)
 	if err != nil {
-		runtimeError("Error writing file: %v", err)
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("Error writing file: %v", err)
 	}
-	return runtime.Value{Type: runtime.VAL_NULL}
+	return runtime.Value{Type: runtime.VAL_NULL}, nil
 }
 
 // ============================================================================
 // Native Functions: Utility Operations
 // ============================================================================
 
-func parseIntNative(argCount int, args []runtime.Value) runtime.Value {
+func parseIntNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("'parse_int' expects 1 argument (string).")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'parse_int' expects 1 argument (string).")
 	}
 	if args[0].Type != runtime.VAL_OBJ {
-		runtimeError("'parse_int' expects a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'parse_int' expects a string.")
 	}
 	strObj, ok := args[0].Obj.(*runtime.ObjString)
 	if !ok {
-		runtimeError("'parse_int' expects a string.")
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return nativeError("'parse_int' expects a string.")
 	}
-	num, err := strconv.Atoi(strObj.Chars)
+	num, err := strconv.ParseInt(strObj.Chars, 10, 64)
 	if err != nil {
-		return runtime.Value{Type: runtime.VAL_NULL}
+		return runtime.Value{Type: runtime.VAL_NULL}, nil
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(num)}
+	return runtime.Value{Type: runtime.VAL_INT, Int: num}, nil
 }
 
 // ============================================================================
 // Native Functions: Types
 // ============================================================================
 
-func getRunTypeNative(argCount int, args []runtime.Value) runtime.Value {
+func getRunTypeNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 1 {
+		return nativeError("get_runtype takes exactly 1 argument")
+	}
+	return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(typeName(args[0]))}, nil
+}
+
+// isIntNative reports whether a value is an int rather than a float or any other value.
+func isIntNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	if argCount != 1 {
-		runtimeError("get_runtype takes exactly 1 argument")
-		return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString("Error: get_type expects 1 argument")}
+		return nativeError("'is_int' expects 1 argument.")
 	}
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: args[0].Type == runtime.VAL_INT}, nil
+}
 
-	return runtime.Value{Type: runtime.VAL_OBJ, Obj: runtime.NewObjString(typeName(args[0]))}
+// isInstanceNative reports whether a value is an instance of a struct or of one inheriting from it.
+func isInstanceNative(argCount int, args []runtime.Value) (runtime.Value, error) {
+	if argCount != 2 {
+		return nativeError("'is_instance' expects 2 arguments: a value and a struct.")
+	}
+	structObj, ok := args[1].Obj.(*runtime.ObjStruct)
+	if !ok {
+		return nativeError("'is_instance' expects a struct as its second argument, got %s.", typeName(args[1]))
+	}
+	instance, ok := args[0].Obj.(*runtime.ObjInstance)
+	return runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && instance.IsInstanceOf(structObj)}, nil
 }
 
 // ============================================================================
 // Native Functions: Others Operations
 // ============================================================================
 
-func clockNative(argCount int, args []runtime.Value) runtime.Value {
+func clockNative(argCount int, args []runtime.Value) (runtime.Value, error) {
 	return runtime.Value{
 		Type:   runtime.VAL_NUMBER,
 		Number: float64(time.Now().UnixNano()) / 1e9,
-	}
+	}, nil
 }
@@ -7,9 +7,25 @@ import (
 	"github.com/cryptrunner49/zscript/internal/runtime"
 )
 
-// Helper function for numeric addition
+// allInts reports whether every value is an integer.
+func allInts(values []runtime.Value) bool {
+	for _, value := range values {
+		if value.Type != runtime.VAL_INT {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function for numeric addition. Integers add exactly; a float operand, or a sum too large
+// for an integer, makes the result a float.
 func addNumbers(a, b runtime.Value) runtime.Value {
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: a.Number + b.Number}
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT {
+		if sum := a.Int + b.Int; (sum >= a.Int) == (b.Int >= 0) {
+			return runtime.Value{Type: runtime.VAL_INT, Int: sum}
+		}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: runtime.AsFloat(a) + runtime.AsFloat(b)}
 }
 
 // Helper function for string concatenation
@@ -35,7 +51,7 @@ func addArrays(arr1, arr2 *runtime.ObjArray) runtime.Value {
 	for i := 0; i < minLen; i++ {
 		v1 := arr1.Elements[i]
 		v2 := arr2.Elements[i]
-		if v1.Type == runtime.VAL_NUMBER && v2.Type == runtime.VAL_NUMBER {
+		if runtime.IsNumber(v1) && runtime.IsNumber(v2) {
 			result[i] = addNumbers(v1, v2)
 		} else {
 			result[i] = addStrings(v1, v2)
@@ -80,8 +96,8 @@ func addInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, InterpretRe
 		}
 		var fieldResult runtime.Value
 		switch val1.Type {
-		case runtime.VAL_NUMBER:
-			if val2.Type == runtime.VAL_NUMBER {
+		case runtime.VAL_NUMBER, runtime.VAL_INT:
+			if runtime.IsNumber(val2) {
 				fieldResult = addNumbers(val1, val2)
 			} else {
 				return runtime.Value{Type: runtime.VAL_NULL}, runtimeError("Incompatible field types for addition in struct: expected number, got %s.", typeName(val2))
@@ -130,9 +146,14 @@ func addInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, InterpretRe
 	return runtime.ObjVal(result), INTERPRET_OK
 }
 
-// Helper function for numeric subtraction
+// Helper function for numeric subtraction. A difference too large for an integer is a float.
 func subtractNumbers(a, b runtime.Value) runtime.Value {
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: a.Number - b.Number}
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT {
+		if difference := a.Int - b.Int; (difference <= a.Int) == (b.Int >= 0) {
+			return runtime.Value{Type: runtime.VAL_INT, Int: difference}
+		}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: runtime.AsFloat(a) - runtime.AsFloat(b)}
 }
 
 // Helper function for string cropping
@@ -162,7 +183,7 @@ func subtractArrays(arr1, arr2 *runtime.ObjArray) runtime.Value {
 	for i := 0; i < minLen; i++ {
 		v1 := arr1.Elements[i]
 		v2 := arr2.Elements[i]
-		if v1.Type == runtime.VAL_NUMBER && v2.Type == runtime.VAL_NUMBER {
+		if runtime.IsNumber(v1) && runtime.IsNumber(v2) {
 			result[i] = subtractNumbers(v1, v2)
 		} else {
 			result[i] = subtractStrings(v1, v2)
@@ -207,8 +228,8 @@ func subtractInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, Interp
 		}
 		var fieldResult runtime.Value
 		switch val1.Type {
-		case runtime.VAL_NUMBER:
-			if val2.Type == runtime.VAL_NUMBER {
+		case runtime.VAL_NUMBER, runtime.VAL_INT:
+			if runtime.IsNumber(val2) {
 				fieldResult = subtractNumbers(val1, val2)
 			} else {
 				return runtime.Value{Type: runtime.VAL_NULL}, runtimeError("Incompatible field types for subtraction in struct: expected number, got %s.", typeName(val2))
@@ -257,9 +278,26 @@ func subtractInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, Interp
 	return runtime.ObjVal(result), INTERPRET_OK
 }
 
-// Helper function for numeric multiplication
+// Helper function for numeric multiplication. A product too large for an integer is a float.
 func multiplyNumbers(a, b runtime.Value) runtime.Value {
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: a.Number * b.Number}
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT {
+		if product, ok := multiplyInts(a.Int, b.Int); ok {
+			return runtime.Value{Type: runtime.VAL_INT, Int: product}
+		}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: runtime.AsFloat(a) * runtime.AsFloat(b)}
+}
+
+// multiplyInts returns a * b and whether the product fits in an integer.
+func multiplyInts(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
 }
 
 // Helper function for array multiplication
@@ -278,7 +316,7 @@ func multiplyArrays(arr1, arr2 *runtime.ObjArray) (runtime.Value, InterpretResul
 	for i := 0; i < minLen; i++ {
 		v1 := arr1.Elements[i]
 		v2 := arr2.Elements[i]
-		if v1.Type != runtime.VAL_NUMBER || v2.Type != runtime.VAL_NUMBER {
+		if !runtime.IsNumber(v1) || !runtime.IsNumber(v2) {
 			return runtime.Value{Type: runtime.VAL_NULL}, runtimeError("Array elements for '*' must be numbers (found %s and %s at index %d).", typeName(v1), typeName(v2), i)
 		}
 		result[i] = multiplyNumbers(v1, v2)
@@ -308,8 +346,8 @@ func multiplyInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, Interp
 		}
 		var fieldResult runtime.Value
 		switch val1.Type {
-		case runtime.VAL_NUMBER:
-			if val2.Type == runtime.VAL_NUMBER {
+		case runtime.VAL_NUMBER, runtime.VAL_INT:
+			if runtime.IsNumber(val2) {
 				fieldResult = multiplyNumbers(val1, val2)
 			} else {
 				fieldResult = val1
@@ -346,13 +384,14 @@ func multiplyInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, Interp
 	return runtime.ObjVal(result), INTERPRET_OK
 }
 
-// Helper function for numeric division
+// Helper function for numeric division. The quotient is always a float, even of two integers;
+// '/_' divides integers to an integer.
 func divideNumbers(a, b runtime.Value) runtime.Value {
-	if b.Number == 0 {
+	if runtime.AsFloat(b) == 0 {
 		runtimeError("Division by zero in struct field operation.")
 		return a
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: a.Number / b.Number}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: runtime.AsFloat(a) / runtime.AsFloat(b)}
 }
 
 // Helper function for array division
@@ -371,7 +410,7 @@ func divideArrays(arr1, arr2 *runtime.ObjArray) (runtime.Value, InterpretResult)
 	for i := 0; i < minLen; i++ {
 		v1 := arr1.Elements[i]
 		v2 := arr2.Elements[i]
-		if v1.Type != runtime.VAL_NUMBER || v2.Type != runtime.VAL_NUMBER {
+		if !runtime.IsNumber(v1) || !runtime.IsNumber(v2) {
 			return runtime.Value{Type: runtime.VAL_NULL}, runtimeError("Array elements for '/' must be numbers (found %s and %s at index %d).", typeName(v1), typeName(v2), i)
 		}
 		result[i] = divideNumbers(v1, v2)
@@ -401,8 +440,8 @@ func divideInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, Interpre
 		}
 		var fieldResult runtime.Value
 		switch val1.Type {
-		case runtime.VAL_NUMBER:
-			if val2.Type == runtime.VAL_NUMBER {
+		case runtime.VAL_NUMBER, runtime.VAL_INT:
+			if runtime.IsNumber(val2) {
 				fieldResult = divideNumbers(val1, val2)
 			} else {
 				fieldResult = val1
@@ -439,13 +478,16 @@ func divideInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, Interpre
 	return runtime.ObjVal(result), INTERPRET_OK
 }
 
-// Helper function for numeric modulo
+// Helper function for numeric modulo. The remainder takes the sign of the dividend.
 func modNumbers(a, b runtime.Value) runtime.Value {
-	if b.Number == 0 {
+	if runtime.AsFloat(b) == 0 {
 		runtimeError("Modulo by zero in struct field operation.")
 		return a
 	}
-	return runtime.Value{Type: runtime.VAL_NUMBER, Number: math.Mod(a.Number, b.Number)}
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT {
+		return runtime.Value{Type: runtime.VAL_INT, Int: a.Int % b.Int}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: math.Mod(runtime.AsFloat(a), runtime.AsFloat(b))}
 }
 
 // Helper function for array modulo
@@ -464,7 +506,7 @@ func modArrays(arr1, arr2 *runtime.ObjArray) (runtime.Value, InterpretResult) {
 	for i := 0; i < minLen; i++ {
 		v1 := arr1.Elements[i]
 		v2 := arr2.Elements[i]
-		if v1.Type != runtime.VAL_NUMBER || v2.Type != runtime.VAL_NUMBER {
+		if !runtime.IsNumber(v1) || !runtime.IsNumber(v2) {
 			return runtime.Value{Type: runtime.VAL_NULL}, runtimeError("Array elements for '%%' must be numbers (found %s and %s at index %d).", typeName(v1), typeName(v2), i)
 		}
 		result[i] = modNumbers(v1, v2)
@@ -494,8 +536,8 @@ func modInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, InterpretRe
 		}
 		var fieldResult runtime.Value
 		switch val1.Type {
-		case runtime.VAL_NUMBER:
-			if val2.Type == runtime.VAL_NUMBER {
+		case runtime.VAL_NUMBER, runtime.VAL_INT:
+			if runtime.IsNumber(val2) {
 				fieldResult = modNumbers(val1, val2)
 			} else {
 				fieldResult = val1
@@ -531,3 +573,124 @@ func modInstances(inst1, inst2 *runtime.ObjInstance) (runtime.Value, InterpretRe
 	}
 	return runtime.ObjVal(result), INTERPRET_OK
 }
+
+// floorDivideNumbers divides for '/_', rounding the quotient down. Two integers give an integer.
+// The divisor must not be zero.
+func floorDivideNumbers(a, b runtime.Value) runtime.Value {
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT && (a.Int != math.MinInt64 || b.Int != -1) {
+		quotient := a.Int / b.Int
+		if a.Int%b.Int != 0 && (a.Int < 0) != (b.Int < 0) {
+			quotient--
+		}
+		return runtime.Value{Type: runtime.VAL_INT, Int: quotient}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: math.Floor(runtime.AsFloat(a) / runtime.AsFloat(b))}
+}
+
+// powerNumbers raises a to the power b for '**'. An integer raised to a non-negative integer
+// power is an integer when it fits in one; anything else is a float.
+func powerNumbers(a, b runtime.Value) runtime.Value {
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT && b.Int >= 0 {
+		if power, ok := powerInts(a.Int, b.Int); ok {
+			return runtime.Value{Type: runtime.VAL_INT, Int: power}
+		}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: math.Pow(runtime.AsFloat(a), runtime.AsFloat(b))}
+}
+
+// powerInts returns base raised to a non-negative exponent and whether the power fits in an
+// integer.
+func powerInts(base, exponent int64) (int64, bool) {
+	result := int64(1)
+	for ok := true; exponent > 0; exponent >>= 1 {
+		if exponent&1 == 1 {
+			if result, ok = multiplyInts(result, base); !ok {
+				return 0, false
+			}
+		}
+		if exponent > 1 {
+			if base, ok = multiplyInts(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// negateNumber applies unary '-' to a number. The smallest integer has no integer negation, so
+// negating it gives a float.
+func negateNumber(a runtime.Value) runtime.Value {
+	if a.Type == runtime.VAL_INT && a.Int != math.MinInt64 {
+		return runtime.Value{Type: runtime.VAL_INT, Int: -a.Int}
+	}
+	return runtime.Value{Type: runtime.VAL_NUMBER, Number: -runtime.AsFloat(a)}
+}
+
+// lessNumbers reports whether a is less than b. Two integers are compared exactly.
+func lessNumbers(a, b runtime.Value) bool {
+	if a.Type == runtime.VAL_INT && b.Type == runtime.VAL_INT {
+		return a.Int < b.Int
+	}
+	return runtime.AsFloat(a) < runtime.AsFloat(b)
+}
+
+// bitwiseOperators are the symbols of the bitwise operators, used in error messages.
+var bitwiseOperators = map[runtime.OpCode]string{
+	runtime.OP_BIT_AND:     "&",
+	runtime.OP_BIT_OR:      "|",
+	runtime.OP_BIT_XOR:     "^",
+	runtime.OP_SHIFT_LEFT:  "<<",
+	runtime.OP_SHIFT_RIGHT: ">>",
+}
+
+// integerOperandName names the operand an operator that only takes integers refused. Ints and
+// floats share the type name "number", so a float is called one to say why it was refused.
+func integerOperandName(v runtime.Value) string {
+	if v.Type == runtime.VAL_NUMBER {
+		return "float"
+	}
+	return typeName(v)
+}
+
+// nonInteger returns the operand of a binary operator that is neither an int nor a BigInt,
+// preferring the left one.
+func nonInteger(a, b runtime.Value) runtime.Value {
+	if _, isInteger := toBigInt(a); isInteger {
+		return b
+	}
+	return a
+}
+
+// bitwiseIntegers applies a binary bitwise operator to two integers. '<<' multiplies by a power of
+// two like '*', so a result too large for 64 bits becomes a float. '>>' shifts in copies of the
+// sign bit, and shifting by 64 or more places leaves only those.
+func bitwiseIntegers(op runtime.OpCode, a, b runtime.Value) (runtime.Value, InterpretResult) {
+	if bigOperands(a, b) {
+		return bigArithmetic(op, a, b)
+	}
+	if a.Type != runtime.VAL_INT || b.Type != runtime.VAL_INT {
+		return a, runtimeError("Operands for '%s' must be integers (got %s).", bitwiseOperators[op], integerOperandName(nonInteger(a, b)))
+	}
+	var result int64
+	switch op {
+	case runtime.OP_BIT_AND:
+		result = a.Int & b.Int
+	case runtime.OP_BIT_OR:
+		result = a.Int | b.Int
+	case runtime.OP_BIT_XOR:
+		result = a.Int ^ b.Int
+	case runtime.OP_SHIFT_LEFT, runtime.OP_SHIFT_RIGHT:
+		if b.Int < 0 {
+			return a, runtimeError("Shift count for '%s' cannot be negative (got %d).", bitwiseOperators[op], b.Int)
+		}
+		if op == runtime.OP_SHIFT_LEFT {
+			result = a.Int << uint64(b.Int)
+			if a.Int != 0 && (b.Int >= 64 || result>>uint64(b.Int) != a.Int) {
+				return runtime.Value{Type: runtime.VAL_NUMBER, Number: math.Ldexp(float64(a.Int), int(min(b.Int, 2048)))}, INTERPRET_OK
+			}
+		} else {
+			result = a.Int >> uint64(b.Int)
+		}
+	}
+	return runtime.Value{Type: runtime.VAL_INT, Int: result}, INTERPRET_OK
+}
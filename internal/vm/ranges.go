@@ -0,0 +1,159 @@
+package vm
+
+import (
+	"strings"
+
+	"github.com/cryptrunner49/zscript/internal/runtime"
+)
+
+// sliceValue slices an array, or a string by characters, for 'value[start:end:step]'. Null bounds
+// and step take their defaults and negative bounds count from the end. A step of 1 keeps the
+// original behaviour of swapping reversed bounds; other steps walk from start towards end, so a
+// negative step slices backwards.
+func sliceValue(value, startVal, endVal, stepVal runtime.Value) (runtime.Value, InterpretResult) {
+	switch obj := value.Obj.(type) {
+	case *runtime.ObjArray:
+		indices, result := sliceIndices(len(obj.Elements), startVal, endVal, stepVal)
+		if result != INTERPRET_OK {
+			return value, result
+		}
+		return selectElements(obj.Elements, indices), INTERPRET_OK
+	case *runtime.ObjString:
+		runes := []rune(obj.Chars)
+		indices, result := sliceIndices(len(runes), startVal, endVal, stepVal)
+		if result != INTERPRET_OK {
+			return value, result
+		}
+		return selectRunes(runes, indices), INTERPRET_OK
+	}
+	return value, runtimeError("Cannot slice %s; only arrays and strings can be sliced.", typeName(value))
+}
+
+// sliceIndices returns the positions selected by a slice of a sequence of the given length.
+func sliceIndices(length int, startVal, endVal, stepVal runtime.Value) ([]int, InterpretResult) {
+	step := 1
+	if stepVal.Type != runtime.VAL_NULL {
+		if !runtime.IsNumber(stepVal) {
+			return nil, runtimeError("Slice step must be a number.")
+		}
+		step = int(runtime.AsInt(stepVal))
+		if step == 0 {
+			return nil, runtimeError("Slice step cannot be zero.")
+		}
+	}
+
+	// A backwards slice starts at the last element and runs past the first one.
+	start, end, lowest := 0, length, 0
+	if step < 0 {
+		start, end, lowest = length-1, -1, -1
+	}
+	if startVal.Type != runtime.VAL_NULL {
+		if !runtime.IsNumber(startVal) {
+			return nil, runtimeError("Slice start must be a number.")
+		}
+		start = clampIndex(int(runtime.AsInt(startVal)), length, lowest)
+	}
+	if endVal.Type != runtime.VAL_NULL {
+		if !runtime.IsNumber(endVal) {
+			return nil, runtimeError("Slice end must be a number.")
+		}
+		end = clampIndex(int(runtime.AsInt(endVal)), length, lowest)
+	}
+	if step == 1 && start > end {
+		start, end = end, start
+	}
+
+	var indices []int
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		indices = append(indices, i)
+	}
+	return indices, INTERPRET_OK
+}
+
+// clampIndex resolves a negative slice bound from the end of the sequence and clamps the bound
+// between lowest and the length of the sequence, less one for backwards slices.
+func clampIndex(index int, length int, lowest int) int {
+	if index < 0 {
+		index += length
+	}
+	highest := length
+	if lowest < 0 {
+		highest = length - 1
+	}
+	return max(lowest, min(index, highest))
+}
+
+// newRange creates the range from start towards end counting by step, which must not be zero. It
+// is an integer range when all three are integers and a float range otherwise.
+func newRange(start, end, step runtime.Value, inclusive bool) *runtime.ObjRange {
+	if allInts([]runtime.Value{start, end, step}) {
+		return runtime.NewIntRange(start.Int, end.Int, step.Int, inclusive)
+	}
+	return runtime.NewRange(runtime.AsFloat(start), runtime.AsFloat(end), runtime.AsFloat(step), inclusive)
+}
+
+// rangeIndices returns the positions selected by indexing a sequence of the given length with a
+// range: the range's values, with negative ones counting from the end, skipping any outside it.
+// Only the part of the range within [-length, length) is walked, so a range far longer than the
+// sequence costs no more than the sequence itself.
+func rangeIndices(length int, rng *runtime.ObjRange) []int {
+	first, last := rng.Within(int64(-length), int64(length))
+	var indices []int
+	for i := first; i < last; i++ {
+		index := int(runtime.AsInt(rng.ValueAt(i)))
+		if index < 0 {
+			index += length
+		}
+		if index >= 0 && index < length {
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+// selectElements builds a new array from the elements at the given positions.
+func selectElements(elements []runtime.Value, indices []int) runtime.Value {
+	selected := make([]runtime.Value, len(indices))
+	for i, index := range indices {
+		selected[i] = elements[index]
+	}
+	return runtime.ObjVal(runtime.NewArray(selected))
+}
+
+// selectRunes builds a new string from the characters at the given positions.
+func selectRunes(runes []rune, indices []int) runtime.Value {
+	var sb strings.Builder
+	for _, index := range indices {
+		sb.WriteRune(runes[index])
+	}
+	return runtime.ObjVal(runtime.NewObjString(sb.String()))
+}
+
+// contains implements 'value in container' for ranges, arrays, strings and maps.
+func contains(value runtime.Value, container runtime.Value) (bool, InterpretResult) {
+	switch obj := container.Obj.(type) {
+	case *runtime.ObjRange:
+		return runtime.IsNumber(value) && obj.Contains(value), INTERPRET_OK
+	case *runtime.ObjArray:
+		for _, element := range obj.Elements {
+			if runtime.Equal(element, value) {
+				return true, INTERPRET_OK
+			}
+		}
+		return false, INTERPRET_OK
+	case *runtime.ObjString:
+		needle, ok := value.Obj.(*runtime.ObjString)
+		if !ok {
+			return false, runtimeError("Only a string can be searched for in a string (got %s).", typeName(value))
+		}
+		return strings.Contains(obj.Chars, needle.Chars), INTERPRET_OK
+	case *runtime.ObjMap:
+		key, ok := value.Obj.(*runtime.ObjString)
+		if !ok {
+			return false, INTERPRET_OK
+		}
+		_, found := obj.Entries[key]
+		return found, INTERPRET_OK
+	}
+	return false, runtimeError("Cannot test membership in %s; 'in' works on ranges, arrays, strings and maps.", typeName(container))
+}
@@ -2,7 +2,7 @@ package vm
 
 import (
 	"fmt"
-	"os"
+	"slices"
 
 	"github.com/cryptrunner49/zscript/internal/runtime"
 )
@@ -25,7 +25,8 @@ func typeName(val runtime.Value) string {
 		return "boolean"
 	case runtime.VAL_NULL:
 		return "null"
-	case runtime.VAL_NUMBER:
+	case runtime.VAL_NUMBER, runtime.VAL_INT:
+		// Ints and floats are both numbers, as get_runtype reports them; is_int tells them apart.
 		return "number"
 	case runtime.VAL_OBJ:
 		switch val.Obj.(type) {
@@ -35,12 +36,34 @@ func typeName(val runtime.Value) string {
 			return "function"
 		case *runtime.ObjClosure:
 			return "closure"
+		case *runtime.ObjBoundMethod:
+			return "method"
 		case *runtime.ObjNative:
 			return "native function"
 		case *runtime.ObjStruct:
 			return "struct"
 		case *runtime.ObjInstance:
 			return "instance"
+		case *runtime.ObjEnum:
+			return "enum"
+		case *runtime.ObjEnumValue:
+			return "enum value"
+		case *runtime.ObjArray:
+			return "array"
+		case *runtime.ObjMap:
+			return "map"
+		case *runtime.ObjModule:
+			return "module"
+		case *runtime.ObjIterator:
+			return "iterator"
+		case *runtime.ObjRange:
+			return "range"
+		case *runtime.ObjGenerator:
+			return "generator"
+		case *runtime.ObjBigInt:
+			return "bigint"
+		case *runtime.ObjDecimal:
+			return "decimal"
 		default:
 			return "object"
 		}
@@ -49,122 +72,393 @@ func typeName(val runtime.Value) string {
 	}
 }
 
-// runtimeError prints a formatted runtime error message along with a backtrace of call frames.
-// It then resets the VM's stack and returns an INTERPRET_RUNTIME_ERROR result.
+// runtimeError raises an Error with the formatted message at the current instruction and returns
+// an INTERPRET_RUNTIME_ERROR result. The error unwinds to the innermost 'try' block when the
+// dispatch loop next runs, or is reported with a backtrace when nothing catches it. Only the
+// first error raised before that happens is kept.
 func runtimeError(format string, args ...interface{}) InterpretResult {
-	fmt.Fprintf(os.Stderr, "Runtime Error: ")
-	fmt.Fprintf(os.Stderr, format, args...)
-	fmt.Fprintln(os.Stderr)
-	// Print a backtrace of the call stack, showing the line number and function name (or
-	// "top-level script") for each frame.
-	for i := vm.frameCount - 1; i >= 0; i-- {
-		frame := &vm.frames[i]
-		function := frame.closure.Function
-		instruction := frame.ip - 1
-		line := function.Chunk.Lines()[instruction]
-		fmt.Fprintf(os.Stderr, "  at [line %d] in ", line)
-		if function.Name == nil {
-			fmt.Fprintln(os.Stderr, "top-level script")
-		} else {
-			fmt.Fprintf(os.Stderr, "function '%s()'\n", function.Name.Chars)
-		}
+	if vm.pendingError == nil {
+		vm.pendingError = newError(fmt.Sprintf(format, args...))
 	}
-	resetStack()
 	return INTERPRET_RUNTIME_ERROR
 }
 
 // callValue attempts to call a value, which can be a function, native function, or struct constructor.
 func callValue(callee runtime.Value, argCount int) bool {
+	if vm.argNames != nil {
+		switch callee.Obj.(type) {
+		case *runtime.ObjClosure, *runtime.ObjBoundMethod:
+		default:
+			vm.argNames = nil
+			runtimeError("Cannot pass keyword arguments to %s; only script functions accept them.", typeName(callee))
+			return false
+		}
+	}
 	if callee.Type == runtime.VAL_OBJ {
 		switch obj := callee.Obj.(type) {
 		case *runtime.ObjClosure:
 			return call(obj, argCount)
+		case *runtime.ObjBoundMethod:
+			// The instance takes the place of the callee, becoming 'this' in slot 0.
+			vm.stack[vm.stackTop-argCount-1] = obj.Receiver
+			return call(obj.Method, argCount)
 		case *runtime.ObjNative:
 			native := obj.Function
-			result := native(argCount, vm.stack[vm.stackTop-argCount:vm.stackTop])
+			result, err := native(argCount, vm.stack[vm.stackTop-argCount:vm.stackTop])
+			if err != nil {
+				runtimeError("%s", err.Error())
+				return false
+			}
 			vm.stackTop -= argCount + 1
 			Push(result)
 			return true
 		case *runtime.ObjStruct:
-			// For struct constructors, create a new instance.
-			vm.stack[vm.stackTop-argCount-1] = runtime.ObjVal(runtime.NewInstance(obj))
+			// For struct constructors, create a new instance with its field defaults.
+			return newInstance(obj, vm.stackTop-argCount-1)
+		case *runtime.ObjEnumValue:
+			// Calling a variant that declares payload fields creates a value carrying the arguments.
+			if len(obj.Fields) == 0 || obj.Payload != nil {
+				runtimeError("Cannot call '%s::%s'; only variants declaring payload fields are callable.", obj.Enum.Name.Chars, obj.Name.Chars)
+				return false
+			}
+			if argCount != len(obj.Fields) {
+				runtimeError("Variant '%s::%s' expects %d payload values but got %d.", obj.Enum.Name.Chars, obj.Name.Chars, len(obj.Fields), argCount)
+				return false
+			}
+			payload := make([]runtime.Value, argCount)
+			copy(payload, vm.stack[vm.stackTop-argCount:vm.stackTop])
+			vm.stackTop -= argCount
+			vm.stack[vm.stackTop-1] = runtime.ObjVal(obj.WithPayload(payload))
 			return true
 		default:
 			// Non-callable object type.
 		}
 	}
-	runtimeError("Cannot call %s; only functions and structs are callable.", typeName(callee))
+	runtimeError("Cannot call %s; only functions, structs and enum variants are callable.", typeName(callee))
+	return false
+}
+
+// invoke calls the named property of the receiver below the arguments. A struct method is called
+// directly with the instance as 'this'; a field of an instance or module holding a callable value
+// is called like any other value.
+func invoke(name *runtime.ObjString, argCount int) bool {
+	receiver := peek(argCount)
+	switch obj := receiver.Obj.(type) {
+	case *runtime.ObjInstance:
+		if value, found := obj.Fields[name]; found {
+			vm.stack[vm.stackTop-argCount-1] = value
+			return callValue(value, argCount)
+		}
+		if method, found := obj.Structure.Methods[name]; found {
+			return call(method, argCount)
+		}
+		runtimeError("Struct '%s' has no field or method '%s'.", obj.Structure.Name.Chars, name.Chars)
+		return false
+	case *runtime.ObjModule:
+		if value, found := obj.Lookup(name); found {
+			vm.stack[vm.stackTop-argCount-1] = value
+			return callValue(value, argCount)
+		}
+		if obj.Exports != nil {
+			runtimeError("Module '%s' does not export '%s'.", obj.Name.Chars, name.Chars)
+		} else {
+			runtimeError("Property '%s' does not exist on this instance.", name.Chars)
+		}
+		return false
+	case *runtime.ObjMap:
+		// 'map.name(args)' calls the entry with the property's name, like '(map.name)(args)'.
+		if value, found := obj.Entries[name]; found {
+			vm.stack[vm.stackTop-argCount-1] = value
+			return callValue(value, argCount)
+		}
+		runtimeError("Cannot call '%s'; the map has no entry with that name.", name.Chars)
+		return false
+	case *runtime.ObjIterator:
+		return invokeIterator(obj, name, argCount)
+	case *runtime.ObjGenerator:
+		return invokeGenerator(obj, name, argCount)
+	}
+	runtimeError("Cannot call method '%s' on %s; only struct instances, modules, maps, iterators and generators have methods.", name.Chars, typeName(receiver))
 	return false
 }
 
-// call sets up a new call frame for a closure, verifying the argument count.
+// superMethod looks a method up in the parent of the struct declaring the running closure, which
+// is the struct 'super' refers to in it.
+func superMethod(closure *runtime.ObjClosure, name *runtime.ObjString) (*runtime.ObjClosure, bool) {
+	owner := closure.Owner
+	if owner == nil || owner.Parent == nil {
+		runtimeError("Cannot use 'super' outside of a method of a struct with a parent.")
+		return nil, false
+	}
+	method, found := owner.Parent.Methods[name]
+	if !found {
+		runtimeError("Parent struct '%s' has no method '%s'.", owner.Parent.Name.Chars, name.Chars)
+		return nil, false
+	}
+	return method, true
+}
+
+// argumentPlaceholder fills the slot of a parameter with a default value that a call left out,
+// until the function's code evaluates the default into it.
+type argumentPlaceholder struct{}
+
+// call sets up a new call frame for a closure, verifying the argument count. Parameters with a
+// default value that the arguments leave out hold a placeholder, which the function's own code
+// replaces with the default; surplus arguments are collected into an array for a rest parameter.
 func call(closure *runtime.ObjClosure, argCount int) bool {
-	if argCount != closure.Function.Arity {
-		runtimeError("Function '%s' expects %d arguments but got %d.", closure.Function.Name.Chars, closure.Function.Arity, argCount)
+	function := closure.Function
+	if vm.argNames != nil {
+		names := vm.argNames
+		vm.argNames = nil
+		var ok bool
+		if argCount, ok = bindKeywordArguments(function, argCount, names); !ok {
+			return false
+		}
+	}
+	required := function.Arity - function.Optional
+	if argCount < required || (argCount > function.Arity && !function.Variadic) {
+		runtimeError("Function '%s' expects %s but got %d.", function.Name.Chars, expectedArguments(function), argCount)
 		return false
 	}
 	if vm.frameCount >= FRAMES_MAX {
 		runtimeError("Stack overflow; too many nested function calls (max %d).", FRAMES_MAX)
 		return false
 	}
+	for i := argCount; i < function.Arity; i++ {
+		Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: &argumentPlaceholder{}})
+	}
+	slotCount := function.Arity
+	if function.Variadic {
+		surplus := max(argCount-function.Arity, 0)
+		rest := make([]runtime.Value, surplus)
+		copy(rest, vm.stack[vm.stackTop-surplus:vm.stackTop])
+		vm.stackTop -= surplus
+		Push(runtime.ObjVal(runtime.NewArray(rest)))
+		slotCount++
+	}
 	frame := &vm.frames[vm.frameCount]
 	vm.frameCount++
 	frame.closure = closure
 	frame.ip = 0
-	frame.slots = vm.stackTop - argCount - 1
+	frame.slots = vm.stackTop - slotCount - 1
+	frame.generator = nil
+	if function.IsGenerator {
+		// The body only runs once the generator is resumed; the call returns the generator.
+		generator := runtime.NewGenerator(closure, vm.stack[frame.slots:vm.stackTop])
+		vm.frameCount--
+		vm.stackTop = frame.slots
+		Push(runtime.ObjVal(generator))
+	}
 	return true
 }
 
-// createInstance creates a new struct instance from a struct value, applying key-value pairs
-// from the stack as field initializers, and returns false if validation fails or the callee
-// is not a struct.
-func createInstance(callee runtime.Value, argCount int) bool {
-	if callee.Type == runtime.VAL_OBJ {
-		if structObj, ok := callee.Obj.(*runtime.ObjStruct); ok {
-			// Create new instance with default values
-			instance := runtime.NewInstance(structObj)
-
-			// Pop the force flag
-			forceVal := Pop()
-			if forceVal.Type != runtime.VAL_BOOL {
-				runtimeError("Internal error: Expected boolean force flag for instance creation.")
+// bindKeywordArguments moves the arguments of a call whose last arguments were passed by name
+// into parameter order, with placeholders for the parameters with defaults that were not given,
+// and returns the resulting argument count.
+func bindKeywordArguments(function *runtime.ObjFunction, argCount int, names []*runtime.ObjString) (int, bool) {
+	base := vm.stackTop - argCount
+	positional := argCount - len(names)
+	args := make([]runtime.Value, argCount)
+	copy(args, vm.stack[base:vm.stackTop])
+
+	params := make([]runtime.Value, function.Arity)
+	given := make([]bool, function.Arity)
+	var rest []runtime.Value
+	for i := 0; i < positional; i++ {
+		if i < function.Arity {
+			params[i] = args[i]
+			given[i] = true
+		} else {
+			rest = append(rest, args[i])
+		}
+	}
+	if len(rest) > 0 && !function.Variadic {
+		runtimeError("Function '%s' expects %s but got %d.", function.Name.Chars, expectedArguments(function), argCount)
+		return 0, false
+	}
+	for i, name := range names {
+		index := -1
+		for j, param := range function.Params {
+			if param == name {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			runtimeError("Function '%s' has no parameter named '%s'.", function.Name.Chars, name.Chars)
+			return 0, false
+		}
+		if given[index] {
+			runtimeError("Function '%s' got more than one value for parameter '%s'.", function.Name.Chars, name.Chars)
+			return 0, false
+		}
+		params[index] = args[positional+i]
+		given[index] = true
+	}
+	for i := range params {
+		if given[i] {
+			continue
+		}
+		if i < function.Arity-function.Optional {
+			runtimeError("Function '%s' is missing a value for parameter '%s'.", function.Name.Chars, function.Params[i].Chars)
+			return 0, false
+		}
+		params[i] = runtime.Value{Type: runtime.VAL_OBJ, Obj: &argumentPlaceholder{}}
+	}
+
+	vm.stackTop = base
+	for _, value := range append(params, rest...) {
+		Push(value)
+	}
+	return function.Arity + len(rest), true
+}
+
+// expectedArguments describes how many arguments a function accepts, for arity errors.
+func expectedArguments(function *runtime.ObjFunction) string {
+	required := function.Arity - function.Optional
+	switch {
+	case function.Variadic:
+		return fmt.Sprintf("at least %d %s", required, pluralArguments(required))
+	case function.Optional > 0:
+		return fmt.Sprintf("%d to %d arguments", required, function.Arity)
+	default:
+		return fmt.Sprintf("%d %s", function.Arity, pluralArguments(function.Arity))
+	}
+}
+
+// pluralArguments returns "argument" or "arguments" to follow the given count.
+func pluralArguments(count int) string {
+	if count == 1 {
+		return "argument"
+	}
+	return "arguments"
+}
+
+// newInstance creates an instance of a struct in the given stack slot, discarding the stack above
+// it, and calls the initializers evaluating its field defaults. They run before the caller resumes,
+// from the root of the inheritance chain down, so a child's defaults replace its parent's; each
+// returns the instance into the same slot.
+func newInstance(structObj *runtime.ObjStruct, slot int) bool {
+	vm.stack[slot] = runtime.ObjVal(runtime.NewInstance(structObj))
+	vm.stackTop = slot + 1
+	for s := structObj; s != nil; s = s.Parent {
+		if s.Initializer != nil && !call(s.Initializer, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// initializeInstance applies the key-value pairs on the stack to the instance below them, which
+// OP_NEW created, and returns false if a field name is invalid. Unless the force flag is set, only
+// fields declared by the struct may be initialized.
+func initializeInstance(argCount int) bool {
+	// Pop the force flag
+	forceVal := Pop()
+	if forceVal.Type != runtime.VAL_BOOL {
+		runtimeError("Internal error: Expected boolean force flag for instance creation.")
+		return false
+	}
+	force := forceVal.Bool
+	instance := peek(argCount * 2).Obj.(*runtime.ObjInstance)
+
+	// Process key-value pairs from the stack, assigning values to the instance’s fields and
+	// validating field names and existence based on the force flag.
+	for i := 0; i < argCount; i++ {
+		value := Pop()  // Pop the value (e.g., 2, then 1)
+		keyVal := Pop() // Pop the key (e.g., "y", then "x")
+		key, ok := keyVal.Obj.(*runtime.ObjString)
+		if !ok {
+			runtimeError("Field name must be a string in instance initializer.")
+			return false
+		}
+		if !force {
+			// Check if the field exists in the struct
+			if _, exists := instance.Structure.Fields[key]; !exists {
+				runtimeError("Unknown field '%s' in struct '%s'.", key.Chars, instance.Structure.Name.Chars)
 				return false
 			}
-			force := forceVal.Bool
-
-			// Base position of the struct on the stack
-			base := vm.stackTop - (argCount * 2) - 1 // 2 slots per pair
-
-			// Process key-value pairs from the stack, assigning values to the instance’s fields and
-			// validating field names and existence based on the force flag.
-			for i := 0; i < argCount; i++ {
-				value := Pop()  // Pop the value (e.g., 2, then 1)
-				keyVal := Pop() // Pop the key (e.g., "y", then "x")
-				if keyVal.Type != runtime.VAL_OBJ {
-					runtimeError("Field name must be a string in instance initializer.")
-					return false
-				}
-				key, ok := keyVal.Obj.(*runtime.ObjString)
-				if !ok {
-					runtimeError("Field name must be a string in instance initializer.")
-					return false
-				}
-				if !force {
-					// Check if the field exists in the struct
-					if _, exists := structObj.Fields[key]; !exists {
-						runtimeError("Unknown field '%s' in struct '%s'.", key.Chars, structObj.Name.Chars)
-						return false
-					}
+		}
+		instance.Fields[key] = value // Assign value to the specified field
+	}
+	return true
+}
+
+// unpack replaces the destructured value on top of the stack with the parts named by an OP_UNPACK
+// pattern: count array elements, or the map values or instance fields with the given names. When
+// the pattern has a rest variable, the remaining elements or entries are pushed last.
+func unpack(kind runtime.UnpackKind, count int, hasRest bool, names []*runtime.ObjString) bool {
+	switch kind {
+	case runtime.UNPACK_ARRAY:
+		value := Pop()
+		array, ok := value.Obj.(*runtime.ObjArray)
+		if !ok {
+			runtimeError("Cannot destructure %s with an array pattern.", typeName(value))
+			return false
+		}
+		size := len(array.Elements)
+		if hasRest && size < count {
+			runtimeError("Array pattern expects at least %d elements but the array has %d.", count, size)
+			return false
+		}
+		if !hasRest && size != count {
+			runtimeError("Array pattern expects %d elements but the array has %d.", count, size)
+			return false
+		}
+		for _, element := range array.Elements[:count] {
+			Push(element)
+		}
+		if hasRest {
+			Push(runtime.ObjVal(runtime.NewArray(append([]runtime.Value(nil), array.Elements[count:]...))))
+		}
+	case runtime.UNPACK_MAP:
+		value := Pop()
+		mapObj, ok := value.Obj.(*runtime.ObjMap)
+		if !ok {
+			runtimeError("Cannot destructure %s with a map pattern.", typeName(value))
+			return false
+		}
+		// Missing keys bind null, as indexing the map would.
+		for _, name := range names {
+			if entry, found := mapObj.Entries[name]; found {
+				Push(entry)
+			} else {
+				Push(runtime.Value{Type: runtime.VAL_NULL})
+			}
+		}
+		if hasRest {
+			rest := runtime.NewMap()
+			for key, entry := range mapObj.Entries {
+				if !slices.Contains(names, key) {
+					rest.Entries[key] = entry
 				}
-				instance.Fields[key] = value // Assign value to the specified field
 			}
-
-			// Replace the struct with the new instance
-			vm.stack[base] = runtime.ObjVal(instance)
-			vm.stackTop = base + 1
-			return true
+			Push(runtime.ObjVal(rest))
+		}
+	case runtime.UNPACK_INSTANCE:
+		structVal := Pop()
+		value := Pop()
+		structObj, ok := structVal.Obj.(*runtime.ObjStruct)
+		if !ok {
+			runtimeError("Cannot destructure with %s; struct patterns require a struct.", typeName(structVal))
+			return false
 		}
+		instance, ok := value.Obj.(*runtime.ObjInstance)
+		if !ok || !instance.IsInstanceOf(structObj) {
+			runtimeError("Cannot destructure %s with a '%s' pattern.", typeName(value), structObj.Name.Chars)
+			return false
+		}
+		for _, name := range names {
+			field, found := instance.Fields[name]
+			if !found {
+				runtimeError("Struct '%s' has no field '%s'.", structObj.Name.Chars, name.Chars)
+				return false
+			}
+			Push(field)
+		}
+	default:
+		runtimeError("Unknown destructuring kind %d.", kind)
+		return false
 	}
-	runtimeError("Cannot instantiate %s with '{}'; only structs can be instantiated this way.", typeName(callee))
-	return false
+	return true
 }
@@ -53,8 +53,8 @@ import "C"
 
 import (
 	"fmt"
-	"math"
-	"os"
+	"math/big"
+	"strings"
 	"time"
 	"unsafe"
 
@@ -75,6 +75,9 @@ type CallFrame struct {
 	closure *runtime.ObjClosure // The closure (function with environment) being executed.
 	ip      int                 // Instruction pointer into the function's bytecode.
 	slots   int                 // Base index in the VM's stack where this call's local variables begin.
+
+	generator *runtime.ObjGenerator // The generator the frame runs, or nil for an ordinary call.
+	lookahead bool                  // Whether done() resumed the generator, to find out if it has ended.
 }
 
 // InterpretResult indicates the outcome of interpreting code.
@@ -88,16 +91,27 @@ const (
 
 // VM represents the virtual machine state.
 type VM struct {
-	frames       [FRAMES_MAX]CallFrame                // Call frame stack for function calls.
-	frameCount   int                                  // Number of active call frames.
-	stack        [STACK_MAX]runtime.Value             // Value stack used during execution.
-	stackTop     int                                  // Index of the next available slot on the stack.
-	objects      *runtime.Obj                         // Linked list of all allocated objects.
-	globals      map[*runtime.ObjString]runtime.Value // Global variables table.
-	strings      map[uint32]*runtime.ObjString        // Interned strings table.
-	openUpvalues *runtime.ObjUpvalue                  // Linked list of open upvalues for closures.
-	libHandles   []unsafe.Pointer                     // List of loaded library handles.
-	lastValue    runtime.Value                        // Store the last value from script execution
+	frames           [FRAMES_MAX]CallFrame                     // Call frame stack for function calls.
+	frameCount       int                                       // Number of active call frames.
+	stack            [STACK_MAX]runtime.Value                  // Value stack used during execution.
+	stackTop         int                                       // Index of the next available slot on the stack.
+	objects          *runtime.Obj                              // Linked list of all allocated objects.
+	globals          map[*runtime.ObjString]runtime.Value      // Global variables of the main script.
+	constGlobals     map[*runtime.ObjString]bool               // Globals of the main script declared with 'const', which cannot be assigned.
+	builtins         map[*runtime.ObjString]runtime.Value      // Native functions and other built-ins, read by every file.
+	modules          map[*runtime.ObjString]*runtime.ObjModule // Namespaces of the imported files, by absolute path.
+	libPaths         []string                                  // Directories added to the search path of imports.
+	strings          map[uint32]*runtime.ObjString             // Interned strings table.
+	openUpvalues     *runtime.ObjUpvalue                       // Linked list of open upvalues for closures.
+	libHandles       []unsafe.Pointer                          // List of loaded library handles.
+	libHandle        unsafe.Pointer                            // Library that 'use' loaded most recently.
+	handlers         []ExceptionHandler                        // Exception handlers registered by 'try' blocks.
+	pendingError     *runtime.ObjInstance                      // Error raised and not yet caught or reported.
+	errorStruct      *runtime.ObjStruct                        // The built-in Error struct.
+	argNames         []*runtime.ObjString                      // Names of the keyword arguments ending the next call's arguments.
+	decimalPrecision int                                       // Digits kept after the decimal point by Decimal division.
+	decimalRounding  runtime.RoundingMode                      // Rounding mode of Decimal division and decimal_round.
+	lastValue        runtime.Value                             // Store the last value from script execution
 }
 
 func GetLastValue() runtime.Value {
@@ -112,11 +126,18 @@ func InitVM(args []string) {
 	resetStack()
 	vm.objects = nil
 	vm.globals = make(map[*runtime.ObjString]runtime.Value)
+	vm.constGlobals = make(map[*runtime.ObjString]bool)
+	vm.builtins = make(map[*runtime.ObjString]runtime.Value)
+	vm.modules = make(map[*runtime.ObjString]*runtime.ObjModule)
+	vm.libPaths = nil
 	vm.strings = make(map[uint32]*runtime.ObjString)
 	vm.lastValue = runtime.Value{Type: runtime.VAL_NULL}
+	vm.decimalPrecision = DEFAULT_DECIMAL_PRECISION
+	vm.decimalRounding = DEFAULT_DECIMAL_ROUNDING
 
 	// Define built-in native functions and globals, including command-line arguments.
 	defineAllNatives()
+	defineErrorStruct()
 	defineArgs(args)
 }
 
@@ -127,15 +148,22 @@ func FreeVM() {
 	}
 
 	vm.globals = nil
+	vm.constGlobals = nil
+	vm.builtins = nil
+	vm.modules = nil
 	vm.strings = nil
 	vm.objects = nil
 }
 
 // resetStack resets the VM's stack, call frame count, and open upvalues.
 func resetStack() {
+	stopGenerators(0)
 	vm.stackTop = 0
 	vm.frameCount = 0
 	vm.openUpvalues = nil
+	vm.handlers = nil
+	vm.pendingError = nil
+	vm.argNames = nil
 }
 
 // Push pushes a value onto the VM's stack.
@@ -212,10 +240,24 @@ func closeUpvalues(last *runtime.Value) {
 	}
 }
 
-// run executes the bytecode instructions in a loop and returns an interpretation result.
+// run executes the bytecode and returns an interpretation result. A raised error unwinds to the
+// innermost exception handler and execution resumes there; an error no handler catches is
+// reported and ends execution.
 func run() InterpretResult {
-	var currentLibHandle unsafe.Pointer
+	for {
+		result := execute()
+		if vm.pendingError == nil {
+			return result
+		}
+		if !catchError() {
+			reportUncaughtError()
+			return INTERPRET_RUNTIME_ERROR
+		}
+	}
+}
 
+// execute runs bytecode instructions until execution completes or an error is raised.
+func execute() InterpretResult {
 	// Helper functions to read bytes and constants from the current call frame.
 	readByte := func(frame *CallFrame) uint8 {
 		b := frame.closure.Function.Chunk.Code()[frame.ip]
@@ -237,6 +279,10 @@ func run() InterpretResult {
 
 	// Main instruction dispatch loop.
 	for {
+		// Stop when the previous instruction raised an error without returning straight away.
+		if vm.pendingError != nil {
+			return INTERPRET_RUNTIME_ERROR
+		}
 		// When no more call frames remain, execution is complete.
 		if vm.frameCount == 0 {
 			return INTERPRET_OK
@@ -277,14 +323,34 @@ func run() InterpretResult {
 			Push(vm.stack[frame.slots+int(slot)])
 		case uint8(runtime.OP_DEFINE_GLOBAL):
 			name := readString(frame)
-			vm.globals[name] = peek(0)
+			globals, constants := globalScope(frame)
+			if constants[name] {
+				return runtimeError("Cannot redeclare constant '%s'.", name.Chars)
+			}
+			globals[name] = peek(0)
+			Pop()
+		case uint8(runtime.OP_DEFINE_CONST):
+			name := readString(frame)
+			globals, constants := globalScope(frame)
+			if constants[name] {
+				return runtimeError("Cannot redeclare constant '%s'.", name.Chars)
+			}
+			globals[name] = peek(0)
+			constants[name] = true
 			Pop()
 		case uint8(runtime.OP_SET_GLOBAL):
 			name := readString(frame)
-			vm.globals[name] = peek(0)
+			globals, constants := globalScope(frame)
+			if constants[name] {
+				return runtimeError("Cannot assign to constant '%s'.", name.Chars)
+			}
+			globals[name] = peek(0)
 		case uint8(runtime.OP_GET_GLOBAL):
 			name := readString(frame)
-			if val, exists := vm.globals[name]; exists {
+			globals, _ := globalScope(frame)
+			if val, exists := globals[name]; exists {
+				Push(val)
+			} else if val, exists := vm.builtins[name]; exists {
 				Push(val)
 			} else {
 				return runtimeError("Global variable '%s' is not defined.", name.Chars)
@@ -302,29 +368,59 @@ func run() InterpretResult {
 			instVal := peek(0)
 			switch obj := instVal.Obj.(type) {
 			case *runtime.ObjInstance:
-				// For struct instances, look up the property in the fields map.
+				// For struct instances, look up the property in the fields map, then among the
+				// struct's methods, which are bound to the instance.
 				name := readString(frame)
 				if value, found := obj.Fields[name]; found {
 					Pop() // Remove the array from the stack.
 					Push(value)
+				} else if method, found := obj.Structure.Methods[name]; found {
+					Pop() // Remove the instance from the stack.
+					Push(runtime.ObjVal(runtime.NewBoundMethod(instVal, method)))
 				} else {
 					return runtimeError("Property '%s' does not exist on this instance.", name.Chars)
 				}
 			case *runtime.ObjModule:
-				// For struct instances, look up the property in the fields map.
+				// For modules, look up the property among the fields visible from outside.
 				name := readString(frame)
-				if value, found := obj.Fields[name]; found {
-					Pop() // Remove the array from the stack.
+				if value, found := obj.Lookup(name); found {
+					Pop() // Remove the module from the stack.
 					Push(value)
+				} else if obj.Exports != nil {
+					return runtimeError("Module '%s' does not export '%s'.", obj.Name.Chars, name.Chars)
 				} else {
 					return runtimeError("Property '%s' does not exist on this instance.", name.Chars)
 				}
+			case *runtime.ObjEnumValue:
+				// For enum values, look up the payload field by name.
+				name := readString(frame)
+				index := -1
+				for i, field := range obj.Fields {
+					if field == name {
+						index = i
+						break
+					}
+				}
+				if index == -1 || obj.Payload == nil {
+					return runtimeError("Variant '%s::%s' has no payload field '%s'.", obj.Enum.Name.Chars, obj.Name.Chars, name.Chars)
+				}
+				Pop() // Remove the enum value from the stack.
+				Push(obj.Payload[index])
+			case *runtime.ObjMap:
+				// For maps, read the entry with the property's name, like 'map["name"]'.
+				name := readString(frame)
+				value, found := obj.Entries[name]
+				if !found {
+					value = runtime.Value{Type: runtime.VAL_NULL}
+				}
+				Pop() // Remove the map from the stack.
+				Push(value)
 			case *runtime.ObjArray:
 				// Allow arrays to expose a "length" property.
 				name := readString(frame)
 				if name.Chars == "length" {
 					Pop()
-					Push(runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(len(obj.Elements))})
+					Push(runtime.Value{Type: runtime.VAL_INT, Int: int64(len(obj.Elements))})
 				} else {
 					return runtimeError("Cannot access property '%s' on array; only 'length' is supported.", name.Chars)
 				}
@@ -333,11 +429,11 @@ func run() InterpretResult {
 				var value runtime.Value
 				switch name.Chars {
 				case "year":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Year())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Year())}
 				case "month":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Month())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Month())}
 				case "day":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Day())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Day())}
 				default:
 					return runtimeError("Property '%s' does not exist on Date.", name.Chars)
 				}
@@ -348,13 +444,13 @@ func run() InterpretResult {
 				var value runtime.Value
 				switch name.Chars {
 				case "hour":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Hour())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Hour())}
 				case "minute":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Minute())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Minute())}
 				case "second":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Second())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Second())}
 				case "nanosecond":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Nanosecond())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Nanosecond())}
 				default:
 					return runtimeError("Property '%s' does not exist on Time.", name.Chars)
 				}
@@ -365,26 +461,26 @@ func run() InterpretResult {
 				var value runtime.Value
 				switch name.Chars {
 				case "year":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Year())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Year())}
 				case "month":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Month())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Month())}
 				case "day":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Day())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Day())}
 				case "hour":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Hour())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Hour())}
 				case "minute":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Minute())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Minute())}
 				case "second":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Second())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Second())}
 				case "nanosecond":
-					value = runtime.Value{Type: runtime.VAL_NUMBER, Number: float64(obj.Time.Nanosecond())}
+					value = runtime.Value{Type: runtime.VAL_INT, Int: int64(obj.Time.Nanosecond())}
 				default:
 					return runtimeError("Property '%s' does not exist on DateTime.", name.Chars)
 				}
 				Pop() // Remove the DateTime object from the stack
 				Push(value)
 			default:
-				return runtimeError("Cannot access property on %s; only struct instances, arrays, and modules have properties.", typeName(instVal))
+				return runtimeError("Cannot access property on %s; only struct instances, maps, arrays, and modules have properties.", typeName(instVal))
 			}
 
 		case uint8(runtime.OP_SET_PROPERTY):
@@ -399,25 +495,37 @@ func run() InterpretResult {
 				Push(value)
 			case *runtime.ObjModule:
 				name := readString(frame)
+				if obj.Exports != nil {
+					return runtimeError("Cannot assign to '%s'; the namespace of imported module '%s' is read-only.", name.Chars, obj.Name.Chars)
+				}
+				if obj.Constants[name] {
+					return runtimeError("Cannot assign to constant '%s' of module '%s'.", name.Chars, obj.Name.Chars)
+				}
 				obj.Fields[name] = peek(0)
 				value := Pop()
 				Pop()
 				Push(value)
+			case *runtime.ObjMap:
+				name := readString(frame)
+				obj.Entries[name] = peek(0)
+				value := Pop()
+				Pop()
+				Push(value)
 			case *runtime.ObjDate:
 				name := readString(frame)
 				value := peek(0)
-				if value.Type != runtime.VAL_NUMBER {
+				if !runtime.IsNumber(value) {
 					return runtimeError("Property '%s' must be a number.", name.Chars)
 				}
 				switch name.Chars {
 				case "year":
-					newYear := int(value.Number)
+					newYear := int(runtime.AsInt(value))
 					obj.Time = time.Date(newYear, obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "month":
-					newMonth := time.Month(value.Number)
+					newMonth := time.Month(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), newMonth, obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "day":
-					newDay := int(value.Number)
+					newDay := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), newDay, obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				default:
 					return runtimeError("Property '%s' does not exist on Date.", name.Chars)
@@ -428,21 +536,21 @@ func run() InterpretResult {
 			case *runtime.ObjTime:
 				name := readString(frame)
 				value := peek(0)
-				if value.Type != runtime.VAL_NUMBER {
+				if !runtime.IsNumber(value) {
 					return runtimeError("Property '%s' must be a number.", name.Chars)
 				}
 				switch name.Chars {
 				case "hour":
-					newHour := int(value.Number)
+					newHour := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), newHour, obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "minute":
-					newMinute := int(value.Number)
+					newMinute := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), newMinute, obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "second":
-					newSecond := int(value.Number)
+					newSecond := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), newSecond, obj.Time.Nanosecond(), obj.Time.Location())
 				case "nanosecond":
-					newNano := int(value.Number)
+					newNano := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), newNano, obj.Time.Location())
 				default:
 					return runtimeError("Property '%s' does not exist on Time.", name.Chars)
@@ -453,30 +561,30 @@ func run() InterpretResult {
 			case *runtime.ObjDateTime:
 				name := readString(frame)
 				value := peek(0)
-				if value.Type != runtime.VAL_NUMBER {
+				if !runtime.IsNumber(value) {
 					return runtimeError("Property '%s' must be a number.", name.Chars)
 				}
 				switch name.Chars {
 				case "year":
-					newYear := int(value.Number)
+					newYear := int(runtime.AsInt(value))
 					obj.Time = time.Date(newYear, obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "month":
-					newMonth := time.Month(value.Number)
+					newMonth := time.Month(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), newMonth, obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "day":
-					newDay := int(value.Number)
+					newDay := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), newDay, obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "hour":
-					newHour := int(value.Number)
+					newHour := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), newHour, obj.Time.Minute(), obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "minute":
-					newMinute := int(value.Number)
+					newMinute := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), newMinute, obj.Time.Second(), obj.Time.Nanosecond(), obj.Time.Location())
 				case "second":
-					newSecond := int(value.Number)
+					newSecond := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), newSecond, obj.Time.Nanosecond(), obj.Time.Location())
 				case "nanosecond":
-					newNano := int(value.Number)
+					newNano := int(runtime.AsInt(value))
 					obj.Time = time.Date(obj.Time.Year(), obj.Time.Month(), obj.Time.Day(), obj.Time.Hour(), obj.Time.Minute(), obj.Time.Second(), newNano, obj.Time.Location())
 				default:
 					return runtimeError("Property '%s' does not exist on DateTime.", name.Chars)
@@ -485,29 +593,47 @@ func run() InterpretResult {
 				Pop()
 				Push(value)
 			default:
-				return runtimeError("Cannot set property on %s; only struct instances and modules have fields.", typeName(instVal))
+				return runtimeError("Cannot set property on %s; only struct instances, maps and modules have fields.", typeName(instVal))
 			}
 		case uint8(runtime.OP_EQUAL):
 			b := Pop()
 			a := Pop()
 			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: runtime.Equal(a, b)})
 		case uint8(runtime.OP_GREATER):
-			if peek(0).Type != runtime.VAL_NUMBER || peek(1).Type != runtime.VAL_NUMBER {
+			if bigOperands(peek(1), peek(0)) {
+				b := Pop()
+				a := Pop()
+				order, ok := compareNumbers(a, b)
+				Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && order > 0})
+				break
+			}
+			if !runtime.IsNumber(peek(0)) || !runtime.IsNumber(peek(1)) {
 				return runtimeError("Both operands for '>' must be numbers (got %s and %s).", typeName(peek(1)), typeName(peek(0)))
 			}
 			b := Pop()
 			a := Pop()
-			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: a.Number > b.Number})
+			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: lessNumbers(b, a)})
 		case uint8(runtime.OP_LESS):
-			if peek(0).Type != runtime.VAL_NUMBER || peek(1).Type != runtime.VAL_NUMBER {
+			if bigOperands(peek(1), peek(0)) {
+				b := Pop()
+				a := Pop()
+				order, ok := compareNumbers(a, b)
+				Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && order < 0})
+				break
+			}
+			if !runtime.IsNumber(peek(0)) || !runtime.IsNumber(peek(1)) {
 				return runtimeError("Both operands for '<' must be numbers (got %s and %s).", typeName(peek(1)), typeName(peek(0)))
 			}
 			b := Pop()
 			a := Pop()
-			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: a.Number < b.Number})
+			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: lessNumbers(a, b)})
 
 		case uint8(runtime.OP_ADD):
-			if peek(0).Type == runtime.VAL_NUMBER && peek(1).Type == runtime.VAL_NUMBER {
+			if bigOperands(peek(1), peek(0)) {
+				if err := bigBinary(runtime.OP_ADD); err != INTERPRET_OK {
+					return err
+				}
+			} else if runtime.IsNumber(peek(0)) && runtime.IsNumber(peek(1)) {
 				b := Pop()
 				a := Pop()
 				Push(addNumbers(a, b))
@@ -561,35 +687,35 @@ func run() InterpretResult {
 			} else {
 				b := Pop()
 				a := Pop()
-				if a.Type == runtime.VAL_OBJ && b.Type == runtime.VAL_NUMBER {
+				if a.Type == runtime.VAL_OBJ && runtime.IsNumber(b) {
 					switch obj := a.Obj.(type) {
 					case *runtime.ObjDate:
-						days := int(b.Number)
+						days := int(runtime.AsInt(b))
 						newTime := obj.Time.AddDate(0, 0, days)
 						Push(runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())))
 					case *runtime.ObjTime:
-						seconds := int64(b.Number)
+						seconds := runtime.AsInt(b)
 						newTime := obj.Time.Add(time.Duration(seconds) * time.Second)
 						Push(runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second())))
 					case *runtime.ObjDateTime:
-						seconds := int64(b.Number)
+						seconds := runtime.AsInt(b)
 						newTime := obj.Time.Add(time.Duration(seconds) * time.Second)
 						Push(runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())))
 					default:
 						Push(addStrings(a, b)) // Mixed types fallback to string concatenation
 					}
-				} else if a.Type == runtime.VAL_NUMBER && b.Type == runtime.VAL_OBJ {
+				} else if runtime.IsNumber(a) && b.Type == runtime.VAL_OBJ {
 					switch obj := b.Obj.(type) {
 					case *runtime.ObjDate:
-						days := int(a.Number)
+						days := int(runtime.AsInt(a))
 						newTime := obj.Time.AddDate(0, 0, days)
 						Push(runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())))
 					case *runtime.ObjTime:
-						seconds := int64(a.Number)
+						seconds := runtime.AsInt(a)
 						newTime := obj.Time.Add(time.Duration(seconds) * time.Second)
 						Push(runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second())))
 					case *runtime.ObjDateTime:
-						seconds := int64(a.Number)
+						seconds := runtime.AsInt(a)
 						newTime := obj.Time.Add(time.Duration(seconds) * time.Second)
 						Push(runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())))
 					default:
@@ -599,7 +725,11 @@ func run() InterpretResult {
 			}
 
 		case uint8(runtime.OP_SUBTRACT):
-			if peek(0).Type == runtime.VAL_NUMBER && peek(1).Type == runtime.VAL_NUMBER {
+			if bigOperands(peek(1), peek(0)) {
+				if err := bigBinary(runtime.OP_SUBTRACT); err != INTERPRET_OK {
+					return err
+				}
+			} else if runtime.IsNumber(peek(0)) && runtime.IsNumber(peek(1)) {
 				b := Pop()
 				a := Pop()
 				Push(subtractNumbers(a, b))
@@ -653,18 +783,18 @@ func run() InterpretResult {
 			} else {
 				b := Pop()
 				a := Pop()
-				if a.Type == runtime.VAL_OBJ && b.Type == runtime.VAL_NUMBER {
+				if a.Type == runtime.VAL_OBJ && runtime.IsNumber(b) {
 					switch obj := a.Obj.(type) {
 					case *runtime.ObjDate:
-						days := int(b.Number)
+						days := int(runtime.AsInt(b))
 						newTime := obj.Time.AddDate(0, 0, -days)
 						Push(runtime.ObjVal(runtime.NewDate(newTime.Year(), newTime.Month(), newTime.Day())))
 					case *runtime.ObjTime:
-						seconds := int64(b.Number)
+						seconds := runtime.AsInt(b)
 						newTime := obj.Time.Add(-time.Duration(seconds) * time.Second)
 						Push(runtime.ObjVal(runtime.NewTime(newTime.Hour(), newTime.Minute(), newTime.Second())))
 					case *runtime.ObjDateTime:
-						seconds := int64(b.Number)
+						seconds := runtime.AsInt(b)
 						newTime := obj.Time.Add(-time.Duration(seconds) * time.Second)
 						Push(runtime.ObjVal(runtime.NewDateTime(newTime.Year(), newTime.Month(), newTime.Day(), newTime.Hour(), newTime.Minute(), newTime.Second())))
 					default:
@@ -678,7 +808,11 @@ func run() InterpretResult {
 			b := peek(0)
 			a := peek(1)
 			switch {
-			case b.Type == runtime.VAL_NUMBER && a.Type == runtime.VAL_NUMBER:
+			case bigOperands(a, b):
+				if err := bigBinary(runtime.OP_MULTIPLY); err != INTERPRET_OK {
+					return err
+				}
+			case runtime.IsNumber(b) && runtime.IsNumber(a):
 				bVal := Pop()
 				aVal := Pop()
 				Push(multiplyNumbers(aVal, bVal))
@@ -731,7 +865,11 @@ func run() InterpretResult {
 			b := peek(0)
 			a := peek(1)
 			switch {
-			case b.Type == runtime.VAL_NUMBER && a.Type == runtime.VAL_NUMBER:
+			case bigOperands(a, b):
+				if err := bigBinary(runtime.OP_DIVIDE); err != INTERPRET_OK {
+					return err
+				}
+			case runtime.IsNumber(b) && runtime.IsNumber(a):
 				bVal := Pop()
 				aVal := Pop()
 				Push(divideNumbers(aVal, bVal))
@@ -784,7 +922,11 @@ func run() InterpretResult {
 			b := peek(0)
 			a := peek(1)
 			switch {
-			case b.Type == runtime.VAL_NUMBER && a.Type == runtime.VAL_NUMBER:
+			case bigOperands(a, b):
+				if err := bigBinary(runtime.OP_MOD); err != INTERPRET_OK {
+					return err
+				}
+			case runtime.IsNumber(b) && runtime.IsNumber(a):
 				bVal := Pop()
 				aVal := Pop()
 				Push(modNumbers(aVal, bVal))
@@ -839,22 +981,23 @@ func run() InterpretResult {
 			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: isFalsey(val)})
 		case uint8(runtime.OP_NEGATE):
 			// Negation: applies unary minus to a number or an array of numbers.
-			if peek(0).Type == runtime.VAL_NUMBER {
-				val := Pop()
-				Push(runtime.Value{Type: runtime.VAL_NUMBER, Number: -val.Number})
+			if runtime.IsNumber(peek(0)) {
+				Push(negateNumber(Pop()))
+			} else if runtime.IsBigNumber(peek(0)) {
+				Push(negateBig(Pop()))
 			} else if peek(0).Type == runtime.VAL_OBJ {
 				// Check if the object is an array.
 				if array, ok := peek(0).Obj.(*runtime.ObjArray); ok {
 					// Verify that every element is a number.
 					for _, elem := range array.Elements {
-						if elem.Type != runtime.VAL_NUMBER {
+						if !runtime.IsNumber(elem) {
 							return runtimeError("Unary '-' requires a number or an array of numbers (got non-number element).")
 						}
 					}
 					// Create a new array with negated numbers.
 					newElements := make([]runtime.Value, len(array.Elements))
 					for i, elem := range array.Elements {
-						newElements[i] = runtime.Value{Type: runtime.VAL_NUMBER, Number: -elem.Number}
+						newElements[i] = negateNumber(elem)
 					}
 					// Pop the original array and push the new negated array.
 					Pop()
@@ -881,6 +1024,12 @@ func run() InterpretResult {
 			if isTruth(peek(0)) {
 				frame.ip += offset
 			}
+		case uint8(runtime.OP_JUMP_IF_NULL):
+			// Conditional jump: jump if the top of the stack is null.
+			offset := int(readShort(frame))
+			if peek(0).Type == runtime.VAL_NULL {
+				frame.ip += offset
+			}
 		case uint8(runtime.OP_LOOP):
 			// Loop back: subtract offset from the instruction pointer.
 			offset := int(readShort(frame))
@@ -899,10 +1048,50 @@ func run() InterpretResult {
 			if !callValue(peek(argCount), argCount) {
 				return INTERPRET_RUNTIME_ERROR
 			}
+		case uint8(runtime.OP_ARGUMENT_NAMES):
+			// Record the names of the keyword arguments for the call that follows.
+			count := int(readByte(frame))
+			vm.argNames = make([]*runtime.ObjString, count)
+			for i := range vm.argNames {
+				vm.argNames[i] = readString(frame)
+			}
+		case uint8(runtime.OP_DEFAULT_ARG):
+			// Skip the default value of a parameter the call supplied an argument for.
+			slot := int(readByte(frame))
+			offset := readShort(frame)
+			if _, missing := vm.stack[frame.slots+slot].Obj.(*argumentPlaceholder); !missing {
+				frame.ip += offset
+			}
+		case uint8(runtime.OP_INVOKE):
+			// Call a property of the receiver directly, without creating a bound method.
+			name := readString(frame)
+			argCount := int(readByte(frame))
+			if !invoke(name, argCount) {
+				return INTERPRET_RUNTIME_ERROR
+			}
+		case uint8(runtime.OP_GET_SUPER):
+			// Bind a method of the parent struct to the instance on top of the stack.
+			name := readString(frame)
+			method, ok := superMethod(frame.closure, name)
+			if !ok {
+				return INTERPRET_RUNTIME_ERROR
+			}
+			receiver := Pop()
+			Push(runtime.ObjVal(runtime.NewBoundMethod(receiver, method)))
+		case uint8(runtime.OP_SUPER_INVOKE):
+			// Call a method of the parent struct with the receiver below the arguments as 'this'.
+			name := readString(frame)
+			argCount := int(readByte(frame))
+			method, ok := superMethod(frame.closure, name)
+			if !ok || !call(method, argCount) {
+				return INTERPRET_RUNTIME_ERROR
+			}
 		case uint8(runtime.OP_CLOSURE):
 			// Create a closure from a function constant and capture its upvalues.
 			function := readConstant(frame).Obj.(*runtime.ObjFunction)
 			closure := runtime.NewClosure(function)
+			// Functions nested in a method resolve 'super' through the method's struct.
+			closure.Owner = frame.closure.Owner
 			Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: closure})
 			// For each upvalue, determine if it is a local or an upvalue from the enclosing function.
 			for i := 0; i < closure.UpvalueCount; i++ {
@@ -923,7 +1112,16 @@ func run() InterpretResult {
 			result := Pop()
 			closeUpvalues(&vm.stack[frame.slots])
 			vm.frameCount--
-			if vm.frameCount == 0 {
+			// Drop handlers left registered by the returning frame.
+			for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frameCount > vm.frameCount {
+				vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			}
+			if frame.generator != nil {
+				// A generator that returns has ended; the caller's receiver slot gets the result.
+				frame.generator.State = runtime.GENERATOR_DONE
+				vm.stackTop = frame.slots - 1
+				Push(generatorResult(frame, result, true))
+			} else if vm.frameCount == 0 {
 				// End of the top-level script.
 				Pop()
 				return INTERPRET_OK
@@ -938,18 +1136,83 @@ func run() InterpretResult {
 			name := readString(frame)
 			objStruct := runtime.NewStruct(name)
 			fieldCount := int(readByte(frame))
-			// For each field, read its name and default value.
+			// Fields start out null; the initializer evaluates the declared defaults.
 			for i := 0; i < fieldCount; i++ {
-				fieldName := readConstant(frame).Obj.(*runtime.ObjString)
-				defaultValue := readConstant(frame)
-				objStruct.Fields[fieldName] = defaultValue
+				objStruct.Fields[readString(frame)] = runtime.Value{Type: runtime.VAL_NULL}
+			}
+			// The method closures were pushed in declaration order before this instruction,
+			// followed by the initializer when the struct has one.
+			closureCount := 0
+			if readByte(frame) != 0 {
+				objStruct.Initializer = peek(0).Obj.(*runtime.ObjClosure)
+				objStruct.Initializer.Owner = objStruct
+				closureCount++
 			}
+			methodCount := int(readByte(frame))
+			closureCount += methodCount
+			for i := 0; i < methodCount; i++ {
+				methodName := readString(frame)
+				method := peek(closureCount - 1 - i).Obj.(*runtime.ObjClosure)
+				method.Owner = objStruct
+				objStruct.Methods[methodName] = method
+			}
+			vm.stackTop -= closureCount
 			Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: objStruct})
 
+		case uint8(runtime.OP_INHERIT):
+			// Make the struct below the parent on the stack inherit from it.
+			parent, ok := peek(0).Obj.(*runtime.ObjStruct)
+			child := peek(1).Obj.(*runtime.ObjStruct)
+			if !ok {
+				return runtimeError("Struct '%s' cannot inherit from %s; the parent must be a struct.", child.Name.Chars, typeName(peek(0)))
+			}
+			child.Inherit(parent)
+			Pop()
+
+		case uint8(runtime.OP_ENUM):
+			// Create a new enum type with its variants and their payload field names.
+			name := readString(frame)
+			objEnum := runtime.NewEnum(name)
+			variantCount := int(readByte(frame))
+			for i := 0; i < variantCount; i++ {
+				variantName := readString(frame)
+				fieldCount := int(readByte(frame))
+				fields := make([]*runtime.ObjString, fieldCount)
+				for j := 0; j < fieldCount; j++ {
+					fields[j] = readString(frame)
+				}
+				objEnum.AddVariant(variantName, fields)
+			}
+			Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: objEnum})
+
+		case uint8(runtime.OP_GET_VARIANT):
+			// Access a variant of an enum (Enum::Variant).
+			name := readString(frame)
+			enumObj, ok := peek(0).Obj.(*runtime.ObjEnum)
+			if !ok {
+				return runtimeError("Cannot use '::' on %s; only enums have variants.", typeName(peek(0)))
+			}
+			variant, found := enumObj.Lookup[name]
+			if !found {
+				return runtimeError("Enum '%s' has no variant '%s'.", enumObj.Name.Chars, name.Chars)
+			}
+			Pop()
+			Push(runtime.ObjVal(variant))
+
+		case uint8(runtime.OP_NEW):
+			// Replace the struct on top of the stack with a new instance of it.
+			structObj, ok := peek(0).Obj.(*runtime.ObjStruct)
+			if !ok {
+				return runtimeError("Cannot instantiate %s with '{}'; only structs can be instantiated this way.", typeName(peek(0)))
+			}
+			if !newInstance(structObj, vm.stackTop-1) {
+				return INTERPRET_RUNTIME_ERROR
+			}
+
 		case uint8(runtime.OP_INSTANCE):
 			argCount := int(readByte(frame)) // Number of key-value pairs
-			// Peek past argCount*2 (pairs) + 1 (force bool) to get the struct
-			if !createInstance(peek(argCount*2+1), argCount) {
+			// Apply the pairs and the force flag above the instance created by OP_NEW.
+			if !initializeInstance(argCount) {
 				return INTERPRET_RUNTIME_ERROR
 			}
 
@@ -962,13 +1225,27 @@ func run() InterpretResult {
 				break
 			}
 
+			// Indexing an array or a string with a range selects the positions it contains.
+			if rng, ok := index.Obj.(*runtime.ObjRange); ok {
+				switch o := obj.Obj.(type) {
+				case *runtime.ObjArray:
+					Push(selectElements(o.Elements, rangeIndices(len(o.Elements), rng)))
+				case *runtime.ObjString:
+					runes := []rune(o.Chars)
+					Push(selectRunes(runes, rangeIndices(len(runes), rng)))
+				default:
+					return runtimeError("Cannot index %s with a range; only arrays and strings can be.", typeName(obj))
+				}
+				break
+			}
+
 			switch o := obj.Obj.(type) {
 			case *runtime.ObjArray:
-				if index.Type != runtime.VAL_NUMBER {
+				if !runtime.IsNumber(index) {
 					runtimeError("Array index must be a number.")
 					break
 				}
-				idx := int(index.Number)
+				idx := int(runtime.AsInt(index))
 				if idx < 0 || idx >= len(o.Elements) {
 					runtimeError("Array index out of bounds.")
 					break
@@ -990,6 +1267,16 @@ func run() InterpretResult {
 				} else {
 					Push(runtime.Value{Type: runtime.VAL_NULL})
 				}
+			case *runtime.ObjEnumValue:
+				// Enum values are indexed by payload position.
+				if !runtime.IsNumber(index) {
+					return runtimeError("Enum payload index must be a number.")
+				}
+				idx := int(runtime.AsInt(index))
+				if idx < 0 || idx >= len(o.Payload) {
+					return runtimeError("Variant '%s::%s' has no payload value at index %d.", o.Enum.Name.Chars, o.Name.Chars, idx)
+				}
+				Push(o.Payload[idx])
 			default:
 				runtimeError("Object does not support indexing.")
 			}
@@ -1006,11 +1293,11 @@ func run() InterpretResult {
 
 			switch o := obj.Obj.(type) {
 			case *runtime.ObjArray:
-				if index.Type != runtime.VAL_NUMBER {
+				if !runtime.IsNumber(index) {
 					runtimeError("Array index must be a number.")
 					break
 				}
-				idx := int(index.Number)
+				idx := int(runtime.AsInt(index))
 				if idx < 0 || idx >= len(o.Elements) {
 					runtimeError("Array index out of bounds.")
 					break
@@ -1053,65 +1340,20 @@ func run() InterpretResult {
 				return runtimeError("Can only get length of arrays.")
 			}
 			Push(runtime.Value{
-				Type:   runtime.VAL_NUMBER,
-				Number: float64(len(array.Elements)),
+				Type: runtime.VAL_INT,
+				Int:  int64(len(array.Elements)),
 			})
 		case uint8(runtime.OP_ARRAY_SLICE):
-			// Create a slice of an array given start and end indices.
+			// Slice an array or a string given start, end and step, any of which may be null.
+			stepVal := Pop()
 			endVal := Pop()
 			startVal := Pop()
-			arrayVal := Pop()
-
-			if arrayVal.Type != runtime.VAL_OBJ {
-				return runtimeError("Expected array for slice operation.")
-			}
-			array, ok := arrayVal.Obj.(*runtime.ObjArray)
-			if !ok {
-				return runtimeError("Expected array for slice operation.")
-			}
-
-			start := 0
-			if startVal.Type != runtime.VAL_NULL {
-				if startVal.Type != runtime.VAL_NUMBER {
-					return runtimeError("Slice start must be a number.")
-				}
-				start = int(startVal.Number)
-				if start < 0 {
-					start += len(array.Elements)
-				}
-			}
-
-			end := len(array.Elements)
-			if endVal.Type != runtime.VAL_NULL {
-				if endVal.Type != runtime.VAL_NUMBER {
-					return runtimeError("Slice end must be a number.")
-				}
-				end = int(endVal.Number)
-				if end < 0 {
-					end += len(array.Elements)
-				}
-			}
-
-			// Clamp indices to valid range.
-			if start < 0 {
-				start = 0
-			} else if start > len(array.Elements) {
-				start = len(array.Elements)
-			}
-
-			if end < 0 {
-				end = 0
-			} else if end > len(array.Elements) {
-				end = len(array.Elements)
-			}
-
-			if start > end {
-				start, end = end, start
+			value := Pop()
+			slice, result := sliceValue(value, startVal, endVal, stepVal)
+			if result != INTERPRET_OK {
+				return result
 			}
-
-			elements := array.Elements[start:end]
-			newArray := runtime.NewArray(elements)
-			Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: newArray})
+			Push(slice)
 
 		case uint8(runtime.OP_MODULE):
 			// Create a new module type instance.
@@ -1119,45 +1361,45 @@ func run() InterpretResult {
 			objModule := runtime.NewModule(name)
 			fieldCount := int(readByte(frame))
 
-			// For each field, read its name and value from the stack in the correct order.
+			// For each field, read its name, its value and whether it is a constant.
 			for i := 0; i < fieldCount; i++ {
 				fieldName := readConstant(frame).Obj.(*runtime.ObjString)
 				defaultValue := readConstant(frame)
 				objModule.Fields[fieldName] = defaultValue
+				if readByte(frame) == 1 {
+					objModule.Constants[fieldName] = true
+				}
 			}
 
 			Push(runtime.Value{Type: runtime.VAL_OBJ, Obj: objModule})
 		case uint8(runtime.OP_IMPORT):
-			path := readString(frame).Chars
-			pathObj := runtime.NewObjString(path)
-			if cached, exists := vm.globals[pathObj]; exists {
-				Push(cached)
-				break
+			// Push the namespace of an imported file. On the first import the file runs first,
+			// and returns its namespace when it is done.
+			specifier := readString(frame)
+			if result := importModule(frame, specifier); result != INTERPRET_OK {
+				return result
 			}
-			content, err := os.ReadFile(path)
-			if err != nil {
-				return runtimeError("Failed to load module '%s': %v", path, err)
+		case uint8(runtime.OP_IMPORT_ALL):
+			module := Pop().Obj.(*runtime.ObjModule)
+			if result := importExports(frame, module); result != INTERPRET_OK {
+				return result
 			}
-			function := compiler.Compile(string(content), path)
-			if function == nil {
-				return INTERPRET_COMPILE_ERROR
+		case uint8(runtime.OP_NAMESPACE):
+			// An imported file has finished running: later imports of it use its namespace.
+			module := frame.closure.Function.Module
+			if result := checkExports(module); result != INTERPRET_OK {
+				return result
 			}
-			closure := runtime.NewClosure(function)
-			vm.globals[pathObj] = runtime.ObjVal(closure)
-			Push(runtime.ObjVal(closure))
-			if !callValue(runtime.ObjVal(closure), 0) {
-				return INTERPRET_RUNTIME_ERROR
-			}
-			Pop()                     // Pop the null return value
-			Push(vm.globals[pathObj]) // Push the closure back
+			vm.modules[frame.closure.Function.File] = module
+			Push(runtime.ObjVal(module))
 		case uint8(runtime.OP_USE):
 			libName := readString(frame).Chars
 			// Use the full library name as provided (e.g., "libmylib.so" or "mylib.dll")
-			currentLibHandle = C.load_library(C.CString(libName))
-			if currentLibHandle == nil {
+			vm.libHandle = C.load_library(C.CString(libName))
+			if vm.libHandle == nil {
 				return runtimeError("Failed to load library '%s'.", libName)
 			}
-			vm.libHandles = append(vm.libHandles, currentLibHandle)
+			vm.libHandles = append(vm.libHandles, vm.libHandle)
 
 		case uint8(runtime.OP_DEFINE_EXTERN):
 			returnTypeConstant := readConstant(frame)
@@ -1170,13 +1412,14 @@ func run() InterpretResult {
 			}
 			funcNameConstant := readConstant(frame)
 			funcName := funcNameConstant.Obj.(*runtime.ObjString).Chars
-			cFunc := C.get_function(currentLibHandle, C.CString(funcName))
+			cFunc := C.get_function(vm.libHandle, C.CString(funcName))
 			if cFunc == nil {
 				return runtimeError("Failed to load function '%s' from library.", funcName)
 			}
 			nativeFunc := createNativeFunc(funcName, cFunc, returnType, paramTypes)
 			nameObj := runtime.NewObjString(funcName)
-			vm.globals[nameObj] = runtime.Value{Type: runtime.VAL_OBJ, Obj: nativeFunc}
+			globals, _ := globalScope(frame)
+			globals[nameObj] = runtime.Value{Type: runtime.VAL_OBJ, Obj: nativeFunc}
 
 		case uint8(runtime.OP_MAP):
 			pairCount := int(readByte(frame))
@@ -1196,40 +1439,185 @@ func run() InterpretResult {
 				mapObj.Entries[key] = value
 			}
 			Push(runtime.ObjVal(mapObj))
+		case uint8(runtime.OP_SWITCH):
+			// Jump-table dispatch of 'match ... through': jump to the body of the case equal to the subject.
+			table := frame.closure.Function.Chunk.SwitchTables()[readByte(frame)]
+			frame.ip = table.Lookup(Pop())
 		case uint8(runtime.OP_MATCH):
-			// TODO
-			fmt.Print("TODO")
+			// Structural pattern test: replace the tested value with a boolean result.
+			kind := runtime.MatchKind(readByte(frame))
+			operand := int(readByte(frame))
+			switch kind {
+			case runtime.MATCH_ARRAY:
+				value := Pop()
+				array, ok := value.Obj.(*runtime.ObjArray)
+				Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && len(array.Elements) == operand})
+			case runtime.MATCH_INSTANCE:
+				structVal := Pop()
+				value := Pop()
+				structObj, ok := structVal.Obj.(*runtime.ObjStruct)
+				if !ok {
+					return runtimeError("Cannot match against %s; struct patterns require a struct.", typeName(structVal))
+				}
+				instance, ok := value.Obj.(*runtime.ObjInstance)
+				Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && instance.IsInstanceOf(structObj)})
+			case runtime.MATCH_VARIANT:
+				name := frame.closure.Function.Chunk.Constants().Values()[operand].Obj.(*runtime.ObjString)
+				enumVal := Pop()
+				value := Pop()
+				enumObj, ok := enumVal.Obj.(*runtime.ObjEnum)
+				if !ok {
+					return runtimeError("Cannot match against %s; variant patterns require an enum.", typeName(enumVal))
+				}
+				variant, found := enumObj.Lookup[name]
+				if !found {
+					return runtimeError("Enum '%s' has no variant '%s'.", enumObj.Name.Chars, name.Chars)
+				}
+				enumValue, ok := value.Obj.(*runtime.ObjEnumValue)
+				Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && enumValue.Enum == enumObj && enumValue.Index == variant.Index})
+			case runtime.MATCH_PAYLOAD:
+				enumValue, ok := Pop().Obj.(*runtime.ObjEnumValue)
+				Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: ok && len(enumValue.Payload) >= operand})
+			default:
+				return runtimeError("Unknown match pattern kind %d.", kind)
+			}
+		case uint8(runtime.OP_RANGE):
+			// Create the range 'start..end', or 'start..=end' when the operand is 1.
+			inclusive := readByte(frame) == 1
+			if !runtime.IsNumber(peek(0)) || !runtime.IsNumber(peek(1)) {
+				return runtimeError("Range bounds must be numbers (got %s and %s).", typeName(peek(1)), typeName(peek(0)))
+			}
+			end := Pop()
+			start := Pop()
+			Push(runtime.ObjVal(newRange(start, end, runtime.Value{Type: runtime.VAL_INT, Int: 1}, inclusive)))
+		case uint8(runtime.OP_IN):
+			// Membership test: 'value in container'.
+			container := Pop()
+			value := Pop()
+			found, result := contains(value, container)
+			if result != INTERPRET_OK {
+				return result
+			}
+			Push(runtime.Value{Type: runtime.VAL_BOOL, Bool: found})
+		case uint8(runtime.OP_ITERATOR):
+			// Replace the value an 'iter' loop walks with its iterator.
+			entries := readByte(frame) == 1
+			iterator, err := newIterator(peek(0), entries)
+			if err != nil {
+				return runtimeError("%s", err.Error())
+			}
+			vm.stack[vm.stackTop-1] = iterator
+		case uint8(runtime.OP_UNPACK):
+			// Destructuring: replace the value with the parts bound to the pattern's variables.
+			kind := runtime.UnpackKind(readByte(frame))
+			count := int(readByte(frame))
+			hasRest := readByte(frame) == 1
+			var names []*runtime.ObjString
+			if kind != runtime.UNPACK_ARRAY {
+				names = make([]*runtime.ObjString, count)
+				for i := range names {
+					names[i] = readString(frame)
+				}
+			}
+			if !unpack(kind, count, hasRest, names) {
+				return INTERPRET_RUNTIME_ERROR
+			}
 		case uint8(runtime.OP_DUP):
 			// Duplicate the top value on the stack
 			top := peek(0)
 			Push(top)
+		case uint8(runtime.OP_DUP_TWO):
+			// Duplicate the top two values on the stack, keeping their order.
+			below, top := peek(1), peek(0)
+			Push(below)
+			Push(top)
 		case uint8(runtime.OP_EXPONENTIAL):
+			if bigOperands(peek(1), peek(0)) {
+				if err := bigBinary(runtime.OP_EXPONENTIAL); err != INTERPRET_OK {
+					return err
+				}
+				break
+			}
 			b := Pop()
 			a := Pop()
-			if a.Type != runtime.VAL_NUMBER || b.Type != runtime.VAL_NUMBER {
+			if !runtime.IsNumber(a) || !runtime.IsNumber(b) {
 				return runtimeError("Operands for '**' must be numbers (got %s and %s).", typeName(a), typeName(b))
 			}
-			result := math.Pow(a.Number, b.Number)
-			Push(runtime.Value{Type: runtime.VAL_NUMBER, Number: result})
+			Push(powerNumbers(a, b))
 		case uint8(runtime.OP_FLOOR):
+			if bigOperands(peek(1), peek(0)) {
+				if err := bigBinary(runtime.OP_FLOOR); err != INTERPRET_OK {
+					return err
+				}
+				break
+			}
 			b := Pop()
 			a := Pop()
-			if a.Type != runtime.VAL_NUMBER || b.Type != runtime.VAL_NUMBER {
+			if !runtime.IsNumber(a) || !runtime.IsNumber(b) {
 				return runtimeError("Operands for '__' must be numbers (got %s and %s).", typeName(a), typeName(b))
 			}
-			if b.Number == 0 {
+			if runtime.AsFloat(b) == 0 {
 				return runtimeError("Division by zero in '__' operator.")
 			}
-			result := math.Floor(a.Number / b.Number)
-			Push(runtime.Value{Type: runtime.VAL_NUMBER, Number: result})
+			Push(floorDivideNumbers(a, b))
 		case uint8(runtime.OP_PERCENT):
+			if bigOperands(peek(1), peek(0)) {
+				if err := bigBinary(runtime.OP_PERCENT); err != INTERPRET_OK {
+					return err
+				}
+				break
+			}
 			b := Pop()
 			a := Pop()
-			if a.Type != runtime.VAL_NUMBER || b.Type != runtime.VAL_NUMBER {
+			if !runtime.IsNumber(a) || !runtime.IsNumber(b) {
 				return runtimeError("Operands for '%%' must be numbers (got %s and %s).", typeName(a), typeName(b))
 			}
-			result := (a.Number / 100.0) * b.Number
+			result := (runtime.AsFloat(a) / 100.0) * runtime.AsFloat(b)
 			Push(runtime.Value{Type: runtime.VAL_NUMBER, Number: result})
+		case uint8(runtime.OP_BIT_AND), uint8(runtime.OP_BIT_OR), uint8(runtime.OP_BIT_XOR),
+			uint8(runtime.OP_SHIFT_LEFT), uint8(runtime.OP_SHIFT_RIGHT):
+			b := Pop()
+			a := Pop()
+			result, err := bitwiseIntegers(runtime.OpCode(instruction), a, b)
+			if err != INTERPRET_OK {
+				return err
+			}
+			Push(result)
+		case uint8(runtime.OP_BIT_NOT):
+			if bigInt, ok := peek(0).Obj.(*runtime.ObjBigInt); ok {
+				Pop()
+				Push(runtime.ObjVal(runtime.NewBigInt(new(big.Int).Not(bigInt.Value))))
+				break
+			}
+			if peek(0).Type != runtime.VAL_INT {
+				return runtimeError("Operand for '~' must be an integer (got %s).", integerOperandName(peek(0)))
+			}
+			Push(runtime.Value{Type: runtime.VAL_INT, Int: ^Pop().Int})
+		case uint8(runtime.OP_TRY):
+			offset := readShort(frame)
+			vm.handlers = append(vm.handlers, ExceptionHandler{
+				frameCount: vm.frameCount,
+				stackTop:   vm.stackTop,
+				address:    frame.ip + offset,
+			})
+		case uint8(runtime.OP_END_TRY):
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case uint8(runtime.OP_THROW):
+			return throwValue(Pop())
+		case uint8(runtime.OP_INTERPOLATE):
+			// Join the parts of an interpolated string, converting the interpolated values.
+			count := int(readByte(frame))
+			var sb strings.Builder
+			for _, part := range vm.stack[vm.stackTop-count : vm.stackTop] {
+				sb.WriteString(toStr(1, []runtime.Value{part}).Obj.(*runtime.ObjString).Chars)
+			}
+			vm.stackTop -= count
+			Push(runtime.ObjVal(runtime.NewObjString(sb.String())))
+		case uint8(runtime.OP_YIELD):
+			// Pause the running generator and hand the value to the code that resumed it.
+			value := Pop()
+			suspendGenerator(frame)
+			Push(generatorResult(frame, value, false))
 		}
 	}
 }